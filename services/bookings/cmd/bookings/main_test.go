@@ -6,15 +6,19 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/spacexfake"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
 
 	bookingsv1 "github.com/zsoltggs/tabeo-interview/services/bookings/pkg/bookings/v1"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stretchr/testify/require"
@@ -24,7 +28,8 @@ import (
 
 const (
 	serviceBaseURL   = "http://localhost:9999"
-	spaceXBaseURL    = "https://api.spacexdata.com/v4"
+	spaceXFakeURL    = "http://localhost:9998"
+	fixturesPath     = "../../resources/spacexfake/fixtures.json"
 	validLaunchPadID = "5e9e4501f509094ba4566f84"
 )
 
@@ -41,12 +46,19 @@ func isE2ETestEnabled(t *testing.T) {
 	}
 }
 
-func Test_SpaceX_GetLaunchPadForID(t *testing.T) {
-	isE2ETestEnabled(t)
-
-	svc := spacex.New(spaceXBaseURL, &http.Client{
+// newSpaceXFake serves the fixtures in-process, so the SpaceX client tests do not need the internet
+func newSpaceXFake(t *testing.T) spacex.SpaceXService {
+	fixtures, err := spacexfake.LoadFixtures(fixturesPath)
+	require.NoError(t, err)
+	server := httptest.NewServer(spacexfake.New(fixtures).Handler("/v4"))
+	t.Cleanup(server.Close)
+	return spacex.New(server.URL+"/v4", &http.Client{
 		Timeout: 10 * time.Second,
-	})
+	}, clockwork.NewRealClock())
+}
+
+func Test_SpaceX_GetLaunchPadForID(t *testing.T) {
+	svc := newSpaceXFake(t)
 	ctx := context.Background()
 	res, err := svc.GetLaunchPadForID(ctx, validLaunchPadID)
 	require.NoError(t, err)
@@ -55,18 +67,15 @@ func Test_SpaceX_GetLaunchPadForID(t *testing.T) {
 }
 
 func Test_SpaceX_GetLaunchesForDate(t *testing.T) {
-	isE2ETestEnabled(t)
-
 	expected := smodels.Launch{
+		ID:        "62a9f89a20413d2695d8871a",
 		Name:      "Starlink 4-21 (v1.5)",
 		DateUTC:   launchExistsDate,
 		Launchpad: validLaunchPadID,
 		Success:   true,
 	}
 
-	svc := spacex.New(spaceXBaseURL, &http.Client{
-		Timeout: 10 * time.Second,
-	})
+	svc := newSpaceXFake(t)
 	ctx := context.Background()
 
 	date := launchExistsDate
@@ -112,14 +121,189 @@ func Test_Service_E2E(t *testing.T) {
 	assert.Equal(t, http.StatusOK, listResponse.StatusCode)
 	defer listResponse.Body.Close()
 
-	// Test delete booking
-	deleteURL := serviceBaseURL + "/bookings/" + createBookingResponse.Booking.ID.String()
-	req, err := http.NewRequest(http.MethodDelete, deleteURL, nil)
-	assert.NoError(t, err)
-
+	assert.Equal(t, `"1"`, resp.Header.Get("ETag"))
+	bookingURL := serviceBaseURL + "/bookings/" + createBookingResponse.Booking.ID.String()
 	client := &http.Client{}
+
+	// Test update booking
+	req, err := http.NewRequest(http.MethodPatch, bookingURL, bytes.NewBufferString(`{"last_name":"Smith"}`))
+	require.NoError(t, err)
+	req.Header.Set("If-Match", resp.Header.Get("ETag"))
+	updateResp, err := client.Do(req)
+	require.NoError(t, err)
+	defer updateResp.Body.Close()
+	assert.Equal(t, http.StatusOK, updateResp.StatusCode)
+	assert.Equal(t, `"2"`, updateResp.Header.Get("ETag"))
+
+	// Test delete booking with the ETag read before the update
+	req, err = http.NewRequest(http.MethodDelete, bookingURL, nil)
+	require.NoError(t, err)
+	req.Header.Set("If-Match", resp.Header.Get("ETag"))
 	deleteResp, err := client.Do(req)
 	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPreconditionFailed, deleteResp.StatusCode)
+
+	// Test delete booking
+	req, err = http.NewRequest(http.MethodDelete, bookingURL, nil)
+	require.NoError(t, err)
+	req.Header.Set("If-Match", updateResp.Header.Get("ETag"))
+	req.Header.Set("X-Actor", "alice")
+	deleteResp, err = client.Do(req)
+	assert.NoError(t, err)
 	assert.NotNil(t, deleteResp)
 	assert.Equal(t, http.StatusNoContent, deleteResp.StatusCode)
+
+	// Test the history of the deleted booking
+	historyResp, err := http.Get(bookingURL + "/history")
+	require.NoError(t, err)
+	defer historyResp.Body.Close()
+	assert.Equal(t, http.StatusOK, historyResp.StatusCode)
+	history := bookingsv1.BookingHistoryResponse{}
+	require.NoError(t, json.NewDecoder(historyResp.Body).Decode(&history))
+	require.Len(t, history.Entries, 3)
+	assert.Equal(t, "created", history.Entries[0].Action)
+	assert.Equal(t, "updated", history.Entries[1].Action)
+	assert.Equal(t, 2, history.Entries[1].After.Version)
+	assert.Empty(t, history.Entries[1].After.LastName, "the history is stored without the personal data")
+	assert.Equal(t, "deleted", history.Entries[2].Action)
+	assert.Equal(t, "alice", history.Entries[2].Actor)
+	assert.Nil(t, history.Entries[2].After)
+}
+
+func createBooking(t *testing.T, launchDate time.Time) *http.Response {
+	booking := map[string]interface{}{
+		"first_name":     "Jane",
+		"last_name":      "Doe",
+		"gender":         "female",
+		"birthday":       "1990-01-01",
+		"launch_pad_id":  validLaunchPadID,
+		"destination_id": "some-destination-id",
+		"launch_date":    launchDate.Format("2006-01-02"),
+	}
+	body, err := json.Marshal(booking)
+	require.NoError(t, err)
+	resp, err := http.Post(serviceBaseURL+"/bookings", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	// The body is buffered so callers can still read it
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp
+}
+
+func callSpaceXFake(t *testing.T, method string, path string, body string) {
+	req, err := http.NewRequest(method, spaceXFakeURL+path, bytes.NewBufferString(body))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Less(t, resp.StatusCode, 300)
+}
+
+func Test_Service_E2E_LaunchConflict(t *testing.T) {
+	isE2ETestEnabled(t)
+	t.Cleanup(func() { callSpaceXFake(t, http.MethodPost, "/admin/reset", "") })
+
+	// Far enough in the future to not be cached by the service
+	launchDate := time.Now().UTC().AddDate(5, 0, 0)
+	callSpaceXFake(t, http.MethodPost, "/admin/launches", `{
+		"name": "E2E conflicting launch",
+		"date_utc": "`+launchDate.Format(time.RFC3339)+`",
+		"launchpad": "`+validLaunchPadID+`"
+	}`)
+
+	resp := createBooking(t, launchDate)
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	conflict := bookingsv1.NotAvailableResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&conflict))
+	require.NotEmpty(t, conflict.Reasons)
+	assert.Equal(t, "launch", conflict.Reasons[0].Type)
+	require.NotNil(t, conflict.Reasons[0].Launch)
+	assert.Equal(t, "E2E conflicting launch", conflict.Reasons[0].Launch.Name)
+	require.NotNil(t, conflict.Suggestions)
+	assert.NotEmpty(t, conflict.Suggestions.Dates)
+	assert.NotContains(t, conflict.Suggestions.Dates, launchDate.Format("2006-01-02"))
+}
+
+func Test_Service_E2E_SpaceXUnavailable(t *testing.T) {
+	isE2ETestEnabled(t)
+	t.Cleanup(func() { callSpaceXFake(t, http.MethodPost, "/admin/reset", "") })
+
+	// Fails the first call and all of its retries
+	callSpaceXFake(t, http.MethodPut, "/admin/faults", `{"status_code": 500, "count": 4}`)
+
+	// A date that is not cached yet by the service
+	resp := createBooking(t, time.Now().UTC().AddDate(6, 0, 0))
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func Test_Service_E2E_CacheAdmin(t *testing.T) {
+	isE2ETestEnabled(t)
+
+	resp := createBooking(t, launchAvailableDate)
+	require.Less(t, resp.StatusCode, http.StatusInternalServerError)
+
+	unauthorizedResp, err := http.Get(serviceBaseURL + "/admin/cache")
+	require.NoError(t, err)
+	defer unauthorizedResp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, unauthorizedResp.StatusCode)
+
+	req, err := newAdminRequest(http.MethodGet, serviceBaseURL+"/admin/cache")
+	require.NoError(t, err)
+	statsResp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer statsResp.Body.Close()
+	assert.Equal(t, http.StatusOK, statsResp.StatusCode)
+	stats := bookingsv1.CacheStatsResponse{}
+	require.NoError(t, json.NewDecoder(statsResp.Body).Decode(&stats))
+	require.Len(t, stats.Providers, 1)
+	assert.Equal(t, "spacex", stats.Providers[0].Provider)
+	assert.Positive(t, stats.Providers[0].Launchpads)
+
+	req, err = newAdminRequest(http.MethodDelete, serviceBaseURL+"/admin/cache?launch_pad_id="+validLaunchPadID)
+	require.NoError(t, err)
+	invalidateResp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer invalidateResp.Body.Close()
+	assert.Equal(t, http.StatusOK, invalidateResp.StatusCode)
+	invalidated := bookingsv1.InvalidateCacheResponse{}
+	require.NoError(t, json.NewDecoder(invalidateResp.Body).Decode(&invalidated))
+	assert.Positive(t, invalidated.Invalidated)
+}
+
+// newAdminRequest returns a request carrying the ADMIN_TOKEN the service was started with, see make dev-env
+func newAdminRequest(method, url string) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+os.Getenv("ADMIN_TOKEN"))
+	return req, nil
+}
+
+func Test_Service_E2E_LaunchPadAvailability(t *testing.T) {
+	isE2ETestEnabled(t)
+
+	resp, err := http.Get(serviceBaseURL + "/launchpads/" + validLaunchPadID + "/availability?from=2022-07-06&to=2022-07-08")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	availability := bookingsv1.LaunchPadAvailabilityResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&availability))
+	require.Len(t, availability.Days, 3)
+	assert.Equal(t, "2022-07-07", availability.Days[1].Date)
+	assert.False(t, availability.Days[1].Available)
+	require.NotEmpty(t, availability.Days[1].Reasons)
+	assert.Equal(t, bookingsv1.UnavailabilityReason{Type: "launch", Description: "Starlink 4-21 (v1.5)"}, availability.Days[1].Reasons[0])
+}
+
+func Test_LoadKeyfile(t *testing.T) {
+	_, err := loadKeyfile("")
+	assert.ErrorContains(t, err, "--pii-keyfile")
+
+	_, err = loadKeyfile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "unable to read keyfile")
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/cachestore"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/schedule"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex"
+)
+
+const spaceXProviderName = "spacex"
+
+// providerConfig is shared by every launch schedule provider, all of them expose a SpaceX compatible API
+type providerConfig struct {
+	resilience spacex.ResilienceConfig
+	limiter    spacex.LimiterConfig
+	cache      spacex.CacheConfig
+	// spaceXTransport is only used for spacex, so recorded cassettes do not mix providers
+	spaceXTransport http.RoundTripper
+}
+
+// newLaunchScheduleProviders builds the enabled providers, each with its own client, limiter, breaker and cache.
+// The caches are returned by provider name.
+func newLaunchScheduleProviders(names []string, urls map[string]string, config providerConfig) ([]schedule.LaunchScheduleProvider, map[string]spacex.Cache, error) {
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("at least one launch schedule provider has to be enabled")
+	}
+	caches := make(map[string]spacex.Cache)
+	var providers []schedule.LaunchScheduleProvider
+	for _, name := range names {
+		if _, ok := caches[name]; ok {
+			return nil, nil, fmt.Errorf("launch schedule provider %s is enabled twice", name)
+		}
+		baseURL, ok := urls[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("missing url for launch schedule provider %s", name)
+		}
+
+		client := &http.Client{
+			Timeout: 10 * time.Second,
+		}
+		if name == spaceXProviderName {
+			client.Transport = config.spaceXTransport
+		}
+		cacheConfig := config.cache
+		if cacheConfig.Backend != nil {
+			// Providers share the backend, their entries must not collide
+			cacheConfig.Backend = cachestore.WithPrefix(cacheConfig.Backend, name+":")
+		}
+		limiterConfig := config.limiter
+		limiterConfig.Metrics = expvar.NewMap(fmt.Sprintf("%s_limiter", name))
+		cache := spacex.NewCache(
+			spacex.NewResilient(
+				spacex.NewLimiter(
+					spacex.New(baseURL, client, clockwork.NewRealClock()),
+					clockwork.NewRealClock(),
+					limiterConfig,
+				),
+				clockwork.NewRealClock(),
+				config.resilience,
+			),
+			clockwork.NewRealClock(),
+			cacheConfig,
+		)
+		caches[name] = cache
+		providers = append(providers, spacex.NewProvider(name, cache))
+	}
+	return providers, caches, nil
+}
+
+// parseProviderURLs parses name=url pairs
+func parseProviderURLs(values []string) (map[string]string, error) {
+	urls := make(map[string]string, len(values))
+	for _, value := range values {
+		name, url, ok := strings.Cut(value, "=")
+		if !ok || name == "" || url == "" {
+			return nil, fmt.Errorf("invalid launch schedule provider url %q, expected name=url", value)
+		}
+		urls[name] = url
+	}
+	return urls, nil
+}
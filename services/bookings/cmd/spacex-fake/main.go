@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	cli "github.com/jawher/mow.cli"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/spacexfake"
+)
+
+func main() {
+	app := cli.App("spacex-fake", "stand-in for the SpaceX API serving launches from a fixture file")
+	restPort := app.Int(cli.IntOpt{
+		Name:   "rest-port",
+		Desc:   "rest api port",
+		Value:  8080,
+		EnvVar: "REST_PORT",
+	})
+	fixturesPath := app.String(cli.StringOpt{
+		Name:   "fixtures",
+		Desc:   "path of the JSON fixture file with launchpads and launches",
+		Value:  "./services/bookings/resources/spacexfake/fixtures.json",
+		EnvVar: "FIXTURES_PATH",
+	})
+	prefix := app.String(cli.StringOpt{
+		Name:   "prefix",
+		Desc:   "path prefix of the SpaceX API",
+		Value:  "/v4",
+		EnvVar: "API_PREFIX",
+	})
+
+	app.Action = func() {
+		fixtures, err := spacexfake.LoadFixtures(*fixturesPath)
+		if err != nil {
+			log.WithError(err).Panic("unable to load fixtures")
+		}
+		log.WithFields(log.Fields{
+			"launchpads": len(fixtures.Launchpads),
+			"launches":   len(fixtures.Launches),
+		}).Info("fixtures loaded")
+
+		httpServer := &http.Server{
+			Addr:    fmt.Sprintf(":%d", *restPort),
+			Handler: spacexfake.New(fixtures).Handler(*prefix),
+		}
+		go func() {
+			log.Infof("about to start server on port %s", httpServer.Addr)
+			if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("listen: %s\n", err)
+			}
+		}()
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		<-sigChan
+		log.Warn("shutting down")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.WithError(err).Error("unable to shutdown http server")
+		}
+	}
+
+	err := app.Run(os.Args)
+	if err != nil {
+		log.WithError(err).Panic("service stopped")
+	}
+}
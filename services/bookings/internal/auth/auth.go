@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/httpresponse"
+)
+
+const (
+	// ActorHeader carries who is making the request, set by the gateway once it authenticated the caller
+	ActorHeader = "X-Actor"
+	// AnonymousActor is the actor of the requests without ActorHeader, and of any context without an actor
+	AnonymousActor = "anonymous"
+	// SystemActor is the actor of the work the service starts itself, e.g. verifying provisional bookings,
+	// the jobs set it explicitly
+	SystemActor = "system"
+
+	bearerPrefix = "Bearer "
+)
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor of ctx, AnonymousActor if none was set
+func ActorFromContext(ctx context.Context) string {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	if !ok || actor == "" {
+		return AnonymousActor
+	}
+	return actor
+}
+
+// Middleware sets the actor of the context of the request from ActorHeader
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		actor := strings.TrimSpace(request.Header.Get(ActorHeader))
+		if actor == "" {
+			actor = AnonymousActor
+		}
+		next.ServeHTTP(response, request.WithContext(WithActor(request.Context(), actor)))
+	})
+}
+
+// AdminMiddleware lets through only the requests carrying token as a bearer token in the Authorization header,
+// it answers 401 to the requests without credentials and 403 to the others, including all requests when token is empty
+func AdminMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			header := request.Header.Get("Authorization")
+			if header == "" {
+				response.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				httpresponse.WriteError(response, http.StatusUnauthorized, "admin token required")
+				return
+			}
+			provided, ok := strings.CutPrefix(header, bearerPrefix)
+			if !ok || token == "" || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(provided)), []byte(token)) != 1 {
+				httpresponse.WriteError(response, http.StatusForbidden, "forbidden")
+				return
+			}
+			next.ServeHTTP(response, request)
+		})
+	}
+}
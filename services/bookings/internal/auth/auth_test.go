@@ -0,0 +1,113 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/auth"
+)
+
+func TestActorFromContext(t *testing.T) {
+	assert.Equal(t, auth.AnonymousActor, auth.ActorFromContext(context.Background()))
+	assert.Equal(t, auth.AnonymousActor, auth.ActorFromContext(auth.WithActor(context.Background(), "")))
+	assert.Equal(t, "alice", auth.ActorFromContext(auth.WithActor(context.Background(), "alice")))
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{
+			name:     "Actor header",
+			header:   "alice",
+			expected: "alice",
+		},
+		{
+			name:     "Actor header with spaces",
+			header:   "  alice ",
+			expected: "alice",
+		},
+		{
+			name:     "No actor header",
+			expected: auth.AnonymousActor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actor string
+			handler := auth.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+				actor = auth.ActorFromContext(request.Context())
+			}))
+			request := httptest.NewRequest(http.MethodGet, "/bookings", nil)
+			if tt.header != "" {
+				request.Header.Set(auth.ActorHeader, tt.header)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+			assert.Equal(t, tt.expected, actor)
+		})
+	}
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			name:           "Valid token",
+			token:          "secret",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "No credentials",
+			token:          "secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Wrong token",
+			token:          "secret",
+			authorization:  "Bearer guess",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Not a bearer token",
+			token:          "secret",
+			authorization:  "Basic c2VjcmV0",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "No token configured",
+			authorization:  "Bearer ",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := auth.AdminMiddleware(tt.token)(http.HandlerFunc(func(response http.ResponseWriter, _ *http.Request) {
+				called = true
+				response.WriteHeader(http.StatusOK)
+			}))
+			request := httptest.NewRequest(http.MethodGet, "/admin/blackouts", nil)
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedStatus == http.StatusOK, called)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, recorder.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
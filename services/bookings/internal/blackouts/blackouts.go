@@ -0,0 +1,118 @@
+package blackouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+var ErrInvalidBlackout = errors.New("invalid blackout")
+
+//go:generate mockgen -package=mocks -destination=../mocks/blackouts.go github.com/zsoltggs/tabeo-interview/services/bookings/internal/blackouts Manager
+type Manager interface {
+	// Create stores a new blackout, the ID and the timestamps are set by the service
+	Create(ctx context.Context, blackout models.Blackout) (*models.Blackout, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.Blackout, error)
+	List(ctx context.Context, filters models.BlackoutFilters) ([]models.Blackout, error)
+	// Update replaces the launch pad, the period and the reason of the blackout with blackout.ID
+	Update(ctx context.Context, blackout models.Blackout) (*models.Blackout, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type manager struct {
+	db          database.Database
+	clock       clockwork.Clock
+	idGenerator func() uuid.UUID
+}
+
+func New(db database.Database, clock clockwork.Clock, idGenerator func() uuid.UUID) Manager {
+	return &manager{
+		db:          db,
+		clock:       clock,
+		idGenerator: idGenerator,
+	}
+}
+
+func (m *manager) Create(ctx context.Context, blackout models.Blackout) (*models.Blackout, error) {
+	err := validate(blackout)
+	if err != nil {
+		return nil, err
+	}
+	now := m.clock.Now()
+	blackout.ID = m.idGenerator()
+	blackout.CreatedAt = now
+	blackout.UpdatedAt = now
+	err = m.db.CreateBlackout(ctx, blackout)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create blackout: %w", err)
+	}
+	log.WithFields(log.Fields{
+		"blackout_id":   blackout.ID,
+		"launch_pad_id": blackout.LaunchPadID,
+		"starts_at":     blackout.StartsAt,
+		"ends_at":       blackout.EndsAt,
+	}).Info("blackout created")
+	return &blackout, nil
+}
+
+func (m *manager) Get(ctx context.Context, id uuid.UUID) (*models.Blackout, error) {
+	blackout, err := m.db.GetBlackout(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get blackout: %w", err)
+	}
+	return blackout, nil
+}
+
+func (m *manager) List(ctx context.Context, filters models.BlackoutFilters) ([]models.Blackout, error) {
+	blackouts, err := m.db.ListBlackouts(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list blackouts: %w", err)
+	}
+	return blackouts, nil
+}
+
+func (m *manager) Update(ctx context.Context, blackout models.Blackout) (*models.Blackout, error) {
+	err := validate(blackout)
+	if err != nil {
+		return nil, err
+	}
+	blackout.UpdatedAt = m.clock.Now()
+	err = m.db.UpdateBlackout(ctx, blackout)
+	if err != nil {
+		return nil, fmt.Errorf("unable to update blackout: %w", err)
+	}
+	log.WithFields(log.Fields{
+		"blackout_id":   blackout.ID,
+		"launch_pad_id": blackout.LaunchPadID,
+		"starts_at":     blackout.StartsAt,
+		"ends_at":       blackout.EndsAt,
+	}).Info("blackout updated")
+	// The creation time is only known by the database
+	return m.Get(ctx, blackout.ID)
+}
+
+func (m *manager) Delete(ctx context.Context, id uuid.UUID) error {
+	err := m.db.DeleteBlackout(ctx, id)
+	if err != nil {
+		return fmt.Errorf("unable to delete blackout: %w", err)
+	}
+	log.WithField("blackout_id", id).Info("blackout deleted")
+	return nil
+}
+
+func validate(blackout models.Blackout) error {
+	if blackout.StartsAt.IsZero() || blackout.EndsAt.IsZero() {
+		return fmt.Errorf("%w: starts_at and ends_at are required", ErrInvalidBlackout)
+	}
+	if !blackout.EndsAt.After(blackout.StartsAt) {
+		return fmt.Errorf("%w: ends_at has to be after starts_at", ErrInvalidBlackout)
+	}
+	return nil
+}
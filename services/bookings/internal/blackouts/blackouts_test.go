@@ -0,0 +1,132 @@
+package blackouts_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/blackouts"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+var (
+	now = time.Date(2049, 1, 1, 0, 0, 0, 0, time.UTC)
+	id  = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	day = time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+)
+
+func TestCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	manager := blackouts.New(mockDB, clockwork.NewFakeClockAt(now), func() uuid.UUID { return id })
+
+	expected := models.Blackout{
+		ID:          id,
+		LaunchPadID: "pad",
+		StartsAt:    day,
+		EndsAt:      day.AddDate(0, 0, 1),
+		Reason:      "Pad refurbishment",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	mockDB.EXPECT().CreateBlackout(gomock.Any(), expected).Return(nil)
+
+	blackout, err := manager.Create(context.Background(), models.Blackout{
+		LaunchPadID: "pad",
+		StartsAt:    day,
+		EndsAt:      day.AddDate(0, 0, 1),
+		Reason:      "Pad refurbishment",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, *blackout)
+}
+
+func TestCreate_Invalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	manager := blackouts.New(mockDB, clockwork.NewFakeClockAt(now), func() uuid.UUID { return id })
+
+	tests := []struct {
+		name     string
+		blackout models.Blackout
+	}{
+		{
+			name:     "Missing period",
+			blackout: models.Blackout{LaunchPadID: "pad"},
+		},
+		{
+			name:     "Ends before it starts",
+			blackout: models.Blackout{StartsAt: day, EndsAt: day.Add(-time.Hour)},
+		},
+		{
+			name:     "Empty period",
+			blackout: models.Blackout{StartsAt: day, EndsAt: day},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manager.Create(context.Background(), tt.blackout)
+			assert.ErrorIs(t, err, blackouts.ErrInvalidBlackout)
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	clock := clockwork.NewFakeClockAt(now)
+	manager := blackouts.New(mockDB, clock, func() uuid.UUID { return id })
+	clock.Advance(time.Hour)
+
+	updated := models.Blackout{
+		ID:        id,
+		StartsAt:  day,
+		EndsAt:    day.AddDate(0, 0, 2),
+		Reason:    "Longer refurbishment",
+		UpdatedAt: now.Add(time.Hour),
+	}
+	stored := updated
+	stored.CreatedAt = now
+	mockDB.EXPECT().UpdateBlackout(gomock.Any(), updated).Return(nil)
+	mockDB.EXPECT().GetBlackout(gomock.Any(), id).Return(&stored, nil)
+
+	blackout, err := manager.Update(context.Background(), models.Blackout{
+		ID:       id,
+		StartsAt: day,
+		EndsAt:   day.AddDate(0, 0, 2),
+		Reason:   "Longer refurbishment",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, stored, *blackout)
+}
+
+func TestUpdate_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	manager := blackouts.New(mockDB, clockwork.NewFakeClockAt(now), func() uuid.UUID { return id })
+
+	mockDB.EXPECT().UpdateBlackout(gomock.Any(), gomock.Any()).Return(database.ErrNotFound)
+
+	_, err := manager.Update(context.Background(), models.Blackout{ID: id, StartsAt: day, EndsAt: day.AddDate(0, 0, 1)})
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	manager := blackouts.New(mockDB, clockwork.NewFakeClockAt(now), func() uuid.UUID { return id })
+
+	mockDB.EXPECT().DeleteBlackout(gomock.Any(), id).Return(errors.New("connection reset"))
+
+	err := manager.Delete(context.Background(), id)
+	assert.ErrorContains(t, err, "unable to delete blackout")
+}
@@ -0,0 +1,98 @@
+package cachestore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kind selects the cache backend
+type Kind string
+
+const (
+	// KindMemory keeps the entries in the memory of the replica
+	KindMemory Kind = "memory"
+	// KindPostgres shares the entries between the replicas through Postgres, with a local copy in memory
+	KindPostgres Kind = "postgres"
+)
+
+func ParseKind(value string) (Kind, error) {
+	switch Kind(value) {
+	case KindMemory, KindPostgres:
+		return Kind(value), nil
+	default:
+		return "", fmt.Errorf("unknown cache backend %q", value)
+	}
+}
+
+// Entry is a cached value
+type Entry struct {
+	Value    []byte
+	StoredAt time.Time
+	// ExpiresAt is zero for entries that never expire
+	ExpiresAt time.Time
+}
+
+// ttl returns the time the entry has left at now, zero for entries that never expire
+func (e Entry) ttl(now time.Time) time.Duration {
+	if e.ExpiresAt.IsZero() {
+		return 0
+	}
+	return e.ExpiresAt.Sub(now)
+}
+
+// KeyInfo describes a cached entry without its value
+type KeyInfo struct {
+	Key      string
+	StoredAt time.Time
+	// ExpiresAt is zero for entries that never expire
+	ExpiresAt time.Time
+}
+
+//go:generate mockgen -package=mocks -destination=../mocks/cachestore.go github.com/zsoltggs/tabeo-interview/services/bookings/internal/cachestore Backend
+type Backend interface {
+	// Get returns false if the key is missing or expired
+	Get(ctx context.Context, key string) (Entry, bool, error)
+	// Set stores the value for ttl, a ttl of zero or less never expires
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	// List returns the entries whose key starts with prefix, expired entries are left out
+	List(ctx context.Context, prefix string) ([]KeyInfo, error)
+}
+
+type prefixed struct {
+	prefix  string
+	backend Backend
+}
+
+// WithPrefix namespaces the keys, so several caches can share a backend
+func WithPrefix(backend Backend, prefix string) Backend {
+	return &prefixed{
+		prefix:  prefix,
+		backend: backend,
+	}
+}
+
+func (p *prefixed) Get(ctx context.Context, key string) (Entry, bool, error) {
+	return p.backend.Get(ctx, p.prefix+key)
+}
+
+func (p *prefixed) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return p.backend.Set(ctx, p.prefix+key, value, ttl)
+}
+
+func (p *prefixed) Delete(ctx context.Context, key string) error {
+	return p.backend.Delete(ctx, p.prefix+key)
+}
+
+func (p *prefixed) List(ctx context.Context, prefix string) ([]KeyInfo, error) {
+	keys, err := p.backend.List(ctx, p.prefix+prefix)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		keys[i].Key = strings.TrimPrefix(keys[i].Key, p.prefix)
+	}
+	return keys, nil
+}
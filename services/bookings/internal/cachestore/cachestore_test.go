@@ -0,0 +1,204 @@
+package cachestore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(now)
+	backend := NewMemory(clock, 0)
+
+	assert.NoError(t, backend.Set(ctx, "short", []byte("1"), time.Minute))
+	assert.NoError(t, backend.Set(ctx, "forever", []byte("2"), 0))
+
+	entry, ok, err := backend.Get(ctx, "short")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Entry{Value: []byte("1"), StoredAt: now, ExpiresAt: now.Add(time.Minute)}, entry)
+
+	clock.Advance(time.Minute)
+	_, ok, err = backend.Get(ctx, "short")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	entry, ok, err = backend.Get(ctx, "forever")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, entry.ExpiresAt.IsZero())
+
+	assert.NoError(t, backend.Set(ctx, "other", []byte("3"), 0))
+	keys, err := backend.List(ctx, "for")
+	assert.NoError(t, err)
+	assert.Equal(t, []KeyInfo{{Key: "forever", StoredAt: now}}, keys)
+
+	assert.NoError(t, backend.Delete(ctx, "forever"))
+	_, ok, err = backend.Get(ctx, "forever")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestWithPrefix(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemory(clockwork.NewFakeClock(), 0)
+	first := WithPrefix(backend, "first:")
+	second := WithPrefix(backend, "second:")
+
+	assert.NoError(t, first.Set(ctx, "key", []byte("1"), 0))
+	assert.NoError(t, second.Set(ctx, "key", []byte("2"), 0))
+
+	entry, ok, err := first.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), entry.Value)
+
+	entry, ok, err = backend.Get(ctx, "second:key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("2"), entry.Value)
+
+	keys, err := second.List(ctx, "")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, "key", keys[0].Key)
+
+	assert.NoError(t, first.Delete(ctx, "key"))
+	_, ok, _ = first.Get(ctx, "key")
+	assert.False(t, ok)
+	_, ok, _ = second.Get(ctx, "key")
+	assert.True(t, ok)
+}
+
+// fakeShared is a shared backend whose invalidations are sent by the test
+type fakeShared struct {
+	Backend
+	listening     chan struct{}
+	invalidations chan string
+	invalidated   chan struct{}
+}
+
+func newFakeShared(clock clockwork.Clock) *fakeShared {
+	return &fakeShared{
+		Backend:       NewMemory(clock, 0),
+		listening:     make(chan struct{}),
+		invalidations: make(chan string),
+		invalidated:   make(chan struct{}),
+	}
+}
+
+func (f *fakeShared) Listen(ctx context.Context, invalidate func(key string)) {
+	close(f.listening)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case key := <-f.invalidations:
+			invalidate(key)
+			f.invalidated <- struct{}{}
+		}
+	}
+}
+
+func (f *fakeShared) Close() {}
+
+// invalidate notifies the listener as another replica would, and waits for the key to be invalidated
+func (f *fakeShared) invalidate(key string) {
+	f.invalidations <- key
+	<-f.invalidated
+}
+
+func TestTiered_ReadsThroughShared(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	now := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(now)
+	shared := newFakeShared(clock)
+	backend := NewTiered(ctx, clock, 0, shared)
+
+	// Stored by another replica a while ago
+	assert.NoError(t, shared.Set(ctx, "key", []byte("1"), time.Hour))
+	clock.Advance(10 * time.Minute)
+
+	entry, ok, err := backend.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, now, entry.StoredAt, "the age of the entry is kept")
+
+	// Served locally from now on, until it expires at the same time as the shared entry
+	assert.NoError(t, shared.Delete(ctx, "key"))
+	entry, ok, err = backend.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, now, entry.StoredAt)
+	assert.Equal(t, now.Add(time.Hour), entry.ExpiresAt)
+
+	clock.Advance(50 * time.Minute)
+	_, ok, err = backend.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestTiered_WritesThroughAndInvalidates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := clockwork.NewFakeClock()
+	shared := newFakeShared(clock)
+	backend := NewTiered(ctx, clock, 0, shared)
+	<-shared.listening
+
+	assert.NoError(t, backend.Set(ctx, "key", []byte("1"), 0))
+	entry, ok, err := shared.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), entry.Value)
+
+	// Another replica changes the entry
+	assert.NoError(t, shared.Set(ctx, "key", []byte("2"), 0))
+	shared.invalidate("key")
+
+	entry, ok, err = backend.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("2"), entry.Value)
+
+	assert.NoError(t, backend.Delete(ctx, "key"))
+	_, ok, _ = shared.Get(ctx, "key")
+	assert.False(t, ok)
+	_, ok, _ = backend.Get(ctx, "key")
+	assert.False(t, ok)
+}
+
+func TestTiered_Concurrent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := clockwork.NewFakeClock()
+	shared := newFakeShared(clock)
+	backend := NewTiered(ctx, clock, 10, shared)
+	<-shared.listening
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = backend.Set(ctx, "key", []byte{byte(i)}, time.Minute)
+			_, _, _ = backend.Get(ctx, "key")
+			_ = backend.Delete(ctx, "key")
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			shared.invalidate("key")
+		}
+	}()
+	wg.Wait()
+}
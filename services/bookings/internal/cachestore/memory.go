@@ -0,0 +1,62 @@
+package cachestore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/lru"
+)
+
+type memory struct {
+	clock   clockwork.Clock
+	entries *lru.Cache[string, Entry]
+}
+
+// NewMemory keeps at most maxEntries entries in memory, the least recently used are evicted
+func NewMemory(clock clockwork.Clock, maxEntries int) Backend {
+	return &memory{
+		clock:   clock,
+		entries: lru.New[string, Entry](clock, maxEntries),
+	}
+}
+
+func (m *memory) Get(_ context.Context, key string) (Entry, bool, error) {
+	entry, _, ok := m.entries.Get(key)
+	return entry, ok, nil
+}
+
+func (m *memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.set(key, Entry{Value: value, StoredAt: m.clock.Now()}, ttl)
+	return nil
+}
+
+func (m *memory) Delete(_ context.Context, key string) error {
+	m.entries.Delete(key)
+	return nil
+}
+
+func (m *memory) List(_ context.Context, prefix string) ([]KeyInfo, error) {
+	var keys []KeyInfo
+	m.entries.Range(func(key string, entry Entry, _ time.Time) bool {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, KeyInfo{
+				Key:       key,
+				StoredAt:  entry.StoredAt,
+				ExpiresAt: entry.ExpiresAt,
+			})
+		}
+		return true
+	})
+	return keys, nil
+}
+
+// set stores the entry as is, so copies of shared entries keep the time they were stored at
+func (m *memory) set(key string, entry Entry, ttl time.Duration) {
+	if ttl > 0 {
+		entry.ExpiresAt = m.clock.Now().Add(ttl)
+	}
+	m.entries.Set(key, entry, ttl)
+}
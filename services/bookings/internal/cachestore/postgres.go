@@ -0,0 +1,197 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database/queries"
+)
+
+const (
+	invalidationChannel = "cache_invalidation"
+	// listenRetryDelay is how long to wait before listening again after the connection was lost
+	listenRetryDelay = time.Second
+	// purgeInterval is how often expired entries are deleted
+	purgeInterval = 10 * time.Minute
+)
+
+// Shared is a backend shared by the replicas
+type Shared interface {
+	Backend
+	// Listen calls invalidate with the keys set or deleted by other replicas until ctx is done
+	Listen(ctx context.Context, invalidate func(key string))
+	// Close closes the connections to the backend
+	Close()
+}
+
+type postgres struct {
+	pool    *pgxpool.Pool
+	queries *queries.Queries
+	clock   clockwork.Clock
+	// instanceID tells apart the notifications of this replica
+	instanceID string
+}
+
+// NewPostgres stores the entries in the cache_entries table and notifies the other replicas of the changes
+func NewPostgres(ctx context.Context, connectionStr string, clock clockwork.Clock) (Shared, error) {
+	pool, err := pgxpool.New(ctx, connectionStr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %w", err)
+	}
+	err = pool.Ping(ctx)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("unable to ping database: %w", err)
+	}
+	return &postgres{
+		pool:       pool,
+		queries:    queries.New(pool),
+		clock:      clock,
+		instanceID: uuid.NewString(),
+	}, nil
+}
+
+func (p *postgres) Get(ctx context.Context, key string) (Entry, bool, error) {
+	entry, err := p.queries.GetCacheEntry(ctx, queries.GetCacheEntryParams{
+		Key: key,
+		Now: pgtype.Timestamptz{Time: p.clock.Now(), Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Entry{}, false, nil
+		}
+		return Entry{}, false, fmt.Errorf("unable to get cache entry: %w", err)
+	}
+	return Entry{
+		Value:     entry.Value,
+		StoredAt:  entry.StoredAt.Time,
+		ExpiresAt: entry.ExpiresAt.Time, // Zero when NULL
+	}, true, nil
+}
+
+func (p *postgres) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	now := p.clock.Now()
+	expiresAt := pgtype.Timestamptz{}
+	if ttl > 0 {
+		expiresAt = pgtype.Timestamptz{Time: now.Add(ttl), Valid: true}
+	}
+	err := p.queries.SetCacheEntry(ctx, queries.SetCacheEntryParams{
+		Key:       key,
+		Value:     value,
+		StoredAt:  pgtype.Timestamptz{Time: now, Valid: true},
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to set cache entry: %w", err)
+	}
+	return p.notify(ctx, key)
+}
+
+func (p *postgres) Delete(ctx context.Context, key string) error {
+	err := p.queries.DeleteCacheEntry(ctx, key)
+	if err != nil {
+		return fmt.Errorf("unable to delete cache entry: %w", err)
+	}
+	return p.notify(ctx, key)
+}
+
+func (p *postgres) List(ctx context.Context, prefix string) ([]KeyInfo, error) {
+	entries, err := p.queries.ListCacheEntries(ctx, queries.ListCacheEntriesParams{
+		Prefix: prefix,
+		Now:    pgtype.Timestamptz{Time: p.clock.Now(), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list cache entries: %w", err)
+	}
+	keys := make([]KeyInfo, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, KeyInfo{
+			Key:       entry.Key,
+			StoredAt:  entry.StoredAt.Time,
+			ExpiresAt: entry.ExpiresAt.Time,
+		})
+	}
+	return keys, nil
+}
+
+func (p *postgres) Close() {
+	p.pool.Close()
+}
+
+func (p *postgres) notify(ctx context.Context, key string) error {
+	err := p.queries.NotifyCacheInvalidation(ctx, p.instanceID+"|"+key)
+	if err != nil {
+		return fmt.Errorf("unable to notify cache invalidation: %w", err)
+	}
+	return nil
+}
+
+func (p *postgres) Listen(ctx context.Context, invalidate func(key string)) {
+	purge := p.clock.NewTicker(purgeInterval)
+	defer purge.Stop()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-purge.Chan():
+				deleted, err := p.queries.DeleteExpiredCacheEntries(ctx, pgtype.Timestamptz{Time: p.clock.Now(), Valid: true})
+				if err != nil {
+					log.WithError(err).Warn("unable to purge expired cache entries")
+					continue
+				}
+				log.WithField("deleted", deleted).Debug("expired cache entries purged")
+			}
+		}
+	}()
+
+	for {
+		err := p.listen(ctx, invalidate)
+		if ctx.Err() != nil {
+			return
+		}
+		log.WithError(err).Warn("cache invalidation listener stopped, listening again")
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.clock.After(listenRetryDelay):
+		}
+	}
+}
+
+func (p *postgres) listen(ctx context.Context, invalidate func(key string)) error {
+	conn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to acquire connection: %w", err)
+	}
+	// LISTEN is bound to the session, the connection must not go back to the pool.
+	// Once hijacked, conn cannot be used anymore, only the connection it returned.
+	listenConn := conn.Hijack()
+	defer listenConn.Close(context.Background())
+
+	_, err = listenConn.Exec(ctx, "LISTEN "+invalidationChannel)
+	if err != nil {
+		return fmt.Errorf("unable to listen: %w", err)
+	}
+	for {
+		notification, err := listenConn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to wait for notification: %w", err)
+		}
+		instanceID, key, ok := strings.Cut(notification.Payload, "|")
+		if !ok || instanceID == p.instanceID {
+			continue
+		}
+		invalidate(key)
+	}
+}
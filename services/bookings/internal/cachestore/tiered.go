@@ -0,0 +1,65 @@
+package cachestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+)
+
+type tiered struct {
+	clock  clockwork.Clock
+	local  *memory
+	shared Shared
+}
+
+// NewTiered serves the entries from memory and falls back to the shared backend, the local copies
+// are dropped when another replica changes them. The invalidations are listened to until ctx is done.
+func NewTiered(ctx context.Context, clock clockwork.Clock, maxEntries int, shared Shared) Backend {
+	t := &tiered{
+		clock:  clock,
+		local:  NewMemory(clock, maxEntries).(*memory),
+		shared: shared,
+	}
+	go shared.Listen(ctx, func(key string) {
+		t.local.entries.Delete(key)
+	})
+	return t
+}
+
+func (t *tiered) Get(ctx context.Context, key string) (Entry, bool, error) {
+	if entry, ok, _ := t.local.Get(ctx, key); ok {
+		return entry, true, nil
+	}
+	entry, ok, err := t.shared.Get(ctx, key)
+	if err != nil || !ok {
+		return Entry{}, false, err
+	}
+	ttl := entry.ttl(t.clock.Now())
+	if entry.ExpiresAt.IsZero() || ttl > 0 {
+		// Keep the time it was stored by the other replica, it tells how fresh the entry is
+		t.local.set(key, Entry{Value: entry.Value, StoredAt: entry.StoredAt}, ttl)
+	}
+	return entry, true, nil
+}
+
+func (t *tiered) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_ = t.local.Set(ctx, key, value, ttl)
+	err := t.shared.Set(ctx, key, value, ttl)
+	if err != nil {
+		// The local copy is still usable, the other replicas fetch the value themselves
+		log.WithError(err).WithField("key", key).Warn("unable to share cache entry")
+	}
+	return nil
+}
+
+func (t *tiered) Delete(ctx context.Context, key string) error {
+	_ = t.local.Delete(ctx, key)
+	return t.shared.Delete(ctx, key)
+}
+
+// List returns the shared entries, the local copies are a subset of them
+func (t *tiered) List(ctx context.Context, prefix string) ([]KeyInfo, error) {
+	return t.shared.List(ctx, prefix)
+}
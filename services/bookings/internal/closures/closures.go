@@ -0,0 +1,133 @@
+package closures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+var (
+	ErrInvalidFile       = errors.New("invalid closures file")
+	ErrUnsupportedFormat = errors.New("unsupported closures format")
+)
+
+// Format of a closures file
+type Format string
+
+const (
+	FormatICS Format = "ics"
+	FormatCSV Format = "csv"
+)
+
+// FormatFromFileName detects the format from the extension of the file
+func FormatFromFileName(name string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), "."))
+}
+
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(value)) {
+	case FormatICS:
+		return FormatICS, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, value)
+	}
+}
+
+//go:generate mockgen -package=mocks -destination=../mocks/closures.go github.com/zsoltggs/tabeo-interview/services/bookings/internal/closures Importer
+type Importer interface {
+	// Import replaces the closures of source with the closures read from r, it returns the number of closures imported
+	Import(ctx context.Context, source string, format Format, r io.Reader) (int, error)
+	// ImportDirectory imports every .ics and .csv file of dir, the file name is used as the source
+	ImportDirectory(ctx context.Context, dir string) error
+}
+
+type importer struct {
+	db          database.Database
+	clock       clockwork.Clock
+	idGenerator func() uuid.UUID
+}
+
+func New(db database.Database, clock clockwork.Clock, idGenerator func() uuid.UUID) Importer {
+	return &importer{
+		db:          db,
+		clock:       clock,
+		idGenerator: idGenerator,
+	}
+}
+
+func (i *importer) Import(ctx context.Context, source string, format Format, r io.Reader) (int, error) {
+	var parse func(io.Reader) ([]models.Closure, error)
+	switch format {
+	case FormatICS:
+		parse = ParseICS
+	case FormatCSV:
+		parse = ParseCSV
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+	closures, err := parse(r)
+	if err != nil {
+		return 0, err
+	}
+	now := i.clock.Now()
+	for idx := range closures {
+		closures[idx].ID = i.idGenerator()
+		closures[idx].Source = source
+		closures[idx].CreatedAt = now
+	}
+	err = i.db.ReplaceClosures(ctx, source, closures)
+	if err != nil {
+		return 0, fmt.Errorf("unable to store closures: %w", err)
+	}
+	log.WithFields(log.Fields{
+		"source":   source,
+		"closures": len(closures),
+	}).Info("closures imported")
+	return len(closures), nil
+}
+
+func (i *importer) ImportDirectory(ctx context.Context, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("unable to read closures directory: %w", err)
+	}
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		format, err := FormatFromFileName(entry.Name())
+		if err != nil {
+			continue
+		}
+		// A broken file must not stop the other files from being imported
+		err = i.importFile(ctx, filepath.Join(dir, entry.Name()), entry.Name(), format)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (i *importer) importFile(ctx context.Context, path string, source string, format Format) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to open file: %w", err)
+	}
+	defer f.Close()
+	_, err = i.Import(ctx, source, format, f)
+	return err
+}
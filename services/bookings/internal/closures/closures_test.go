@@ -0,0 +1,74 @@
+package closures_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/closures"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+var (
+	now = time.Date(2049, 1, 1, 0, 0, 0, 0, time.UTC)
+	id  = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+)
+
+func TestImport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	importer := closures.New(mockDB, clockwork.NewFakeClockAt(now), func() uuid.UUID { return id })
+
+	mockDB.EXPECT().ReplaceClosures(gomock.Any(), "partner.csv", []models.Closure{{
+		ID:          id,
+		LaunchPadID: "pad",
+		StartsAt:    time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC),
+		EndsAt:      time.Date(2049, 7, 8, 0, 0, 0, 0, time.UTC),
+		Source:      "partner.csv",
+		CreatedAt:   now,
+	}}).Return(nil)
+
+	count, err := importer.Import(context.Background(), "partner.csv", closures.FormatCSV,
+		strings.NewReader("launch_pad_id,starts_at,ends_at\npad,2049-07-07,2049-07-07\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = importer.Import(context.Background(), "partner.txt", closures.Format("txt"), strings.NewReader(""))
+	assert.ErrorIs(t, err, closures.ErrUnsupportedFormat)
+}
+
+func TestImportDirectory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	importer := closures.New(mockDB, clockwork.NewFakeClockAt(now), func() uuid.UUID { return id })
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"partner.ics": "BEGIN:VEVENT\nLOCATION:pad\nDTSTART;VALUE=DATE:20490707\nEND:VEVENT\n",
+		"partner.csv": "launch_pad_id,starts_at,ends_at\npad,2049-07-08,2049-07-08\n",
+		"broken.csv":  "launch_pad_id\npad\n",
+		"notes.txt":   "ignored",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	mockDB.EXPECT().ReplaceClosures(gomock.Any(), "partner.ics", gomock.Len(1)).Return(nil)
+	mockDB.EXPECT().ReplaceClosures(gomock.Any(), "partner.csv", gomock.Len(1)).Return(errors.New("boom"))
+
+	err := importer.ImportDirectory(context.Background(), dir)
+	assert.ErrorIs(t, err, closures.ErrInvalidFile)
+	assert.ErrorContains(t, err, "broken.csv")
+	assert.ErrorContains(t, err, "partner.csv: unable to store closures: boom")
+}
@@ -0,0 +1,105 @@
+package closures
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+const (
+	columnLaunchPadID = "launch_pad_id"
+	columnStartsAt    = "starts_at"
+	columnEndsAt      = "ends_at"
+	columnSummary     = "summary"
+)
+
+// ParseCSV reads closures from a CSV file with a header row naming the launch_pad_id, starts_at, ends_at
+// and optionally summary columns. Times are RFC3339 or dates, a date as ends_at closes the whole day.
+func ParseCSV(r io.Reader) ([]models.Closure, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: unable to read header: %s", ErrInvalidFile, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{columnLaunchPadID, columnStartsAt, columnEndsAt} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column", ErrInvalidFile, required)
+		}
+	}
+
+	var result []models.Closure
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+		}
+		line, _ := reader.FieldPos(0)
+		closure, err := toCSVClosure(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidFile, line, err)
+		}
+		result = append(result, closure)
+	}
+	return result, nil
+}
+
+func toCSVClosure(record []string, columns map[string]int) (models.Closure, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	launchPadID := field(columnLaunchPadID)
+	if launchPadID == "" {
+		return models.Closure{}, fmt.Errorf("missing %s", columnLaunchPadID)
+	}
+	startsAt, _, err := parseCSVTime(field(columnStartsAt))
+	if err != nil {
+		return models.Closure{}, fmt.Errorf("invalid %s: %w", columnStartsAt, err)
+	}
+	endsAt, isDate, err := parseCSVTime(field(columnEndsAt))
+	if err != nil {
+		return models.Closure{}, fmt.Errorf("invalid %s: %w", columnEndsAt, err)
+	}
+	if isDate {
+		endsAt = endsAt.AddDate(0, 0, 1)
+	}
+	if !endsAt.After(startsAt) {
+		return models.Closure{}, fmt.Errorf("closure ends before it starts")
+	}
+	return models.Closure{
+		LaunchPadID: launchPadID,
+		StartsAt:    startsAt,
+		EndsAt:      endsAt,
+		Summary:     field(columnSummary),
+	}, nil
+}
+
+func parseCSVTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("expected RFC3339 time or date, got %q", value)
+	}
+	return t.UTC(), false, nil
+}
@@ -0,0 +1,64 @@
+package closures
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+func TestParseCSV(t *testing.T) {
+	sheet := `launch_pad_id,starts_at,ends_at,summary
+5e9e4501f509094ba4566f84,2049-07-07T10:00:00Z,2049-07-07T18:00:00Z,"Range maintenance, north"
+5e9e4501f509094ba4566f84,2049-07-08,2049-07-09,
+`
+	closures, err := ParseCSV(strings.NewReader(sheet))
+	require.NoError(t, err)
+	assert.Equal(t, []models.Closure{
+		{
+			LaunchPadID: "5e9e4501f509094ba4566f84",
+			StartsAt:    time.Date(2049, 7, 7, 10, 0, 0, 0, time.UTC),
+			EndsAt:      time.Date(2049, 7, 7, 18, 0, 0, 0, time.UTC),
+			Summary:     "Range maintenance, north",
+		},
+		{
+			LaunchPadID: "5e9e4501f509094ba4566f84",
+			StartsAt:    time.Date(2049, 7, 8, 0, 0, 0, 0, time.UTC),
+			EndsAt:      time.Date(2049, 7, 10, 0, 0, 0, 0, time.UTC),
+		},
+	}, closures)
+}
+
+func TestParseCSV_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		sheet       string
+		expectedErr string
+	}{
+		{
+			name:        "missing column",
+			sheet:       "launch_pad_id,starts_at\npad,2049-07-07\n",
+			expectedErr: "invalid closures file: missing ends_at column",
+		},
+		{
+			name:        "invalid time",
+			sheet:       "launch_pad_id,starts_at,ends_at\npad,tomorrow,2049-07-07\n",
+			expectedErr: `invalid closures file: line 2: invalid starts_at: expected RFC3339 time or date, got "tomorrow"`,
+		},
+		{
+			name:        "missing launch pad",
+			sheet:       "launch_pad_id,starts_at,ends_at\n,2049-07-07,2049-07-07\n",
+			expectedErr: "invalid closures file: line 2: missing launch_pad_id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(tt.sheet))
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package closures
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+// launchPadProperty names the launch pad of an event, LOCATION is used when it is missing
+const launchPadProperty = "X-LAUNCH-PAD-ID"
+
+var durationRegexp = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseICS reads the VEVENTs of an iCalendar file, every event closes its launch pad from DTSTART to DTEND.
+// All-day events close the launch pad for whole days in UTC, cancelled events are skipped.
+func ParseICS(r io.Reader) ([]models.Closure, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+
+	var result []models.Closure
+	var event map[string]icsProperty
+	eventLine := 0
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		property, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidFile, i+1, err)
+		}
+		switch {
+		case property.name == "BEGIN" && strings.EqualFold(property.value, "VEVENT"):
+			event = make(map[string]icsProperty)
+			eventLine = i + 1
+		case property.name == "END" && strings.EqualFold(property.value, "VEVENT"):
+			if event == nil {
+				return nil, fmt.Errorf("%w: line %d: END:VEVENT without BEGIN:VEVENT", ErrInvalidFile, i+1)
+			}
+			closure, ok, err := toClosure(event)
+			if err != nil {
+				return nil, fmt.Errorf("%w: event at line %d: %s", ErrInvalidFile, eventLine, err)
+			}
+			if ok {
+				result = append(result, closure)
+			}
+			event = nil
+		case event != nil:
+			// Only the first occurrence of a property is used
+			if _, ok := event[property.name]; !ok {
+				event[property.name] = property
+			}
+		}
+	}
+	if event != nil {
+		return nil, fmt.Errorf("%w: event at line %d is not closed", ErrInvalidFile, eventLine)
+	}
+	return result, nil
+}
+
+// unfold joins the lines that were folded with a leading space or tab
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			// Keep the line numbers in the errors in sync with the file
+			lines = append(lines, "")
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func parseProperty(line string) (icsProperty, error) {
+	// The value starts at the first colon that is not inside a quoted parameter value
+	inQuotes := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		}
+		if c == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon == -1 {
+		return icsProperty{}, fmt.Errorf("missing colon in %q", line)
+	}
+	parts := strings.Split(line[:colon], ";")
+	property := icsProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string),
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		property.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return property, nil
+}
+
+func toClosure(event map[string]icsProperty) (models.Closure, bool, error) {
+	if status, ok := event["STATUS"]; ok && strings.EqualFold(status.value, "CANCELLED") {
+		return models.Closure{}, false, nil
+	}
+	launchPad, ok := event[launchPadProperty]
+	if !ok {
+		launchPad, ok = event["LOCATION"]
+	}
+	launchPadID := strings.TrimSpace(unescape(launchPad.value))
+	if !ok || launchPadID == "" {
+		return models.Closure{}, false, fmt.Errorf("missing %s or LOCATION", launchPadProperty)
+	}
+	start, ok := event["DTSTART"]
+	if !ok {
+		return models.Closure{}, false, fmt.Errorf("missing DTSTART")
+	}
+	startsAt, allDay, err := parseICSTime(start)
+	if err != nil {
+		return models.Closure{}, false, fmt.Errorf("invalid DTSTART: %w", err)
+	}
+
+	var endsAt time.Time
+	if end, ok := event["DTEND"]; ok {
+		endsAt, _, err = parseICSTime(end)
+		if err != nil {
+			return models.Closure{}, false, fmt.Errorf("invalid DTEND: %w", err)
+		}
+	} else if duration, ok := event["DURATION"]; ok {
+		d, err := parseICSDuration(duration.value)
+		if err != nil {
+			return models.Closure{}, false, fmt.Errorf("invalid DURATION: %w", err)
+		}
+		endsAt = startsAt.Add(d)
+	} else if allDay {
+		endsAt = startsAt.AddDate(0, 0, 1)
+	} else {
+		return models.Closure{}, false, fmt.Errorf("missing DTEND or DURATION")
+	}
+	if !endsAt.After(startsAt) {
+		return models.Closure{}, false, fmt.Errorf("event ends before it starts")
+	}
+
+	return models.Closure{
+		LaunchPadID: launchPadID,
+		StartsAt:    startsAt,
+		EndsAt:      endsAt,
+		Summary:     unescape(event["SUMMARY"].value),
+	}, true, nil
+}
+
+// parseICSTime parses DATE and DATE-TIME values, floating times are treated as UTC
+func parseICSTime(property icsProperty) (time.Time, bool, error) {
+	value := property.value
+	if property.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.Parse("20060102", value)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	location := time.UTC
+	if tzid, ok := property.params["TZID"]; ok {
+		var err error
+		location, err = time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown TZID %s", tzid)
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, location)
+	return t.UTC(), false, err
+}
+
+func parseICSDuration(value string) (time.Duration, error) {
+	match := durationRegexp.FindStringSubmatch(value)
+	if match == nil || value == "P" || value == "PT" {
+		return 0, fmt.Errorf("unsupported duration %q", value)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if match[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(match[i+2])
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(n) * unit
+	}
+	if match[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
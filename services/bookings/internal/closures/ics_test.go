@@ -0,0 +1,106 @@
+package closures
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+func TestParseICS(t *testing.T) {
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Partner//Pad operations//EN",
+		"BEGIN:VEVENT",
+		"UID:1@partner",
+		"DTSTART:20490707T100000Z",
+		"DTEND:20490707T180000Z",
+		"SUMMARY:Range maintenance\\, north",
+		"X-LAUNCH-PAD-ID:5e9e4501f509094ba4566f84",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:2@partner",
+		"DTSTART;VALUE=DATE:20490708",
+		"LOCATION:5e9e4501f509094ba4566f84",
+		"SUMMARY:Pad closed for",
+		"  inspection",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:3@partner",
+		"DTSTART;TZID=America/New_York:20490709T080000",
+		"DURATION:PT2H30M",
+		"LOCATION:5e9e4502f509092b78566f87",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:4@partner",
+		"STATUS:CANCELLED",
+		"DTSTART:20490710T100000Z",
+		"DTEND:20490710T180000Z",
+		"LOCATION:5e9e4502f509092b78566f87",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	closures, err := ParseICS(strings.NewReader(calendar))
+	require.NoError(t, err)
+	assert.Equal(t, []models.Closure{
+		{
+			LaunchPadID: "5e9e4501f509094ba4566f84",
+			StartsAt:    time.Date(2049, 7, 7, 10, 0, 0, 0, time.UTC),
+			EndsAt:      time.Date(2049, 7, 7, 18, 0, 0, 0, time.UTC),
+			Summary:     "Range maintenance, north",
+		},
+		{
+			LaunchPadID: "5e9e4501f509094ba4566f84",
+			StartsAt:    time.Date(2049, 7, 8, 0, 0, 0, 0, time.UTC),
+			EndsAt:      time.Date(2049, 7, 9, 0, 0, 0, 0, time.UTC),
+			Summary:     "Pad closed for inspection",
+		},
+		{
+			LaunchPadID: "5e9e4502f509092b78566f87",
+			StartsAt:    time.Date(2049, 7, 9, 12, 0, 0, 0, time.UTC),
+			EndsAt:      time.Date(2049, 7, 9, 14, 30, 0, 0, time.UTC),
+		},
+	}, closures)
+}
+
+func TestParseICS_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		lines       []string
+		expectedErr string
+	}{
+		{
+			name:        "missing launch pad",
+			lines:       []string{"BEGIN:VEVENT", "DTSTART:20490707T100000Z", "DTEND:20490707T180000Z", "END:VEVENT"},
+			expectedErr: "invalid closures file: event at line 1: missing X-LAUNCH-PAD-ID or LOCATION",
+		},
+		{
+			name:        "ends before it starts",
+			lines:       []string{"BEGIN:VEVENT", "LOCATION:pad", "DTSTART:20490707T100000Z", "DTEND:20490707T080000Z", "END:VEVENT"},
+			expectedErr: "invalid closures file: event at line 1: event ends before it starts",
+		},
+		{
+			name:        "not closed",
+			lines:       []string{"BEGIN:VCALENDAR", "BEGIN:VEVENT", "LOCATION:pad"},
+			expectedErr: "invalid closures file: event at line 2 is not closed",
+		},
+		{
+			name:        "invalid line",
+			lines:       []string{"BEGIN:VEVENT", "LOCATION"},
+			expectedErr: `invalid closures file: line 2: missing colon in "LOCATION"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseICS(strings.NewReader(strings.Join(tt.lines, "\n")))
+			assert.EqualError(t, err, tt.expectedErr)
+			assert.ErrorIs(t, err, ErrInvalidFile)
+		})
+	}
+}
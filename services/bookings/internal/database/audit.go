@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database/queries"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+func (q *pg) AppendAudit(ctx context.Context, entry models.AuditEntry) error {
+	before, err := marshalSnapshot(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshalSnapshot(entry.After)
+	if err != nil {
+		return err
+	}
+	err = q.queries.CreateBookingAuditEntry(ctx, queries.CreateBookingAuditEntryParams{
+		BookingID:  entry.BookingID,
+		Action:     entry.Action,
+		Actor:      entry.Actor,
+		OccurredAt: pgtype.Timestamptz{Time: entry.OccurredAt, Valid: true},
+		Before:     before,
+		After:      after,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create audit entry: %w", err)
+	}
+	return nil
+}
+
+func (q *pg) ListAudit(ctx context.Context, bookingID uuid.UUID) ([]models.AuditEntry, error) {
+	entries, err := q.queries.ListBookingAuditEntries(ctx, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list audit entries: %w", err)
+	}
+	var result []models.AuditEntry
+	for _, e := range entries {
+		before, err := unmarshalSnapshot(e.Before)
+		if err != nil {
+			return nil, err
+		}
+		after, err := unmarshalSnapshot(e.After)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, models.AuditEntry{
+			BookingID:  e.BookingID,
+			Action:     e.Action,
+			Actor:      e.Actor,
+			OccurredAt: e.OccurredAt.Time,
+			Before:     before,
+			After:      after,
+		})
+	}
+	return result, nil
+}
+
+// marshalSnapshot returns the JSON of the booking without its personal data, nil for a missing snapshot so it is
+// stored as NULL
+func marshalSnapshot(booking *models.Booking) ([]byte, error) {
+	if booking == nil {
+		return nil, nil
+	}
+	return marshalWithoutPersonalData(*booking)
+}
+
+func unmarshalSnapshot(data []byte) (*models.Booking, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var booking models.Booking
+	err := json.Unmarshal(data, &booking)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal booking: %w", err)
+	}
+	return &booking, nil
+}
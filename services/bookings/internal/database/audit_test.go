@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+func TestAudit(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close(context.Background())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	created := models.Booking{
+		ID:            uuid.New(),
+		Status:        models.BookingStatusConfirmed,
+		FirstName:     "John",
+		LastName:      "Doe",
+		Gender:        "Male",
+		Birthday:      now.AddDate(-25, 0, 0),
+		LaunchPadID:   "LP-001",
+		DestinationID: "DS-001",
+		LaunchDate:    now.AddDate(0, 1, 0),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Version:       1,
+	}
+	updated := created
+	updated.LastName = "Smith"
+	updated.UpdatedAt = now.Add(time.Minute)
+	updated.Version = 2
+	entries := []models.AuditEntry{
+		{BookingID: created.ID, Action: models.AuditActionCreated, Actor: "alice", OccurredAt: now, After: &created},
+		{BookingID: created.ID, Action: models.AuditActionUpdated, Actor: "bob", OccurredAt: now.Add(time.Minute), Before: &created, After: &updated},
+		{BookingID: created.ID, Action: models.AuditActionDeleted, Actor: "alice", OccurredAt: now.Add(time.Hour), Before: &updated},
+	}
+	for _, entry := range entries {
+		require.NoError(t, db.AppendAudit(ctx, entry))
+	}
+	require.NoError(t, db.AppendAudit(ctx, models.AuditEntry{
+		BookingID:  uuid.New(),
+		Action:     models.AuditActionCreated,
+		Actor:      "alice",
+		OccurredAt: now,
+		After:      &models.Booking{},
+	}))
+
+	history, err := db.ListAudit(ctx, created.ID)
+	require.NoError(t, err)
+	require.Len(t, history, len(entries))
+	for i, entry := range entries {
+		assert.Equal(t, entry.Action, history[i].Action)
+		assert.Equal(t, entry.Actor, history[i].Actor)
+		assert.True(t, entry.OccurredAt.Equal(history[i].OccurredAt))
+		assert.Equal(t, entry.Before == nil, history[i].Before == nil)
+		assert.Equal(t, entry.After == nil, history[i].After == nil)
+	}
+	assert.Equal(t, 2, history[1].After.Version)
+	assert.Equal(t, 2, history[2].Before.Version)
+	// The snapshots are stored without the personal data
+	var withPII int
+	require.NoError(t, db.(*pg).pool.QueryRow(ctx,
+		"SELECT count(*) FROM booking_audit WHERE booking_id = $1 AND (before ?| $2 OR after ?| $2)", created.ID, personalDataFields).
+		Scan(&withPII))
+	assert.Zero(t, withPII)
+	assert.Empty(t, history[1].After.LastName)
+
+	history, err = db.ListAudit(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	// The history cannot be rewritten
+	_, err = db.(*pg).pool.Exec(ctx, "UPDATE booking_audit SET actor = 'mallory' WHERE booking_id = $1", created.ID)
+	assert.ErrorContains(t, err, "append-only")
+	_, err = db.(*pg).pool.Exec(ctx, "DELETE FROM booking_audit WHERE booking_id = $1", created.ID)
+	assert.ErrorContains(t, err, "append-only")
+}
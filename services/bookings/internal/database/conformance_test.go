@@ -0,0 +1,660 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+func TestMemory(t *testing.T) {
+	testConformance(t, func(t *testing.T) Database {
+		return NewMemory()
+	})
+}
+
+func TestPostgres(t *testing.T) {
+	testConformance(t, setupTestDB)
+}
+
+// testConformance checks that a Database behaves like the others, newDB returns an empty one
+func testConformance(t *testing.T, newDB func(t *testing.T) Database) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, db Database)
+	}{
+		{"bookings", testBookings},
+		{"list bookings", testListBookings},
+		{"update status", testUpdateStatus},
+		{"count bookings by launch date", testCountBookingsByLaunchDate},
+		{"closures", testClosures},
+		{"blackouts", testBlackouts},
+		{"audit", testAudit},
+		{"outbox", testOutbox},
+		{"transactions", testTransactions},
+		{"retention", testRetention},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB(t)
+			defer db.Close(context.Background())
+			tt.test(t, db)
+		})
+	}
+}
+
+// conformanceNow is stored as is by every Database, Postgres keeps microseconds
+var conformanceNow = time.Now().UTC().Truncate(time.Second)
+
+func testBooking(launchPadID string, launchDate time.Time) models.Booking {
+	return models.Booking{
+		ID:            uuid.New(),
+		Status:        models.BookingStatusConfirmed,
+		FirstName:     "John",
+		LastName:      "Doe",
+		Gender:        "male",
+		Birthday:      time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		LaunchPadID:   launchPadID,
+		DestinationID: "DS-001",
+		LaunchDate:    launchDate,
+		CreatedAt:     conformanceNow,
+		UpdatedAt:     conformanceNow,
+		Version:       1,
+	}
+}
+
+// inUTC returns the booking with its times in UTC, as Postgres returns them in the local time zone
+func inUTC(booking models.Booking) models.Booking {
+	booking.Birthday = booking.Birthday.UTC()
+	booking.LaunchDate = booking.LaunchDate.UTC()
+	booking.CreatedAt = booking.CreatedAt.UTC()
+	booking.UpdatedAt = booking.UpdatedAt.UTC()
+	return booking
+}
+
+func bookingIDs(bookings []models.Booking) []uuid.UUID {
+	var result []uuid.UUID
+	for _, booking := range bookings {
+		result = append(result, booking.ID)
+	}
+	return result
+}
+
+func testBookings(t *testing.T, db Database) {
+	ctx := context.Background()
+	booking := testBooking("LP-001", conformanceNow.AddDate(0, 1, 0))
+	require.NoError(t, db.Create(ctx, booking))
+	assert.Error(t, db.Create(ctx, booking), "IDs are unique")
+
+	got, err := db.GetByID(ctx, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, booking, inUTC(*got))
+	_, err = db.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Only the details of the passenger are changed
+	updated := booking
+	updated.FirstName = "Jane"
+	updated.Gender = "female"
+	updated.LaunchPadID = "LP-002"
+	updated.UpdatedAt = conformanceNow.Add(time.Minute)
+	require.NoError(t, db.Update(ctx, updated))
+	got, err = db.GetByID(ctx, booking.ID)
+	require.NoError(t, err)
+	expected := booking
+	expected.FirstName = "Jane"
+	expected.Gender = "female"
+	expected.UpdatedAt = updated.UpdatedAt
+	expected.Version = 2
+	assert.Equal(t, expected, inUTC(*got))
+
+	assert.ErrorIs(t, db.Update(ctx, updated), ErrVersionMismatch)
+	updated.ID = uuid.New()
+	assert.ErrorIs(t, db.Update(ctx, updated), ErrNotFound)
+
+	assert.ErrorIs(t, db.Delete(ctx, booking.ID, 1), ErrVersionMismatch)
+	require.NoError(t, db.Delete(ctx, booking.ID, 2))
+	assert.ErrorIs(t, db.Delete(ctx, booking.ID, 2), ErrNotFound)
+	_, err = db.GetByID(ctx, booking.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func testListBookings(t *testing.T, db Database) {
+	ctx := context.Background()
+	launchDate := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+	var bookings []models.Booking
+	for i, name := range []string{"John", "Jane", "Mary Ann", "John", "Peter"} {
+		booking := testBooking("LP-001", launchDate)
+		booking.FirstName = name
+		booking.CreatedAt = conformanceNow.Add(time.Duration(i) * time.Minute)
+		bookings = append(bookings, booking)
+	}
+	bookings[1].LaunchPadID = "LP-002"
+	bookings[2].DestinationID = "DS-002"
+	bookings[3].LaunchDate = launchDate.AddDate(0, 0, 1)
+	bookings[4].Status = models.BookingStatusProvisional
+	for _, booking := range bookings {
+		require.NoError(t, db.Create(ctx, booking))
+	}
+	str := func(s string) *string { return &s }
+	otherDate := launchDate.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name        string
+		pagination  models.Pagination
+		filters     models.Filters
+		expectedIDs []uuid.UUID
+	}{
+		{
+			name:        "newest first",
+			pagination:  models.Pagination{Limit: 10},
+			expectedIDs: bookingIDs([]models.Booking{bookings[4], bookings[3], bookings[2], bookings[1], bookings[0]}),
+		},
+		{
+			name:        "page",
+			pagination:  models.Pagination{Offset: 1, Limit: 2},
+			expectedIDs: bookingIDs([]models.Booking{bookings[3], bookings[2]}),
+		},
+		{
+			name:       "past the last page",
+			pagination: models.Pagination{Offset: 5, Limit: 2},
+		},
+		{
+			name:        "launch date",
+			pagination:  models.Pagination{Limit: 10},
+			filters:     models.Filters{LaunchDate: &otherDate},
+			expectedIDs: bookingIDs([]models.Booking{bookings[3]}),
+		},
+		{
+			name:        "launch pad",
+			pagination:  models.Pagination{Limit: 10},
+			filters:     models.Filters{LaunchPadID: str("LP-002")},
+			expectedIDs: bookingIDs([]models.Booking{bookings[1]}),
+		},
+		{
+			name:        "destination",
+			pagination:  models.Pagination{Limit: 10},
+			filters:     models.Filters{DestinationID: str("DS-002")},
+			expectedIDs: bookingIDs([]models.Booking{bookings[2]}),
+		},
+		{
+			name:        "status",
+			pagination:  models.Pagination{Limit: 10},
+			filters:     models.Filters{Status: str(models.BookingStatusProvisional)},
+			expectedIDs: bookingIDs([]models.Booking{bookings[4]}),
+		},
+		{
+			name:        "first name ignoring case and extra spaces",
+			pagination:  models.Pagination{Limit: 10},
+			filters:     models.Filters{FirstName: str("  mary   ANN ")},
+			expectedIDs: bookingIDs([]models.Booking{bookings[2]}),
+		},
+		{
+			name:        "names and launch pad",
+			pagination:  models.Pagination{Limit: 10},
+			filters:     models.Filters{FirstName: str("john"), LastName: str("doe"), LaunchPadID: str("LP-001")},
+			expectedIDs: bookingIDs([]models.Booking{bookings[3], bookings[0]}),
+		},
+		{
+			name:       "partial name",
+			pagination: models.Pagination{Limit: 10},
+			filters:    models.Filters{FirstName: str("Jo")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.List(ctx, tt.pagination, tt.filters)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIDs, bookingIDs(got))
+		})
+	}
+
+	// Bookings created at the same time are in the order of their IDs, so pages neither overlap nor skip any
+	launchPadID := uuid.NewString()
+	var tied []models.Booking
+	for range 3 {
+		booking := testBooking(launchPadID, launchDate)
+		booking.CreatedAt = conformanceNow
+		require.NoError(t, db.Create(ctx, booking))
+		tied = append(tied, booking)
+	}
+	slices.SortFunc(tied, func(a, b models.Booking) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+	for offset := range tied {
+		got, err := db.List(ctx, models.Pagination{Offset: offset, Limit: 1}, models.Filters{LaunchPadID: &launchPadID})
+		require.NoError(t, err)
+		assert.Equal(t, bookingIDs(tied[offset:offset+1]), bookingIDs(got))
+	}
+}
+
+func testUpdateStatus(t *testing.T, db Database) {
+	ctx := context.Background()
+	booking := testBooking("LP-001", conformanceNow.AddDate(0, 1, 0))
+	booking.Status = models.BookingStatusProvisional
+	require.NoError(t, db.Create(ctx, booking))
+
+	require.NoError(t, db.UpdateStatus(ctx, booking.ID, models.BookingStatusConfirmed, conformanceNow.Add(time.Minute)))
+	got, err := db.GetByID(ctx, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.BookingStatusConfirmed, got.Status)
+	assert.Equal(t, 2, got.Version)
+	assert.True(t, conformanceNow.Add(time.Minute).Equal(got.UpdatedAt))
+
+	assert.ErrorIs(t, db.UpdateStatus(ctx, uuid.New(), models.BookingStatusConfirmed, conformanceNow), ErrNotFound)
+}
+
+func testCountBookingsByLaunchDate(t *testing.T, db Database) {
+	ctx := context.Background()
+	day := time.Date(2049, 7, 1, 0, 0, 0, 0, time.UTC)
+	for _, b := range []struct {
+		launchPadID   string
+		destinationID string
+		launchDate    time.Time
+		status        string
+	}{
+		{"LP-001", "DS-002", day, models.BookingStatusProvisional},
+		{"LP-001", "DS-001", day, models.BookingStatusConfirmed},
+		{"LP-001", "DS-001", day, models.BookingStatusConfirmed},
+		{"LP-001", "DS-001", day, models.BookingStatusRejected},
+		{"LP-001", "DS-001", day.AddDate(0, 0, 1), models.BookingStatusConfirmed},
+		{"LP-001", "DS-001", day.AddDate(0, 0, 2), models.BookingStatusConfirmed},
+		{"LP-002", "DS-001", day, models.BookingStatusConfirmed},
+	} {
+		booking := testBooking(b.launchPadID, b.launchDate)
+		booking.DestinationID = b.destinationID
+		booking.Status = b.status
+		require.NoError(t, db.Create(ctx, booking))
+	}
+
+	counts, err := db.CountBookingsByLaunchDate(ctx, "LP-001", day, day.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	for i := range counts {
+		counts[i].LaunchDate = counts[i].LaunchDate.UTC()
+	}
+	assert.Equal(t, []models.LaunchDateBookings{
+		{LaunchDate: day, DestinationID: "DS-001", Bookings: 2},
+		{LaunchDate: day, DestinationID: "DS-002", Bookings: 1},
+		{LaunchDate: day.AddDate(0, 0, 1), DestinationID: "DS-001", Bookings: 1},
+	}, counts)
+}
+
+func testClosures(t *testing.T, db Database) {
+	ctx := context.Background()
+	day := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+	closure := func(launchPadID string, startsAt time.Time, endsAt time.Time) models.Closure {
+		return models.Closure{
+			ID:          uuid.New(),
+			LaunchPadID: launchPadID,
+			StartsAt:    startsAt,
+			EndsAt:      endsAt,
+			Summary:     "Range maintenance",
+			CreatedAt:   day,
+		}
+	}
+	late := closure("LP-001", day.Add(20*time.Hour), day.Add(30*time.Hour))
+	early := closure("LP-001", day.Add(-2*time.Hour), day.Add(time.Hour))
+	require.NoError(t, db.ReplaceClosures(ctx, "partner.ics", []models.Closure{
+		late,
+		early,
+		closure("LP-001", day.Add(-2*time.Hour), day),
+		closure("LP-001", day.Add(24*time.Hour), day.Add(48*time.Hour)),
+		closure("LP-002", day, day.Add(24*time.Hour)),
+	}))
+	other := closure("LP-001", day.Add(5*time.Hour), day.Add(6*time.Hour))
+	require.NoError(t, db.ReplaceClosures(ctx, "partner.csv", []models.Closure{other}))
+
+	closures, err := db.ListClosures(ctx, "LP-001", day, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, closures, 3)
+	assert.Equal(t, []uuid.UUID{early.ID, other.ID, late.ID},
+		[]uuid.UUID{closures[0].ID, closures[1].ID, closures[2].ID}, "closures overlapping [from, to) by start")
+	assert.Equal(t, "partner.ics", closures[0].Source)
+	assert.Equal(t, "partner.csv", closures[1].Source)
+	assert.True(t, early.EndsAt.Equal(closures[0].EndsAt))
+
+	// Importing a source again only replaces its own closures
+	require.NoError(t, db.ReplaceClosures(ctx, "partner.ics", nil))
+	closures, err = db.ListClosures(ctx, "LP-001", day, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, closures, 1)
+	assert.Equal(t, other.ID, closures[0].ID)
+}
+
+func testBlackouts(t *testing.T, db Database) {
+	ctx := context.Background()
+	day := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+	blackout := func(launchPadID string, startsAt time.Time, endsAt time.Time) models.Blackout {
+		return models.Blackout{
+			ID:          uuid.New(),
+			LaunchPadID: launchPadID,
+			StartsAt:    startsAt,
+			EndsAt:      endsAt,
+			Reason:      "Pad refurbishment",
+			CreatedAt:   day,
+			UpdatedAt:   day,
+		}
+	}
+	allPads := blackout("", day.Add(24*time.Hour), day.Add(48*time.Hour))
+	pad := blackout("LP-001", day, day.Add(48*time.Hour))
+	otherPad := blackout("LP-002", day.Add(-24*time.Hour), day.Add(24*time.Hour))
+	for _, b := range []models.Blackout{allPads, pad, otherPad} {
+		require.NoError(t, db.CreateBlackout(ctx, b))
+	}
+	assert.Error(t, db.CreateBlackout(ctx, pad), "IDs are unique")
+
+	got, err := db.GetBlackout(ctx, allPads.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "", got.LaunchPadID)
+	assert.Equal(t, allPads.Reason, got.Reason)
+
+	ids := func(blackouts []models.Blackout) []uuid.UUID {
+		var result []uuid.UUID
+		for _, b := range blackouts {
+			result = append(result, b.ID)
+		}
+		return result
+	}
+	launchPadID := "LP-001"
+	from, to := day.Add(24*time.Hour), day.Add(48*time.Hour)
+	blackouts, err := db.ListBlackouts(ctx, models.BlackoutFilters{})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{otherPad.ID, pad.ID, allPads.ID}, ids(blackouts), "by start")
+	blackouts, err = db.ListBlackouts(ctx, models.BlackoutFilters{LaunchPadID: &launchPadID, From: &from, To: &to})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{pad.ID, allPads.ID}, ids(blackouts), "with the blackouts of every launch pad")
+	blackouts, err = db.ListBlackouts(ctx, models.BlackoutFilters{To: &day})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{otherPad.ID}, ids(blackouts))
+
+	pad.EndsAt = day.Add(time.Hour)
+	pad.Reason = "Shorter refurbishment"
+	require.NoError(t, db.UpdateBlackout(ctx, pad))
+	blackouts, err = db.ListBlackouts(ctx, models.BlackoutFilters{LaunchPadID: &launchPadID, From: &from, To: &to})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{allPads.ID}, ids(blackouts))
+
+	require.NoError(t, db.DeleteBlackout(ctx, pad.ID))
+	_, err = db.GetBlackout(ctx, pad.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, db.UpdateBlackout(ctx, pad), ErrNotFound)
+	assert.ErrorIs(t, db.DeleteBlackout(ctx, pad.ID), ErrNotFound)
+}
+
+func testAudit(t *testing.T, db Database) {
+	ctx := context.Background()
+	booking := testBooking("LP-001", conformanceNow.AddDate(0, 1, 0))
+	updated := booking
+	updated.FirstName = "Jane"
+	updated.Version = 2
+	entries := []models.AuditEntry{
+		{BookingID: booking.ID, Action: models.AuditActionCreated, Actor: "alice", OccurredAt: conformanceNow, After: &booking},
+		{BookingID: booking.ID, Action: models.AuditActionUpdated, Actor: "bob", OccurredAt: conformanceNow, Before: &booking, After: &updated},
+		{BookingID: booking.ID, Action: models.AuditActionDeleted, Actor: "bob", OccurredAt: conformanceNow, Before: &updated},
+	}
+	for _, entry := range entries {
+		require.NoError(t, db.AppendAudit(ctx, entry))
+	}
+	require.NoError(t, db.AppendAudit(ctx, models.AuditEntry{BookingID: uuid.New(), Action: models.AuditActionCreated, Actor: "alice", OccurredAt: conformanceNow}))
+	// Changing the snapshots afterwards does not change the history
+	booking.DestinationID = "changed"
+
+	history, err := db.ListAudit(ctx, entries[0].BookingID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	for i, entry := range history {
+		assert.Equal(t, entries[i].Action, entry.Action)
+		assert.Equal(t, entries[i].Actor, entry.Actor)
+		assert.True(t, conformanceNow.Equal(entry.OccurredAt))
+	}
+	assert.Nil(t, history[0].Before)
+	assert.Equal(t, "DS-001", history[0].After.DestinationID)
+	assert.Equal(t, 2, history[1].After.Version)
+	assert.Nil(t, history[2].After)
+	// The snapshots are stored without the personal data
+	for _, entry := range history {
+		for _, snapshot := range []*models.Booking{entry.Before, entry.After} {
+			if snapshot != nil {
+				assert.Equal(t, booking.ID, snapshot.ID)
+				assert.Empty(t, snapshot.FirstName)
+				assert.True(t, snapshot.Birthday.IsZero())
+			}
+		}
+	}
+
+	history, err = db.ListAudit(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func testOutbox(t *testing.T, db Database) {
+	ctx := context.Background()
+	booking := testBooking("LP-001", conformanceNow.AddDate(0, 1, 0))
+	other := testBooking("LP-001", conformanceNow.AddDate(0, 1, 0))
+	require.NoError(t, db.Create(ctx, booking))
+	require.NoError(t, db.Create(ctx, other))
+	booking.FirstName = "Jane"
+	require.NoError(t, db.Update(ctx, booking))
+	require.NoError(t, db.Delete(ctx, other.ID, 1))
+
+	claim := func(limit int, lease time.Duration) []models.OutboxEvent {
+		events, err := db.ClaimOutboxEvents(ctx, limit, lease)
+		require.NoError(t, err)
+		return events
+	}
+	// Only the oldest pending event of every booking
+	events := claim(10, time.Hour)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.EventBookingCreated, events[0].Type)
+	assert.Equal(t, booking.ID, events[0].BookingID)
+	var data map[string]any
+	require.NoError(t, json.Unmarshal(events[0].Data, &data))
+	assert.Equal(t, booking.ID.String(), data["id"])
+	assert.NotContains(t, data, "first_name", "events are recorded without the personal data")
+	assert.Equal(t, other.ID, events[1].BookingID)
+	assert.Empty(t, claim(10, time.Hour), "leased events are not claimed again")
+
+	require.NoError(t, db.MarkOutboxEventPublished(ctx, events[0].ID))
+	require.NoError(t, db.MarkOutboxEventFailed(ctx, events[1].ID, "connection refused", time.Hour, false))
+	events = claim(10, time.Hour)
+	require.Len(t, events, 1, "the events of other wait for the failed one")
+	assert.Equal(t, models.EventBookingUpdated, events[0].Type)
+	require.NoError(t, json.Unmarshal(events[0].Data, &data))
+	assert.NotContains(t, data, "first_name")
+	assert.EqualValues(t, 2, data["version"])
+
+	require.NoError(t, db.MarkOutboxEventFailed(ctx, events[0].ID, "connection refused", 0, false))
+	events = claim(10, 0)
+	require.Len(t, events, 1)
+	assert.Equal(t, 1, events[0].Attempts)
+	// The lease expired without the event being marked
+	events = claim(10, time.Hour)
+	require.Len(t, events, 1, "events of an expired lease are claimed again")
+	assert.Equal(t, 1, events[0].Attempts)
+	require.NoError(t, db.MarkOutboxEventFailed(ctx, events[0].ID, "connection refused", 0, true))
+	assert.Empty(t, claim(10, time.Hour), "dead events are not published again")
+}
+
+func testTransactions(t *testing.T, db Database) {
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+	assert.ErrorIs(t, db.LockLaunchSlot(ctx, "LP-001", conformanceNow, time.Second), ErrNoTransaction)
+
+	committed := testBooking("LP-001", conformanceNow.AddDate(0, 1, 0))
+	rolledBack := testBooking("LP-001", conformanceNow.AddDate(0, 1, 0))
+	nested := testBooking("LP-001", conformanceNow.AddDate(0, 1, 0))
+	err := db.WithTx(ctx, func(tx Database) error {
+		require.NoError(t, tx.LockLaunchSlot(ctx, "LP-001", conformanceNow, time.Second))
+		require.NoError(t, tx.Create(ctx, committed))
+		err := tx.WithTx(ctx, func(tx Database) error {
+			require.NoError(t, tx.Create(ctx, rolledBack))
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+		_, err = tx.GetByID(ctx, rolledBack.ID)
+		assert.ErrorIs(t, err, ErrNotFound, "the nested transaction is rolled back alone")
+		return tx.WithTx(ctx, func(tx Database) error {
+			return tx.Create(ctx, nested)
+		})
+	})
+	require.NoError(t, err)
+	for _, id := range []uuid.UUID{committed.ID, nested.ID} {
+		_, err = db.GetByID(ctx, id)
+		assert.NoError(t, err)
+	}
+
+	err = db.WithTx(ctx, func(tx Database) error {
+		require.NoError(t, tx.Create(ctx, rolledBack))
+		require.NoError(t, tx.Delete(ctx, committed.ID, 1))
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+	_, err = db.GetByID(ctx, rolledBack.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = db.GetByID(ctx, committed.ID)
+	assert.NoError(t, err)
+}
+
+func testRetention(t *testing.T, db Database) {
+	ctx := context.Background()
+	newBooking := func(launchDate time.Time) models.Booking {
+		booking := testBooking("LP-001", launchDate)
+		require.NoError(t, db.Create(ctx, booking))
+		require.NoError(t, db.AppendAudit(ctx, models.AuditEntry{
+			BookingID:  booking.ID,
+			Action:     models.AuditActionCreated,
+			Actor:      "alice",
+			OccurredAt: conformanceNow,
+			After:      &booking,
+		}))
+		return booking
+	}
+	old := newBooking(conformanceNow.AddDate(-2, 0, 0))
+	deleted := newBooking(conformanceNow.AddDate(-1, 0, 0))
+	require.NoError(t, db.Delete(ctx, deleted.ID, deleted.Version))
+	require.NoError(t, db.AppendAudit(ctx, models.AuditEntry{
+		BookingID:  deleted.ID,
+		Action:     models.AuditActionDeleted,
+		Actor:      "alice",
+		OccurredAt: conformanceNow,
+		Before:     &deleted,
+	}))
+	recent := newBooking(conformanceNow.AddDate(0, -1, 0))
+
+	anonymizeBefore := conformanceNow.AddDate(0, -6, 0)
+	// The history of the deleted booking was stored without the personal data, it has nothing to erase
+	toAnonymize, err := db.ListBookingsToAnonymize(ctx, anonymizeBefore, models.Pagination{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, toAnonymize, 1)
+	assert.Equal(t, models.ExpiredBooking{ID: old.ID, LaunchDate: old.LaunchDate}, inUTCExpired(toAnonymize[0]))
+	page, err := db.ListBookingsToAnonymize(ctx, anonymizeBefore, models.Pagination{Offset: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page)
+
+	anonymized, err := db.AnonymizeBooking(ctx, old.ID, conformanceNow)
+	require.NoError(t, err)
+	require.NotNil(t, anonymized)
+	got, err := db.GetByID(ctx, old.ID)
+	require.NoError(t, err)
+	assert.Equal(t, inUTC(*anonymized), inUTC(*got))
+	assert.Empty(t, got.FirstName)
+	assert.True(t, got.Birthday.IsZero())
+	assert.Equal(t, old.LaunchPadID, got.LaunchPadID)
+	assert.Equal(t, 2, got.Version)
+	again, err := db.AnonymizeBooking(ctx, old.ID, conformanceNow)
+	require.NoError(t, err)
+	assert.Nil(t, again, "already anonymized")
+	gone, err := db.AnonymizeBooking(ctx, deleted.ID, conformanceNow)
+	require.NoError(t, err)
+	assert.Nil(t, gone)
+	toAnonymize, err = db.ListBookingsToAnonymize(ctx, anonymizeBefore, models.Pagination{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, toAnonymize)
+
+	// Anonymized bookings are not found by name
+	firstName := "John"
+	bookings, err := db.List(ctx, models.Pagination{Limit: 10}, models.Filters{FirstName: &firstName})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{recent.ID}, bookingIDs(bookings))
+
+	for _, id := range []uuid.UUID{old.ID, deleted.ID} {
+		history, err := db.ListAudit(ctx, id)
+		require.NoError(t, err)
+		require.NotEmpty(t, history)
+		for _, entry := range history {
+			for _, snapshot := range []*models.Booking{entry.Before, entry.After} {
+				if snapshot != nil {
+					assert.Empty(t, snapshot.FirstName)
+					assert.Equal(t, id, snapshot.ID)
+				}
+			}
+		}
+	}
+
+	toPurge, err := db.ListBookingsToPurge(ctx, conformanceNow.AddDate(0, -2, 0), models.Pagination{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, toPurge, 1)
+	assert.Equal(t, old.ID, toPurge[0].ID)
+	require.NoError(t, db.PurgeBooking(ctx, old.ID))
+	_, err = db.GetByID(ctx, old.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, db.PurgeBooking(ctx, old.ID), ErrNotFound)
+
+	// The events of the purged booking are left without personal data
+	var types []string
+	for _, event := range publishEvents(t, db, old.ID) {
+		types = append(types, event.Type)
+		var data map[string]any
+		require.NoError(t, json.Unmarshal(event.Data, &data))
+		assert.Empty(t, data["first_name"])
+		assert.Equal(t, old.ID.String(), data["id"])
+	}
+	assert.Equal(t, []string{models.EventBookingCreated, models.EventBookingAnonymized, models.EventBookingPurged}, types)
+
+	// Editing an anonymized booking makes it due for anonymization again
+	anonymized, err = db.AnonymizeBooking(ctx, recent.ID, conformanceNow)
+	require.NoError(t, err)
+	recent.FirstName = "Jane"
+	recent.Version = anonymized.Version
+	require.NoError(t, db.Update(ctx, recent))
+	toAnonymize, err = db.ListBookingsToAnonymize(ctx, conformanceNow, models.Pagination{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, toAnonymize, 1)
+	assert.Equal(t, recent.ID, toAnonymize[0].ID)
+}
+
+func inUTCExpired(booking models.ExpiredBooking) models.ExpiredBooking {
+	booking.LaunchDate = booking.LaunchDate.UTC()
+	return booking
+}
+
+// publishEvents publishes the events of the booking, in order
+func publishEvents(t *testing.T, db Database, bookingID uuid.UUID) []models.OutboxEvent {
+	ctx := context.Background()
+	var result []models.OutboxEvent
+	for {
+		var published []models.OutboxEvent
+		// Without a lease the events of the other bookings are left to be claimed
+		claimed, err := db.ClaimOutboxEvents(ctx, 100, 0)
+		require.NoError(t, err)
+		for _, event := range claimed {
+			if event.BookingID == bookingID {
+				published = append(published, event)
+				require.NoError(t, db.MarkOutboxEventPublished(ctx, event.ID))
+			}
+		}
+		if len(published) == 0 {
+			return result
+		}
+		result = append(result, published...)
+	}
+}
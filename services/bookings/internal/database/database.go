@@ -3,6 +3,8 @@ package database
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -11,12 +13,98 @@ import (
 
 var ErrNotFound = errors.New("error not found")
 
+// ErrVersionMismatch is returned when a booking changed since the version the caller read
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// ErrLockTimeout is returned when a lock could not be acquired in time
+var ErrLockTimeout = errors.New("lock timeout")
+
+// ErrNoTransaction is returned by the methods that only make sense in WithTx
+var ErrNoTransaction = errors.New("not in a transaction")
+
+// Storage selects the Database implementation
+type Storage string
+
+const (
+	StoragePostgres Storage = "postgres"
+	// StorageMemory keeps everything in memory until the process exits, see NewMemory
+	StorageMemory Storage = "memory"
+)
+
+func ParseStorage(value string) (Storage, error) {
+	switch Storage(value) {
+	case StoragePostgres, StorageMemory:
+		return Storage(value), nil
+	default:
+		return "", fmt.Errorf("unknown storage %q", value)
+	}
+}
+
 //go:generate mockgen -package=mocks -destination=../mocks/database.go github.com/zsoltggs/tabeo-interview/services/bookings/internal/database Database
 type Database interface {
+	// Create creates the booking and records a models.EventBookingCreated in the outbox
 	Create(ctx context.Context, booking models.Booking) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Update changes the details of the passenger of the booking if it is still at booking.Version, the version
+	// is incremented. It records a models.EventBookingUpdated in the outbox and returns ErrVersionMismatch
+	// if the booking changed in the meantime.
+	Update(ctx context.Context, booking models.Booking) error
+	// Delete deletes the booking if it is still at version and records a models.EventBookingDeleted in the outbox,
+	// it returns ErrVersionMismatch if the booking changed in the meantime
+	Delete(ctx context.Context, id uuid.UUID, version int) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Booking, error)
 	List(ctx context.Context, pagination models.Pagination, filters models.Filters) ([]models.Booking, error)
+	// UpdateStatus sets the status of the booking whatever its version, the version is incremented
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string, updatedAt time.Time) error
+	// CountBookingsByLaunchDate counts the bookings of the launch pad that were not rejected,
+	// per launch date in [from, to) and destination
+	CountBookingsByLaunchDate(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]models.LaunchDateBookings, error)
+	// ReplaceClosures replaces every closure of the source with closures in a single transaction
+	ReplaceClosures(ctx context.Context, source string, closures []models.Closure) error
+	// ListClosures returns the closures of the launch pad overlapping [from, to)
+	ListClosures(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]models.Closure, error)
+	// AppendAudit appends the entry to the history of its booking, the history cannot be changed afterwards
+	AppendAudit(ctx context.Context, entry models.AuditEntry) error
+	// ListAudit returns the history of the booking, oldest first
+	ListAudit(ctx context.Context, bookingID uuid.UUID) ([]models.AuditEntry, error)
+	CreateBlackout(ctx context.Context, blackout models.Blackout) error
+	GetBlackout(ctx context.Context, id uuid.UUID) (*models.Blackout, error)
+	ListBlackouts(ctx context.Context, filters models.BlackoutFilters) ([]models.Blackout, error)
+	UpdateBlackout(ctx context.Context, blackout models.Blackout) error
+	DeleteBlackout(ctx context.Context, id uuid.UUID) error
+	// ClaimOutboxEvents leases up to limit pending events due for publishing to the caller for lease, oldest first.
+	// Events leased by another relay and events of a booking with an older pending event are skipped, so the events
+	// of a booking are published in order. The lease is taken in a transaction of its own and ends when the event
+	// is marked, or when it expires, after which the event can be claimed again.
+	ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	MarkOutboxEventPublished(ctx context.Context, id uuid.UUID) error
+	// MarkOutboxEventFailed records a failed attempt to publish the event, which is published again after retryIn,
+	// or never again if dead is set
+	MarkOutboxEventFailed(ctx context.Context, id uuid.UUID, lastError string, retryIn time.Duration, dead bool) error
+	// ReencryptBookings encrypts the personal data of up to limit bookings encrypted with another key than
+	// the active one, or not encrypted yet, with the active key. It returns the number of bookings reencrypted,
+	// anonymized bookings and bookings being reencrypted by another transaction are skipped.
+	ReencryptBookings(ctx context.Context, limit int) (int, error)
+	// ListBookingsToAnonymize returns the bookings launched before launchedBefore that were not anonymized,
+	// oldest launch first, including the bookings deleted before whose history still has personal data
+	ListBookingsToAnonymize(ctx context.Context, launchedBefore time.Time, pagination models.Pagination) ([]models.ExpiredBooking, error)
+	// ListBookingsToPurge returns the bookings launched before launchedBefore, oldest launch first
+	ListBookingsToPurge(ctx context.Context, launchedBefore time.Time, pagination models.Pagination) ([]models.ExpiredBooking, error)
+	// AnonymizeBooking erases the personal data of the booking, including the encrypted one and its data key,
+	// and of its history and events, the version is incremented. It records a models.EventBookingAnonymized
+	// in the outbox and returns the anonymized booking, or nil if there was no booking left to anonymize
+	// but only its history, e.g. it was deleted before.
+	AnonymizeBooking(ctx context.Context, id uuid.UUID, at time.Time) (*models.Booking, error)
+	// PurgeBooking deletes the booking whatever its version and erases the personal data of its history and events,
+	// which are kept. It records a models.EventBookingPurged in the outbox.
+	PurgeBooking(ctx context.Context, id uuid.UUID) error
+	// LockLaunchSlot serializes the transactions booking the launch pad on the day of launchDate,
+	// the lock is held until the transaction ends. It returns ErrLockTimeout if the lock is not acquired
+	// within timeout (zero waits forever) and ErrNoTransaction outside of WithTx.
+	LockLaunchSlot(ctx context.Context, launchPadID string, launchDate time.Time, timeout time.Duration) error
+	// WithTx runs fn in a transaction, committed if fn returns nil and rolled back otherwise.
+	// The transaction is run again on serialization failures and deadlocks, so fn must not have other side effects.
+	// Calling WithTx on the Database of fn runs a nested transaction in a savepoint.
+	WithTx(ctx context.Context, fn func(tx Database) error, opts ...TxOption) error
 	Health() error
 	Close(ctx context.Context)
 }
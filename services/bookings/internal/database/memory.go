@@ -0,0 +1,657 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+type memoryBooking struct {
+	booking models.Booking
+	// anonymizedAt is set once the retention policy erased the personal data, a later edit clears it
+	anonymizedAt *time.Time
+}
+
+type memoryEvent struct {
+	event       models.OutboxEvent
+	seq         int64
+	status      string
+	availableAt time.Time
+	// lockedUntil is the end of the lease of the relay publishing the event
+	lockedUntil time.Time
+}
+
+// memoryState is the content of the database. Snapshots and event payloads are replaced rather than changed,
+// so a clone only copies the maps and slices.
+type memoryState struct {
+	bookings  map[uuid.UUID]memoryBooking
+	closures  []models.Closure
+	blackouts map[uuid.UUID]models.Blackout
+	// events and audit are in the order they were recorded
+	events  []memoryEvent
+	audit   []models.AuditEntry
+	lastSeq int64
+}
+
+func (s *memoryState) clone() *memoryState {
+	result := &memoryState{
+		bookings:  make(map[uuid.UUID]memoryBooking, len(s.bookings)),
+		closures:  append([]models.Closure(nil), s.closures...),
+		blackouts: make(map[uuid.UUID]models.Blackout, len(s.blackouts)),
+		events:    append([]memoryEvent(nil), s.events...),
+		audit:     append([]models.AuditEntry(nil), s.audit...),
+		lastSeq:   s.lastSeq,
+	}
+	for id, booking := range s.bookings {
+		result.bookings[id] = booking
+	}
+	for id, blackout := range s.blackouts {
+		result.blackouts[id] = blackout
+	}
+	return result
+}
+
+type memoryStore struct {
+	// txMu serializes the writes, a transaction holds it until it ends
+	txMu sync.Mutex
+	// mu guards state, which is replaced when a transaction commits
+	mu    sync.Mutex
+	state *memoryState
+}
+
+type memory struct {
+	store *memoryStore
+	// tx is the state of the transaction of WithTx, nil outside of it
+	tx *memoryState
+}
+
+// NewMemory returns a Database keeping everything in memory, for development and tests. Transactions are
+// serializable: they run one at a time on a copy of the state, committed at their end, while reads outside of them
+// see the committed state. The Database of a transaction must be used for its writes, writing with another one blocks.
+// The personal data of the bookings is not encrypted.
+func NewMemory() Database {
+	return &memory{
+		store: &memoryStore{
+			state: &memoryState{
+				bookings:  map[uuid.UUID]memoryBooking{},
+				blackouts: map[uuid.UUID]models.Blackout{},
+			},
+		},
+	}
+}
+
+// read runs fn with the state of the transaction, or the committed state
+func (m *memory) read(fn func(state *memoryState) error) error {
+	if m.tx != nil {
+		return fn(m.tx)
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	return fn(m.store.state)
+}
+
+// write runs fn with the state of the transaction, or in a transaction of its own.
+// fn must check everything before changing the state, so a failed write changes nothing.
+func (m *memory) write(fn func(state *memoryState) error) error {
+	if m.tx != nil {
+		return fn(m.tx)
+	}
+	m.store.txMu.Lock()
+	defer m.store.txMu.Unlock()
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	return fn(m.store.state)
+}
+
+func (m *memory) WithTx(ctx context.Context, fn func(tx Database) error, _ ...TxOption) error {
+	if m.tx != nil {
+		// Nested transactions run on a copy of the outer one, kept if fn succeeds like a savepoint
+		nested := m.tx.clone()
+		err := fn(&memory{store: m.store, tx: nested})
+		if err != nil {
+			return err
+		}
+		*m.tx = *nested
+		return nil
+	}
+	// Transactions cannot conflict as they run one at a time, there is nothing to retry
+	m.store.txMu.Lock()
+	defer m.store.txMu.Unlock()
+	m.store.mu.Lock()
+	state := m.store.state.clone()
+	m.store.mu.Unlock()
+	err := fn(&memory{store: m.store, tx: state})
+	if err != nil {
+		return err
+	}
+	m.store.mu.Lock()
+	m.store.state = state
+	m.store.mu.Unlock()
+	return nil
+}
+
+func (m *memory) Create(_ context.Context, booking models.Booking) error {
+	return m.write(func(state *memoryState) error {
+		if _, ok := state.bookings[booking.ID]; ok {
+			return fmt.Errorf("error creating booking: booking %s already exists", booking.ID)
+		}
+		err := state.recordEvent(models.EventBookingCreated, booking)
+		if err != nil {
+			return err
+		}
+		state.bookings[booking.ID] = memoryBooking{booking: booking}
+		return nil
+	})
+}
+
+func (m *memory) Update(_ context.Context, booking models.Booking) error {
+	return m.write(func(state *memoryState) error {
+		stored, ok := state.bookings[booking.ID]
+		switch {
+		case !ok:
+			return ErrNotFound
+		case stored.booking.Version != booking.Version:
+			return ErrVersionMismatch
+		}
+		updated := stored.booking
+		updated.FirstName = booking.FirstName
+		updated.LastName = booking.LastName
+		updated.Gender = booking.Gender
+		updated.Birthday = booking.Birthday
+		updated.UpdatedAt = booking.UpdatedAt
+		updated.Version++
+		err := state.recordEvent(models.EventBookingUpdated, updated)
+		if err != nil {
+			return err
+		}
+		state.bookings[booking.ID] = memoryBooking{booking: updated}
+		return nil
+	})
+}
+
+func (m *memory) Delete(_ context.Context, id uuid.UUID, version int) error {
+	return m.write(func(state *memoryState) error {
+		stored, ok := state.bookings[id]
+		switch {
+		case !ok:
+			return ErrNotFound
+		case stored.booking.Version != version:
+			return ErrVersionMismatch
+		}
+		err := state.recordEvent(models.EventBookingDeleted, stored.booking)
+		if err != nil {
+			return err
+		}
+		delete(state.bookings, id)
+		return nil
+	})
+}
+
+func (m *memory) GetByID(_ context.Context, id uuid.UUID) (*models.Booking, error) {
+	var result *models.Booking
+	err := m.read(func(state *memoryState) error {
+		stored, ok := state.bookings[id]
+		if !ok {
+			return ErrNotFound
+		}
+		booking := stored.booking
+		result = &booking
+		return nil
+	})
+	return result, err
+}
+
+func (m *memory) List(_ context.Context, pagination models.Pagination, filters models.Filters) ([]models.Booking, error) {
+	var matching []models.Booking
+	err := m.read(func(state *memoryState) error {
+		for _, stored := range state.bookings {
+			if matchesFilters(stored, filters) {
+				matching = append(matching, stored.booking)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Newest first, then by ID like the ORDER BY of Postgres, so both page the same way
+	sort.Slice(matching, func(i, j int) bool {
+		if !matching[i].CreatedAt.Equal(matching[j].CreatedAt) {
+			return matching[i].CreatedAt.After(matching[j].CreatedAt)
+		}
+		return bytes.Compare(matching[i].ID[:], matching[j].ID[:]) < 0
+	})
+	return paginate(matching, pagination), nil
+}
+
+// matchesFilters matches the names like their blind indexes do, the anonymized bookings have none
+func matchesFilters(stored memoryBooking, filters models.Filters) bool {
+	booking := stored.booking
+	switch {
+	case filters.LaunchDate != nil && !booking.LaunchDate.Equal(*filters.LaunchDate),
+		filters.LaunchPadID != nil && booking.LaunchPadID != *filters.LaunchPadID,
+		filters.DestinationID != nil && booking.DestinationID != *filters.DestinationID,
+		filters.Status != nil && booking.Status != *filters.Status:
+		return false
+	case filters.FirstName != nil && (stored.anonymizedAt != nil || normalizeName(booking.FirstName) != normalizeName(*filters.FirstName)),
+		filters.LastName != nil && (stored.anonymizedAt != nil || normalizeName(booking.LastName) != normalizeName(*filters.LastName)):
+		return false
+	default:
+		return true
+	}
+}
+
+// normalizeName ignores case and extra spaces
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func paginate[T any](items []T, pagination models.Pagination) []T {
+	if pagination.Offset >= len(items) {
+		return nil
+	}
+	items = items[pagination.Offset:]
+	if pagination.Limit < len(items) {
+		items = items[:pagination.Limit]
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
+
+func (m *memory) UpdateStatus(_ context.Context, id uuid.UUID, status string, updatedAt time.Time) error {
+	return m.write(func(state *memoryState) error {
+		stored, ok := state.bookings[id]
+		if !ok {
+			return ErrNotFound
+		}
+		stored.booking.Status = status
+		stored.booking.UpdatedAt = updatedAt
+		stored.booking.Version++
+		state.bookings[id] = stored
+		return nil
+	})
+}
+
+func (m *memory) CountBookingsByLaunchDate(_ context.Context, launchPadID string, from time.Time, to time.Time) ([]models.LaunchDateBookings, error) {
+	type key struct {
+		launchDate    int64
+		destinationID string
+	}
+	counts := map[key]*models.LaunchDateBookings{}
+	err := m.read(func(state *memoryState) error {
+		for _, stored := range state.bookings {
+			booking := stored.booking
+			if booking.LaunchPadID != launchPadID || booking.LaunchDate.Before(from) || !booking.LaunchDate.Before(to) ||
+				booking.Status == models.BookingStatusRejected {
+				continue
+			}
+			k := key{launchDate: booking.LaunchDate.UnixNano(), destinationID: booking.DestinationID}
+			if counts[k] == nil {
+				counts[k] = &models.LaunchDateBookings{LaunchDate: booking.LaunchDate, DestinationID: booking.DestinationID}
+			}
+			counts[k].Bookings++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result []models.LaunchDateBookings
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].LaunchDate.Equal(result[j].LaunchDate) {
+			return result[i].LaunchDate.Before(result[j].LaunchDate)
+		}
+		return result[i].DestinationID < result[j].DestinationID
+	})
+	return result, nil
+}
+
+func (m *memory) ReplaceClosures(_ context.Context, source string, closures []models.Closure) error {
+	return m.write(func(state *memoryState) error {
+		var kept []models.Closure
+		for _, closure := range state.closures {
+			if closure.Source != source {
+				kept = append(kept, closure)
+			}
+		}
+		for _, closure := range closures {
+			closure.Source = source
+			kept = append(kept, closure)
+		}
+		state.closures = kept
+		return nil
+	})
+}
+
+func (m *memory) ListClosures(_ context.Context, launchPadID string, from time.Time, to time.Time) ([]models.Closure, error) {
+	var result []models.Closure
+	err := m.read(func(state *memoryState) error {
+		for _, closure := range state.closures {
+			if closure.LaunchPadID == launchPadID && closure.StartsAt.Before(to) && closure.EndsAt.After(from) {
+				result = append(result, closure)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].StartsAt.Before(result[j].StartsAt)
+	})
+	return result, nil
+}
+
+func (m *memory) AppendAudit(_ context.Context, entry models.AuditEntry) error {
+	return m.write(func(state *memoryState) error {
+		// Like in Postgres, the snapshots are stored without the personal data
+		state.audit = append(state.audit, auditEntryWithoutPersonalData(entry))
+		return nil
+	})
+}
+
+func (m *memory) ListAudit(_ context.Context, bookingID uuid.UUID) ([]models.AuditEntry, error) {
+	var result []models.AuditEntry
+	err := m.read(func(state *memoryState) error {
+		for _, entry := range state.audit {
+			if entry.BookingID == bookingID {
+				result = append(result, copyAuditEntry(entry))
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// copyAuditEntry copies the snapshots, so the history cannot be changed through them
+func copyAuditEntry(entry models.AuditEntry) models.AuditEntry {
+	if entry.Before != nil {
+		before := *entry.Before
+		entry.Before = &before
+	}
+	if entry.After != nil {
+		after := *entry.After
+		entry.After = &after
+	}
+	return entry
+}
+
+// auditEntryWithoutPersonalData returns the entry with copies of its snapshots without the personal data
+func auditEntryWithoutPersonalData(entry models.AuditEntry) models.AuditEntry {
+	if entry.Before != nil {
+		before := withoutPersonalData(*entry.Before)
+		entry.Before = &before
+	}
+	if entry.After != nil {
+		after := withoutPersonalData(*entry.After)
+		entry.After = &after
+	}
+	return entry
+}
+
+func (m *memory) CreateBlackout(_ context.Context, blackout models.Blackout) error {
+	return m.write(func(state *memoryState) error {
+		if _, ok := state.blackouts[blackout.ID]; ok {
+			return fmt.Errorf("unable to create blackout: blackout %s already exists", blackout.ID)
+		}
+		state.blackouts[blackout.ID] = blackout
+		return nil
+	})
+}
+
+func (m *memory) GetBlackout(_ context.Context, id uuid.UUID) (*models.Blackout, error) {
+	var result *models.Blackout
+	err := m.read(func(state *memoryState) error {
+		blackout, ok := state.blackouts[id]
+		if !ok {
+			return ErrNotFound
+		}
+		result = &blackout
+		return nil
+	})
+	return result, err
+}
+
+func (m *memory) ListBlackouts(_ context.Context, filters models.BlackoutFilters) ([]models.Blackout, error) {
+	var result []models.Blackout
+	err := m.read(func(state *memoryState) error {
+		for _, blackout := range state.blackouts {
+			switch {
+			case filters.LaunchPadID != nil && blackout.LaunchPadID != "" && blackout.LaunchPadID != *filters.LaunchPadID,
+				filters.From != nil && !blackout.EndsAt.After(*filters.From),
+				filters.To != nil && !blackout.StartsAt.Before(*filters.To):
+				continue
+			}
+			result = append(result, blackout)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].StartsAt.Equal(result[j].StartsAt) {
+			return result[i].StartsAt.Before(result[j].StartsAt)
+		}
+		return bytes.Compare(result[i].ID[:], result[j].ID[:]) < 0
+	})
+	return result, nil
+}
+
+func (m *memory) UpdateBlackout(_ context.Context, blackout models.Blackout) error {
+	return m.write(func(state *memoryState) error {
+		stored, ok := state.blackouts[blackout.ID]
+		if !ok {
+			return ErrNotFound
+		}
+		stored.LaunchPadID = blackout.LaunchPadID
+		stored.StartsAt = blackout.StartsAt
+		stored.EndsAt = blackout.EndsAt
+		stored.Reason = blackout.Reason
+		stored.UpdatedAt = blackout.UpdatedAt
+		state.blackouts[blackout.ID] = stored
+		return nil
+	})
+}
+
+func (m *memory) DeleteBlackout(_ context.Context, id uuid.UUID) error {
+	return m.write(func(state *memoryState) error {
+		if _, ok := state.blackouts[id]; !ok {
+			return ErrNotFound
+		}
+		delete(state.blackouts, id)
+		return nil
+	})
+}
+
+// recordEvent records an event of the booking in the outbox of state
+func (s *memoryState) recordEvent(eventType string, booking models.Booking) error {
+	data, err := marshalWithoutPersonalData(booking)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	s.lastSeq++
+	s.events = append(s.events, memoryEvent{
+		event: models.OutboxEvent{
+			ID:        uuid.New(),
+			Type:      eventType,
+			BookingID: booking.ID,
+			Data:      data,
+			CreatedAt: now,
+		},
+		seq:         s.lastSeq,
+		status:      models.OutboxStatusPending,
+		availableAt: now,
+	})
+	return nil
+}
+
+func (m *memory) ClaimOutboxEvents(_ context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	var result []models.OutboxEvent
+	err := m.write(func(state *memoryState) error {
+		// The events are in seq order
+		blocked := map[uuid.UUID]bool{}
+		now := time.Now()
+		for i := range state.events {
+			if len(result) >= limit {
+				break
+			}
+			stored := state.events[i]
+			if stored.status != models.OutboxStatusPending || blocked[stored.event.BookingID] {
+				continue
+			}
+			// The later events of the booking wait for this one
+			blocked[stored.event.BookingID] = true
+			if stored.availableAt.After(now) || stored.lockedUntil.After(now) {
+				continue
+			}
+			state.events[i].lockedUntil = now.Add(lease)
+			result = append(result, stored.event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (m *memory) MarkOutboxEventPublished(_ context.Context, id uuid.UUID) error {
+	return m.write(func(state *memoryState) error {
+		for i := range state.events {
+			if state.events[i].event.ID == id {
+				state.events[i].status = models.OutboxStatusPublished
+				state.events[i].event.Attempts++
+				state.events[i].lockedUntil = time.Time{}
+			}
+		}
+		return nil
+	})
+}
+
+func (m *memory) MarkOutboxEventFailed(_ context.Context, id uuid.UUID, _ string, retryIn time.Duration, dead bool) error {
+	return m.write(func(state *memoryState) error {
+		for i := range state.events {
+			if state.events[i].event.ID != id {
+				continue
+			}
+			state.events[i].status = models.OutboxStatusPending
+			if dead {
+				state.events[i].status = models.OutboxStatusDead
+			}
+			state.events[i].event.Attempts++
+			state.events[i].availableAt = time.Now().Add(retryIn)
+			state.events[i].lockedUntil = time.Time{}
+		}
+		return nil
+	})
+}
+
+// ReencryptBookings has nothing to do, the personal data is not encrypted in memory
+func (m *memory) ReencryptBookings(_ context.Context, _ int) (int, error) {
+	return 0, nil
+}
+
+func (m *memory) ListBookingsToAnonymize(_ context.Context, launchedBefore time.Time, pagination models.Pagination) ([]models.ExpiredBooking, error) {
+	var result []models.ExpiredBooking
+	err := m.read(func(state *memoryState) error {
+		for _, stored := range state.bookings {
+			if stored.anonymizedAt == nil && stored.booking.LaunchDate.Before(launchedBefore) {
+				result = append(result, models.ExpiredBooking{ID: stored.booking.ID, LaunchDate: stored.booking.LaunchDate})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortExpiredBookings(result)
+	return paginate(result, pagination), nil
+}
+
+func (m *memory) ListBookingsToPurge(_ context.Context, launchedBefore time.Time, pagination models.Pagination) ([]models.ExpiredBooking, error) {
+	var result []models.ExpiredBooking
+	err := m.read(func(state *memoryState) error {
+		for _, stored := range state.bookings {
+			if stored.booking.LaunchDate.Before(launchedBefore) {
+				result = append(result, models.ExpiredBooking{ID: stored.booking.ID, LaunchDate: stored.booking.LaunchDate})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortExpiredBookings(result)
+	return paginate(result, pagination), nil
+}
+
+// sortExpiredBookings sorts the bookings by launch date, then by ID like Postgres compares UUIDs
+func sortExpiredBookings(bookings []models.ExpiredBooking) {
+	sort.Slice(bookings, func(i, j int) bool {
+		if !bookings[i].LaunchDate.Equal(bookings[j].LaunchDate) {
+			return bookings[i].LaunchDate.Before(bookings[j].LaunchDate)
+		}
+		return bytes.Compare(bookings[i].ID[:], bookings[j].ID[:]) < 0
+	})
+}
+
+func (m *memory) AnonymizeBooking(_ context.Context, id uuid.UUID, at time.Time) (*models.Booking, error) {
+	var result *models.Booking
+	err := m.write(func(state *memoryState) error {
+		stored, ok := state.bookings[id]
+		if !ok || stored.anonymizedAt != nil {
+			// Deleted, or anonymized in the meantime
+			return nil
+		}
+		booking := withoutPersonalData(stored.booking)
+		booking.UpdatedAt = at
+		booking.Version++
+		result = &booking
+		state.bookings[id] = memoryBooking{booking: booking, anonymizedAt: &at}
+		return state.recordEvent(models.EventBookingAnonymized, *result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (m *memory) PurgeBooking(_ context.Context, id uuid.UUID) error {
+	return m.write(func(state *memoryState) error {
+		stored, ok := state.bookings[id]
+		if !ok {
+			return ErrNotFound
+		}
+		delete(state.bookings, id)
+		return state.recordEvent(models.EventBookingPurged, withoutPersonalData(stored.booking))
+	})
+}
+
+func (m *memory) LockLaunchSlot(_ context.Context, _ string, _ time.Time, _ time.Duration) error {
+	if m.tx == nil {
+		return ErrNoTransaction
+	}
+	// The transaction already runs alone
+	return nil
+}
+
+func (m *memory) Health() error {
+	return nil
+}
+
+func (m *memory) Close(_ context.Context) {}
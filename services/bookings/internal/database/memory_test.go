@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+func TestParseStorage(t *testing.T) {
+	storage, err := ParseStorage("memory")
+	assert.NoError(t, err)
+	assert.Equal(t, StorageMemory, storage)
+
+	_, err = ParseStorage("sqlite")
+	assert.Error(t, err)
+}
+
+func TestMemory_Concurrency(t *testing.T) {
+	db := NewMemory()
+	ctx := context.Background()
+	launchDate := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+	const capacity = 5
+
+	// Like the service, every booking checks the bookings of the flight before creating one
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := db.WithTx(ctx, func(tx Database) error {
+				require.NoError(t, tx.LockLaunchSlot(ctx, "LP-001", launchDate, time.Second))
+				counts, err := tx.CountBookingsByLaunchDate(ctx, "LP-001", launchDate, launchDate.AddDate(0, 0, 1))
+				if err != nil || len(counts) > 0 && counts[0].Bookings >= capacity {
+					return err
+				}
+				return tx.Create(ctx, testBooking("LP-001", launchDate))
+			})
+			assert.NoError(t, err)
+			_, err = db.List(ctx, models.Pagination{Limit: 10}, models.Filters{})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	bookings, err := db.List(ctx, models.Pagination{Limit: 100}, models.Filters{})
+	require.NoError(t, err)
+	assert.Len(t, bookings, capacity)
+}
+
+func TestMemory_CopiesValues(t *testing.T) {
+	db := NewMemory()
+	ctx := context.Background()
+	booking := testBooking("LP-001", time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, db.Create(ctx, booking))
+	require.NoError(t, db.AppendAudit(ctx, models.AuditEntry{BookingID: booking.ID, Action: models.AuditActionCreated, After: &booking}))
+
+	got, err := db.GetByID(ctx, booking.ID)
+	require.NoError(t, err)
+	got.FirstName = "Changed"
+	history, err := db.ListAudit(ctx, booking.ID)
+	require.NoError(t, err)
+	history[0].After.DestinationID = "changed"
+
+	got, err = db.GetByID(ctx, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "John", got.FirstName)
+	history, err = db.ListAudit(ctx, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, booking.DestinationID, history[0].After.DestinationID)
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database/queries"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+// createBookingEvent records an event of the booking, without its personal data, in the outbox. txQueries must be
+// the queries of the transaction changing the booking so the event is only published if the change is committed.
+func createBookingEvent(ctx context.Context, txQueries *queries.Queries, eventType string, booking models.Booking) error {
+	data, err := marshalWithoutPersonalData(booking)
+	if err != nil {
+		return err
+	}
+	err = txQueries.CreateOutboxEvent(ctx, queries.CreateOutboxEventParams{
+		ID:        uuid.New(),
+		BookingID: booking.ID,
+		EventType: eventType,
+		Payload:   data,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create outbox event: %w", err)
+	}
+	return nil
+}
+
+func (q *pg) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	events, err := q.queries.ClaimOutboxEvents(ctx, queries.ClaimOutboxEventsParams{
+		LeaseSeconds: lease.Seconds(),
+		Limit:        int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to claim outbox events: %w", err)
+	}
+	// UPDATE ... RETURNING does not keep the order of the subquery
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Seq < events[j].Seq
+	})
+	var result []models.OutboxEvent
+	for _, e := range events {
+		result = append(result, models.OutboxEvent{
+			ID:        e.ID,
+			Type:      e.EventType,
+			BookingID: e.BookingID,
+			Data:      e.Payload,
+			CreatedAt: e.CreatedAt.Time,
+			Attempts:  int(e.Attempts),
+		})
+	}
+	return result, nil
+}
+
+func (q *pg) MarkOutboxEventPublished(ctx context.Context, id uuid.UUID) error {
+	err := q.queries.MarkOutboxEventPublished(ctx, id)
+	if err != nil {
+		return fmt.Errorf("unable to mark outbox event published: %w", err)
+	}
+	return nil
+}
+
+func (q *pg) MarkOutboxEventFailed(ctx context.Context, id uuid.UUID, lastError string, retryIn time.Duration, dead bool) error {
+	status := models.OutboxStatusPending
+	if dead {
+		status = models.OutboxStatusDead
+	}
+	err := q.queries.MarkOutboxEventFailed(ctx, queries.MarkOutboxEventFailedParams{
+		Status:         status,
+		LastError:      lastError,
+		RetryInSeconds: retryIn.Seconds(),
+		ID:             id,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to mark outbox event failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+// claimEvents leases the events of the bookings and runs fn with them
+func claimEvents(t *testing.T, db Database, bookingIDs []uuid.UUID, fn func(db Database, events []models.OutboxEvent) error) error {
+	claimed, err := db.ClaimOutboxEvents(context.Background(), 100, time.Minute)
+	require.NoError(t, err)
+	var events []models.OutboxEvent
+	for _, event := range claimed {
+		for _, id := range bookingIDs {
+			if event.BookingID == id {
+				events = append(events, event)
+			}
+		}
+	}
+	return fn(db, events)
+}
+
+func TestOutbox(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close(context.Background())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	booking := models.Booking{
+		ID:            uuid.New(),
+		Status:        models.BookingStatusConfirmed,
+		FirstName:     "John",
+		LastName:      "Doe",
+		Gender:        "Male",
+		Birthday:      now.AddDate(-25, 0, 0),
+		LaunchPadID:   "LP-001",
+		DestinationID: "DS-001",
+		LaunchDate:    now.AddDate(0, 1, 0),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Version:       1,
+	}
+	require.NoError(t, db.Create(ctx, booking))
+	require.NoError(t, db.Delete(ctx, booking.ID, 1))
+	// A booking created in a transaction rolled back has no event
+	rolledBack := booking
+	rolledBack.ID = uuid.New()
+	err := db.WithTx(ctx, func(tx Database) error {
+		require.NoError(t, tx.Create(ctx, rolledBack))
+		return errors.New("rollback")
+	})
+	require.EqualError(t, err, "rollback")
+	ids := []uuid.UUID{booking.ID, rolledBack.ID}
+
+	// The deleted event waits for the created one
+	err = claimEvents(t, db, ids, func(tx Database, events []models.OutboxEvent) error {
+		require.Len(t, events, 1)
+		assert.Equal(t, models.EventBookingCreated, events[0].Type)
+		var data models.Booking
+		require.NoError(t, json.Unmarshal(events[0].Data, &data))
+		assert.Equal(t, booking.ID, data.ID)
+		assert.Empty(t, data.FirstName, "events are recorded without the personal data")
+
+		// Events leased by a relay are skipped by the others
+		err := claimEvents(t, db, ids, func(_ Database, events []models.OutboxEvent) error {
+			assert.Empty(t, events)
+			return nil
+		})
+		require.NoError(t, err)
+		return tx.MarkOutboxEventPublished(ctx, events[0].ID)
+	})
+	require.NoError(t, err)
+
+	// Failed events are claimed again once they are due
+	err = claimEvents(t, db, ids, func(tx Database, events []models.OutboxEvent) error {
+		require.Len(t, events, 1)
+		assert.Equal(t, models.EventBookingDeleted, events[0].Type)
+		assert.Equal(t, 0, events[0].Attempts)
+		return tx.MarkOutboxEventFailed(ctx, events[0].ID, "webhook responded with status 502", 0, false)
+	})
+	require.NoError(t, err)
+	err = claimEvents(t, db, ids, func(tx Database, events []models.OutboxEvent) error {
+		require.Len(t, events, 1)
+		assert.Equal(t, 1, events[0].Attempts)
+		return tx.MarkOutboxEventFailed(ctx, events[0].ID, "webhook responded with status 502", time.Hour, false)
+	})
+	require.NoError(t, err)
+	err = claimEvents(t, db, ids, func(_ Database, events []models.OutboxEvent) error {
+		assert.Empty(t, events)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Dead events are never claimed again
+	other := booking
+	other.ID = uuid.New()
+	require.NoError(t, db.Create(ctx, other))
+	ids = []uuid.UUID{other.ID}
+	err = claimEvents(t, db, ids, func(tx Database, events []models.OutboxEvent) error {
+		require.Len(t, events, 1)
+		return tx.MarkOutboxEventFailed(ctx, events[0].ID, "webhook responded with status 502", 0, true)
+	})
+	require.NoError(t, err)
+	err = claimEvents(t, db, ids, func(_ Database, events []models.OutboxEvent) error {
+		assert.Empty(t, events)
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,210 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database/queries"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+// personalDataFields are the JSON fields of the personal data of a models.Booking
+var personalDataFields = []string{"first_name", "last_name", "gender", "birthday"}
+
+// encryptedPII is the personal data of a booking encrypted with a data key of its own,
+// and the blind indexes of the names the bookings are filtered by
+type encryptedPII struct {
+	KeyID          pgtype.Text
+	DataKey        []byte
+	FirstName      []byte
+	LastName       []byte
+	Gender         []byte
+	Birthday       []byte
+	FirstNameIndex []byte
+	LastNameIndex  []byte
+}
+
+// encryptPII encrypts the personal data of the booking with a new data key, encrypted with the active key
+func (q *pg) encryptPII(booking models.Booking) (encryptedPII, error) {
+	dataKey, err := q.keys.NewDataKey()
+	if err != nil {
+		return encryptedPII{}, err
+	}
+	result := encryptedPII{
+		KeyID:          pgtype.Text{String: dataKey.KeyID, Valid: true},
+		DataKey:        dataKey.Encrypted,
+		FirstNameIndex: q.keys.BlindIndex(booking.FirstName),
+		LastNameIndex:  q.keys.BlindIndex(booking.LastName),
+	}
+	columns := []struct {
+		name  string
+		value string
+		dest  *[]byte
+	}{
+		{name: "first_name", value: booking.FirstName, dest: &result.FirstName},
+		{name: "last_name", value: booking.LastName, dest: &result.LastName},
+		{name: "gender", value: booking.Gender, dest: &result.Gender},
+		{name: "birthday", value: booking.Birthday.UTC().Format(time.RFC3339Nano), dest: &result.Birthday},
+	}
+	for _, column := range columns {
+		*column.dest, err = dataKey.Encrypt([]byte(column.value), associatedData(booking, column.name))
+		if err != nil {
+			return encryptedPII{}, fmt.Errorf("unable to encrypt %s: %w", column.name, err)
+		}
+	}
+	return result, nil
+}
+
+// fromBooking returns the booking with its personal data decrypted,
+// the bookings not reencrypted since they were created in plaintext are returned as they are
+func (q *pg) fromBooking(booking queries.Booking) (models.Booking, error) {
+	result := bookingFromRow(booking)
+	if !booking.PiiKeyID.Valid {
+		return result, nil
+	}
+	dataKey, err := q.keys.OpenDataKey(booking.PiiKeyID.String, booking.PiiDataKey)
+	if err != nil {
+		return models.Booking{}, fmt.Errorf("unable to open data key of booking %s: %w", booking.ID, err)
+	}
+	var birthday string
+	columns := []struct {
+		name       string
+		ciphertext []byte
+		dest       *string
+	}{
+		{name: "first_name", ciphertext: booking.FirstNameEncrypted, dest: &result.FirstName},
+		{name: "last_name", ciphertext: booking.LastNameEncrypted, dest: &result.LastName},
+		{name: "gender", ciphertext: booking.GenderEncrypted, dest: &result.Gender},
+		{name: "birthday", ciphertext: booking.BirthdayEncrypted, dest: &birthday},
+	}
+	for _, column := range columns {
+		plaintext, err := dataKey.Decrypt(column.ciphertext, associatedData(result, column.name))
+		if err != nil {
+			return models.Booking{}, fmt.Errorf("unable to decrypt %s of booking %s: %w", column.name, booking.ID, err)
+		}
+		*column.dest = string(plaintext)
+	}
+	result.Birthday, err = time.Parse(time.RFC3339Nano, birthday)
+	if err != nil {
+		return models.Booking{}, fmt.Errorf("unable to parse birthday of booking %s: %w", booking.ID, err)
+	}
+	return result, nil
+}
+
+// bookingFromRow returns the booking with its plaintext columns, without decrypting its personal data
+func bookingFromRow(booking queries.Booking) models.Booking {
+	return models.Booking{
+		ID:            booking.ID,
+		Status:        booking.Status,
+		FirstName:     booking.FirstName.String,
+		LastName:      booking.LastName.String,
+		Gender:        booking.Gender.String,
+		Birthday:      booking.Birthday.Time,
+		LaunchPadID:   booking.LaunchPadID,
+		DestinationID: booking.DestinationID,
+		LaunchDate:    booking.LaunchDate.Time,
+		CreatedAt:     booking.CreatedAt.Time,
+		UpdatedAt:     booking.UpdatedAt.Time,
+		Version:       int(booking.Version),
+	}
+}
+
+func (q *pg) fromBookings(bookings []queries.Booking) ([]models.Booking, error) {
+	var result []models.Booking
+	for _, b := range bookings {
+		booking, err := q.fromBooking(b)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, booking)
+	}
+	return result, nil
+}
+
+// associatedData binds an encrypted value to its booking and column
+func associatedData(booking models.Booking, column string) []byte {
+	return []byte(booking.ID.String() + "/" + column)
+}
+
+func (q *pg) ReencryptBookings(ctx context.Context, limit int) (int, error) {
+	var reencrypted int
+	err := q.inTx(ctx, func(txQueries *queries.Queries) error {
+		bookings, err := txQueries.ListBookingsToReencrypt(ctx, queries.ListBookingsToReencryptParams{
+			ActiveKeyID: q.keys.ActiveKeyID(),
+			Limit:       int32(limit),
+		})
+		if err != nil {
+			return fmt.Errorf("unable to list bookings to reencrypt: %w", err)
+		}
+		for _, b := range bookings {
+			booking, err := q.fromBooking(b)
+			if err != nil {
+				return err
+			}
+			pii, err := q.encryptPII(booking)
+			if err != nil {
+				return err
+			}
+			err = txQueries.SetBookingPII(ctx, queries.SetBookingPIIParams{
+				PiiKeyID:           pii.KeyID,
+				PiiDataKey:         pii.DataKey,
+				FirstNameEncrypted: pii.FirstName,
+				LastNameEncrypted:  pii.LastName,
+				GenderEncrypted:    pii.Gender,
+				BirthdayEncrypted:  pii.Birthday,
+				FirstNameIndex:     pii.FirstNameIndex,
+				LastNameIndex:      pii.LastNameIndex,
+				ID:                 booking.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("unable to reencrypt booking %s: %w", booking.ID, err)
+			}
+		}
+		reencrypted = len(bookings)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return reencrypted, nil
+}
+
+// withoutPersonalData returns the booking with its personal data cleared
+func withoutPersonalData(booking models.Booking) models.Booking {
+	booking.FirstName = ""
+	booking.LastName = ""
+	booking.Gender = ""
+	booking.Birthday = time.Time{}
+	return booking
+}
+
+// marshalWithoutPersonalData returns the JSON of the booking without the fields of its personal data. The snapshots
+// of the history and the payloads of the events are not encrypted, so they are stored without it.
+func marshalWithoutPersonalData(booking models.Booking) ([]byte, error) {
+	data, err := json.Marshal(booking)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal booking: %w", err)
+	}
+	return removePersonalData(data)
+}
+
+// removePersonalData removes the fields of the personal data from the JSON of a booking
+func removePersonalData(data []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal booking: %w", err)
+	}
+	for _, field := range personalDataFields {
+		delete(fields, field)
+	}
+	result, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal booking: %w", err)
+	}
+	return result, nil
+}
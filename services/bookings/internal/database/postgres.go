@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 
@@ -12,15 +13,23 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database/queries"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/encryption"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
 )
 
 type pg struct {
-	pool    *pgxpool.Pool
-	queries *queries.Queries
+	pool *pgxpool.Pool
+	// tx is the transaction of WithTx, nil outside of it
+	tx       pgx.Tx
+	queries  *queries.Queries
+	txConfig TxConfig
+	// keys encrypt the personal data of the bookings
+	keys encryption.Keyring
 }
 
-func NewPostgres(ctx context.Context, connectionStr string) (Database, error) {
+// NewPostgres connects to Postgres, the personal data of the bookings is encrypted with keys.
+// opts set the defaults of the transactions of WithTx.
+func NewPostgres(ctx context.Context, connectionStr string, keys encryption.Keyring, opts ...TxOption) (Database, error) {
 	pool, err := pgxpool.New(ctx, connectionStr)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to database: %w", err)
@@ -31,42 +40,121 @@ func NewPostgres(ctx context.Context, connectionStr string) (Database, error) {
 		return nil, fmt.Errorf("unable to ping database: %w", err)
 	}
 
+	txConfig := DefaultTxConfig()
+	for _, opt := range opts {
+		opt(&txConfig)
+	}
 	return &pg{
-		pool:    pool,
-		queries: queries.New(pool),
+		pool:     pool,
+		queries:  queries.New(pool),
+		txConfig: txConfig,
+		keys:     keys,
 	}, nil
 }
 
 func (q *pg) Create(ctx context.Context, booking models.Booking) error {
-	err := q.queries.CreateBooking(ctx, queries.CreateBookingParams{
-		ID:            booking.ID,
-		FirstName:     booking.FirstName,
-		LastName:      booking.LastName,
-		Gender:        booking.Gender,
-		Birthday:      pgtype.Timestamptz{Time: booking.Birthday, Valid: true},
-		LaunchPadID:   booking.LaunchPadID,
-		DestinationID: booking.DestinationID,
-		LaunchDate:    pgtype.Timestamptz{Time: booking.LaunchDate, Valid: true},
-		CreatedAt:     pgtype.Timestamptz{Time: booking.CreatedAt, Valid: true},
-		UpdatedAt:     pgtype.Timestamptz{Time: booking.UpdatedAt, Valid: true},
-	})
+	pii, err := q.encryptPII(booking)
 	if err != nil {
-		return fmt.Errorf("error creating booking: %w", err)
+		return err
 	}
-	return nil
+	return q.inTx(ctx, func(txQueries *queries.Queries) error {
+		err := txQueries.CreateBooking(ctx, queries.CreateBookingParams{
+			ID:                 booking.ID,
+			LaunchPadID:        booking.LaunchPadID,
+			DestinationID:      booking.DestinationID,
+			LaunchDate:         pgtype.Timestamptz{Time: booking.LaunchDate, Valid: true},
+			CreatedAt:          pgtype.Timestamptz{Time: booking.CreatedAt, Valid: true},
+			UpdatedAt:          pgtype.Timestamptz{Time: booking.UpdatedAt, Valid: true},
+			Status:             booking.Status,
+			Version:            int32(booking.Version),
+			PiiKeyID:           pii.KeyID,
+			PiiDataKey:         pii.DataKey,
+			FirstNameEncrypted: pii.FirstName,
+			LastNameEncrypted:  pii.LastName,
+			GenderEncrypted:    pii.Gender,
+			BirthdayEncrypted:  pii.Birthday,
+			FirstNameIndex:     pii.FirstNameIndex,
+			LastNameIndex:      pii.LastNameIndex,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating booking: %w", err)
+		}
+		return createBookingEvent(ctx, txQueries, models.EventBookingCreated, booking)
+	})
 }
 
-func (q *pg) Delete(ctx context.Context, id uuid.UUID) error {
-	_, err := q.queries.DeleteBooking(ctx, id)
+func (q *pg) Update(ctx context.Context, booking models.Booking) error {
+	pii, err := q.encryptPII(booking)
 	if err != nil {
-		switch {
-		case errors.Is(err, pgx.ErrNoRows):
-			return ErrNotFound
-		default:
-			return fmt.Errorf("unable to delete: %w", err)
+		return err
+	}
+	return q.inTx(ctx, func(txQueries *queries.Queries) error {
+		_, err := txQueries.UpdateBooking(ctx, queries.UpdateBookingParams{
+			PiiKeyID:           pii.KeyID,
+			PiiDataKey:         pii.DataKey,
+			FirstNameEncrypted: pii.FirstName,
+			LastNameEncrypted:  pii.LastName,
+			GenderEncrypted:    pii.Gender,
+			BirthdayEncrypted:  pii.Birthday,
+			FirstNameIndex:     pii.FirstNameIndex,
+			LastNameIndex:      pii.LastNameIndex,
+			UpdatedAt:          pgtype.Timestamptz{Time: booking.UpdatedAt, Valid: true},
+			ID:                 booking.ID,
+			Version:            int32(booking.Version),
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+				return versionMismatchOrNotFound(ctx, txQueries, booking.ID)
+			default:
+				return fmt.Errorf("unable to update booking: %w", err)
+			}
 		}
+		stored, err := txQueries.GetBookingByID(ctx, booking.ID)
+		if err != nil {
+			return fmt.Errorf("unable to get updated booking: %w", err)
+		}
+		updated, err := q.fromBooking(stored)
+		if err != nil {
+			return err
+		}
+		return createBookingEvent(ctx, txQueries, models.EventBookingUpdated, updated)
+	})
+}
+
+func (q *pg) Delete(ctx context.Context, id uuid.UUID, version int) error {
+	return q.inTx(ctx, func(txQueries *queries.Queries) error {
+		stored, err := txQueries.DeleteBooking(ctx, queries.DeleteBookingParams{
+			ID:      id,
+			Version: int32(version),
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+				return versionMismatchOrNotFound(ctx, txQueries, id)
+			default:
+				return fmt.Errorf("unable to delete: %w", err)
+			}
+		}
+		booking, err := q.fromBooking(stored)
+		if err != nil {
+			return err
+		}
+		return createBookingEvent(ctx, txQueries, models.EventBookingDeleted, booking)
+	})
+}
+
+// versionMismatchOrNotFound tells apart why a write conditioned on the version of the booking matched no row
+func versionMismatchOrNotFound(ctx context.Context, txQueries *queries.Queries, id uuid.UUID) error {
+	_, err := txQueries.GetBookingByID(ctx, id)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return ErrNotFound
+	case err != nil:
+		return fmt.Errorf("unable to get booking: %w", err)
+	default:
+		return ErrVersionMismatch
 	}
-	return nil
 }
 
 func (q *pg) GetByID(ctx context.Context, id uuid.UUID) (*models.Booking, error) {
@@ -79,18 +167,11 @@ func (q *pg) GetByID(ctx context.Context, id uuid.UUID) (*models.Booking, error)
 			return nil, fmt.Errorf("unable to get booking: %w", err)
 		}
 	}
-	return &models.Booking{
-		ID:            booking.ID,
-		FirstName:     booking.FirstName,
-		LastName:      booking.LastName,
-		Gender:        booking.Gender,
-		Birthday:      booking.Birthday.Time, // All values are required so this is fine
-		LaunchPadID:   booking.LaunchPadID,
-		DestinationID: booking.DestinationID,
-		LaunchDate:    booking.LaunchDate.Time,
-		CreatedAt:     booking.CreatedAt.Time,
-		UpdatedAt:     booking.UpdatedAt.Time,
-	}, nil
+	result, err := q.fromBooking(booking)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
 func (q *pg) List(ctx context.Context, pagination models.Pagination, filters models.Filters) ([]models.Booking, error) {
@@ -98,6 +179,7 @@ func (q *pg) List(ctx context.Context, pagination models.Pagination, filters mod
 		LaunchDate:    pgtype.Timestamptz{},
 		LaunchPadID:   pgtype.Text{},
 		DestinationID: pgtype.Text{},
+		Status:        pgtype.Text{},
 		Offset:        int32(pagination.Offset),
 		Limit:         int32(pagination.Limit),
 	}
@@ -119,32 +201,221 @@ func (q *pg) List(ctx context.Context, pagination models.Pagination, filters mod
 			Valid:  true,
 		}
 	}
+	if filters.Status != nil {
+		params.Status = pgtype.Text{
+			String: *filters.Status,
+			Valid:  true,
+		}
+	}
+	// The names are looked up by their blind index, they are encrypted
+	if filters.FirstName != nil {
+		params.FirstNameIndex = q.keys.BlindIndex(*filters.FirstName)
+	}
+	if filters.LastName != nil {
+		params.LastNameIndex = q.keys.BlindIndex(*filters.LastName)
+	}
 	bookings, err := q.queries.ListBookings(ctx, params)
 	if err != nil {
 		return nil, err
 	}
-	var result []models.Booking
-	for _, b := range bookings {
-		result = append(result, models.Booking{
-			ID:            b.ID,
-			FirstName:     b.FirstName,
-			LastName:      b.LastName,
-			Gender:        b.Gender,
-			Birthday:      b.Birthday.Time, // All values are required so this is fine
-			LaunchPadID:   b.LaunchPadID,
-			DestinationID: b.DestinationID,
-			LaunchDate:    b.LaunchDate.Time,
-			CreatedAt:     b.CreatedAt.Time,
-			UpdatedAt:     b.UpdatedAt.Time,
+	return q.fromBookings(bookings)
+}
+
+func (q *pg) UpdateStatus(ctx context.Context, id uuid.UUID, status string, updatedAt time.Time) error {
+	_, err := q.queries.UpdateBookingStatus(ctx, queries.UpdateBookingStatusParams{
+		ID:        id,
+		Status:    status,
+		UpdatedAt: pgtype.Timestamptz{Time: updatedAt, Valid: true},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrNotFound
+		default:
+			return fmt.Errorf("unable to update status: %w", err)
+		}
+	}
+	return nil
+}
+
+func (q *pg) CountBookingsByLaunchDate(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]models.LaunchDateBookings, error) {
+	counts, err := q.queries.CountBookingsByLaunchDate(ctx, queries.CountBookingsByLaunchDateParams{
+		LaunchPadID: launchPadID,
+		FromDate:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToDate:      pgtype.Timestamptz{Time: to, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to count bookings: %w", err)
+	}
+	var result []models.LaunchDateBookings
+	for _, c := range counts {
+		result = append(result, models.LaunchDateBookings{
+			LaunchDate:    c.LaunchDate.Time,
+			DestinationID: c.DestinationID,
+			Bookings:      int(c.Bookings),
+		})
+	}
+	return result, nil
+}
+
+func (q *pg) ReplaceClosures(ctx context.Context, source string, closures []models.Closure) error {
+	return q.inTx(ctx, func(txQueries *queries.Queries) error {
+		err := txQueries.DeleteClosuresBySource(ctx, source)
+		if err != nil {
+			return fmt.Errorf("unable to delete closures: %w", err)
+		}
+		for _, closure := range closures {
+			err = txQueries.CreateClosure(ctx, queries.CreateClosureParams{
+				ID:          closure.ID,
+				LaunchPadID: closure.LaunchPadID,
+				StartsAt:    pgtype.Timestamptz{Time: closure.StartsAt, Valid: true},
+				EndsAt:      pgtype.Timestamptz{Time: closure.EndsAt, Valid: true},
+				Summary:     closure.Summary,
+				Source:      source,
+				CreatedAt:   pgtype.Timestamptz{Time: closure.CreatedAt, Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("unable to create closure: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (q *pg) ListClosures(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]models.Closure, error) {
+	closures, err := q.queries.ListClosures(ctx, queries.ListClosuresParams{
+		LaunchPadID: launchPadID,
+		EndsBefore:  pgtype.Timestamptz{Time: to, Valid: true},
+		StartsAfter: pgtype.Timestamptz{Time: from, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list closures: %w", err)
+	}
+	var result []models.Closure
+	for _, c := range closures {
+		result = append(result, models.Closure{
+			ID:          c.ID,
+			LaunchPadID: c.LaunchPadID,
+			StartsAt:    c.StartsAt.Time,
+			EndsAt:      c.EndsAt.Time,
+			Summary:     c.Summary,
+			Source:      c.Source,
+			CreatedAt:   c.CreatedAt.Time,
 		})
 	}
 	return result, nil
 }
 
+func (q *pg) CreateBlackout(ctx context.Context, blackout models.Blackout) error {
+	err := q.queries.CreateBlackout(ctx, queries.CreateBlackoutParams{
+		ID:          blackout.ID,
+		LaunchPadID: toText(blackout.LaunchPadID),
+		StartsAt:    pgtype.Timestamptz{Time: blackout.StartsAt, Valid: true},
+		EndsAt:      pgtype.Timestamptz{Time: blackout.EndsAt, Valid: true},
+		Reason:      blackout.Reason,
+		CreatedAt:   pgtype.Timestamptz{Time: blackout.CreatedAt, Valid: true},
+		UpdatedAt:   pgtype.Timestamptz{Time: blackout.UpdatedAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create blackout: %w", err)
+	}
+	return nil
+}
+
+func (q *pg) GetBlackout(ctx context.Context, id uuid.UUID) (*models.Blackout, error) {
+	blackout, err := q.queries.GetBlackoutByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, fmt.Errorf("unable to get blackout: %w", err)
+		}
+	}
+	result := fromBlackout(blackout)
+	return &result, nil
+}
+
+func (q *pg) ListBlackouts(ctx context.Context, filters models.BlackoutFilters) ([]models.Blackout, error) {
+	params := queries.ListBlackoutsParams{}
+	if filters.LaunchPadID != nil {
+		params.LaunchPadID = pgtype.Text{String: *filters.LaunchPadID, Valid: true}
+	}
+	if filters.From != nil {
+		params.StartsAfter = pgtype.Timestamptz{Time: *filters.From, Valid: true}
+	}
+	if filters.To != nil {
+		params.EndsBefore = pgtype.Timestamptz{Time: *filters.To, Valid: true}
+	}
+	blackouts, err := q.queries.ListBlackouts(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list blackouts: %w", err)
+	}
+	var result []models.Blackout
+	for _, b := range blackouts {
+		result = append(result, fromBlackout(b))
+	}
+	return result, nil
+}
+
+func (q *pg) UpdateBlackout(ctx context.Context, blackout models.Blackout) error {
+	_, err := q.queries.UpdateBlackout(ctx, queries.UpdateBlackoutParams{
+		ID:          blackout.ID,
+		LaunchPadID: toText(blackout.LaunchPadID),
+		StartsAt:    pgtype.Timestamptz{Time: blackout.StartsAt, Valid: true},
+		EndsAt:      pgtype.Timestamptz{Time: blackout.EndsAt, Valid: true},
+		Reason:      blackout.Reason,
+		UpdatedAt:   pgtype.Timestamptz{Time: blackout.UpdatedAt, Valid: true},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrNotFound
+		default:
+			return fmt.Errorf("unable to update blackout: %w", err)
+		}
+	}
+	return nil
+}
+
+func (q *pg) DeleteBlackout(ctx context.Context, id uuid.UUID) error {
+	_, err := q.queries.DeleteBlackout(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrNotFound
+		default:
+			return fmt.Errorf("unable to delete blackout: %w", err)
+		}
+	}
+	return nil
+}
+
+func fromBlackout(blackout queries.Blackout) models.Blackout {
+	return models.Blackout{
+		ID:          blackout.ID,
+		LaunchPadID: blackout.LaunchPadID.String, // Empty for the blackouts of every launch pad
+		StartsAt:    blackout.StartsAt.Time,
+		EndsAt:      blackout.EndsAt.Time,
+		Reason:      blackout.Reason,
+		CreatedAt:   blackout.CreatedAt.Time,
+		UpdatedAt:   blackout.UpdatedAt.Time,
+	}
+}
+
+// toText stores empty strings as NULL
+func toText(value string) pgtype.Text {
+	return pgtype.Text{String: value, Valid: value != ""}
+}
+
 func (q *pg) Health() error {
 	return q.pool.Ping(context.Background())
 }
 
+// Close closes the pool, it is a no-op in a transaction as the pool is shared
 func (q *pg) Close(_ context.Context) {
+	if q.tx != nil {
+		return
+	}
 	q.pool.Close()
 }
@@ -9,15 +9,78 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Blackout struct {
+	ID          uuid.UUID
+	LaunchPadID pgtype.Text
+	StartsAt    pgtype.Timestamptz
+	EndsAt      pgtype.Timestamptz
+	Reason      string
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
 type Booking struct {
-	ID            uuid.UUID
-	FirstName     string
-	LastName      string
-	Gender        string
-	Birthday      pgtype.Timestamptz
-	LaunchPadID   string
-	DestinationID string
-	LaunchDate    pgtype.Timestamptz
-	CreatedAt     pgtype.Timestamptz
-	UpdatedAt     pgtype.Timestamptz
+	ID                 uuid.UUID
+	FirstName          pgtype.Text
+	LastName           pgtype.Text
+	Gender             pgtype.Text
+	Birthday           pgtype.Timestamptz
+	LaunchPadID        string
+	DestinationID      string
+	LaunchDate         pgtype.Timestamptz
+	CreatedAt          pgtype.Timestamptz
+	UpdatedAt          pgtype.Timestamptz
+	Status             string
+	Version            int32
+	PiiKeyID           pgtype.Text
+	PiiDataKey         []byte
+	FirstNameEncrypted []byte
+	LastNameEncrypted  []byte
+	GenderEncrypted    []byte
+	BirthdayEncrypted  []byte
+	FirstNameIndex     []byte
+	LastNameIndex      []byte
+	AnonymizedAt       pgtype.Timestamptz
+}
+
+type BookingAudit struct {
+	ID         int64
+	BookingID  uuid.UUID
+	Action     string
+	Actor      string
+	OccurredAt pgtype.Timestamptz
+	Before     []byte
+	After      []byte
+}
+
+type CacheEntry struct {
+	Key       string
+	Value     []byte
+	StoredAt  pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+type Closure struct {
+	ID          uuid.UUID
+	LaunchPadID string
+	StartsAt    pgtype.Timestamptz
+	EndsAt      pgtype.Timestamptz
+	Summary     string
+	Source      string
+	CreatedAt   pgtype.Timestamptz
+}
+
+type OutboxEvent struct {
+	ID          uuid.UUID
+	Seq         int64
+	BookingID   uuid.UUID
+	EventType   string
+	Payload     []byte
+	Status      string
+	Attempts    int32
+	LastError   string
+	CreatedAt   pgtype.Timestamptz
+	AvailableAt pgtype.Timestamptz
+	PublishedAt pgtype.Timestamptz
+	LockedUntil pgtype.Timestamptz
 }
@@ -12,9 +12,232 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const anonymizeBooking = `-- name: AnonymizeBooking :one
+UPDATE bookings
+SET first_name           = NULL,
+    last_name            = NULL,
+    gender               = NULL,
+    birthday             = NULL,
+    pii_key_id           = NULL,
+    pii_data_key         = NULL,
+    first_name_encrypted = NULL,
+    last_name_encrypted  = NULL,
+    gender_encrypted     = NULL,
+    birthday_encrypted   = NULL,
+    first_name_index     = NULL,
+    last_name_index      = NULL,
+    anonymized_at        = $1,
+    updated_at           = $1,
+    version              = version + 1
+WHERE id = $2
+  AND anonymized_at IS NULL
+RETURNING id,
+    first_name,
+    last_name,
+    gender,
+    birthday,
+    launch_pad_id,
+    destination_id,
+    launch_date,
+    created_at,
+    updated_at,
+    status,
+    version,
+    pii_key_id,
+    pii_data_key,
+    first_name_encrypted,
+    last_name_encrypted,
+    gender_encrypted,
+    birthday_encrypted,
+    first_name_index,
+    last_name_index,
+    anonymized_at
+`
+
+type AnonymizeBookingParams struct {
+	AnonymizedAt pgtype.Timestamptz
+	ID           uuid.UUID
+}
+
+func (q *Queries) AnonymizeBooking(ctx context.Context, arg AnonymizeBookingParams) (Booking, error) {
+	row := q.db.QueryRow(ctx, anonymizeBooking, arg.AnonymizedAt, arg.ID)
+	var i Booking
+	err := row.Scan(
+		&i.ID,
+		&i.FirstName,
+		&i.LastName,
+		&i.Gender,
+		&i.Birthday,
+		&i.LaunchPadID,
+		&i.DestinationID,
+		&i.LaunchDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.Version,
+		&i.PiiKeyID,
+		&i.PiiDataKey,
+		&i.FirstNameEncrypted,
+		&i.LastNameEncrypted,
+		&i.GenderEncrypted,
+		&i.BirthdayEncrypted,
+		&i.FirstNameIndex,
+		&i.LastNameIndex,
+		&i.AnonymizedAt,
+	)
+	return i, err
+}
+
+const claimOutboxEvents = `-- name: ClaimOutboxEvents :many
+UPDATE outbox_events
+SET locked_until = now() + make_interval(secs => $1::float8)
+WHERE id IN (SELECT e.id
+             FROM outbox_events e
+             WHERE e.status = 'pending'
+               AND e.available_at <= now()
+               AND (e.locked_until IS NULL OR e.locked_until <= now())
+               AND NOT EXISTS (SELECT 1
+                               FROM outbox_events earlier
+                               WHERE earlier.booking_id = e.booking_id
+                                 AND earlier.status = 'pending'
+                                 AND earlier.seq < e.seq)
+             ORDER BY e.seq
+             LIMIT $2 FOR UPDATE SKIP LOCKED)
+RETURNING id,
+    seq,
+    booking_id,
+    event_type,
+    payload,
+    status,
+    attempts,
+    last_error,
+    created_at,
+    available_at,
+    published_at,
+    locked_until
+`
+
+type ClaimOutboxEventsParams struct {
+	LeaseSeconds float64
+	Limit        int32
+}
+
+func (q *Queries) ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]OutboxEvent, error) {
+	rows, err := q.db.Query(ctx, claimOutboxEvents, arg.LeaseSeconds, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutboxEvent
+	for rows.Next() {
+		var i OutboxEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Seq,
+			&i.BookingID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.AvailableAt,
+			&i.PublishedAt,
+			&i.LockedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countBookingsByLaunchDate = `-- name: CountBookingsByLaunchDate :many
+SELECT launch_date,
+       destination_id,
+       count(*) AS bookings
+FROM bookings
+WHERE launch_pad_id = $1
+  AND launch_date >= $2
+  AND launch_date < $3
+  AND status <> 'rejected'
+GROUP BY launch_date, destination_id
+ORDER BY launch_date, destination_id
+`
+
+type CountBookingsByLaunchDateParams struct {
+	LaunchPadID string
+	FromDate    pgtype.Timestamptz
+	ToDate      pgtype.Timestamptz
+}
+
+type CountBookingsByLaunchDateRow struct {
+	LaunchDate    pgtype.Timestamptz
+	DestinationID string
+	Bookings      int64
+}
+
+func (q *Queries) CountBookingsByLaunchDate(ctx context.Context, arg CountBookingsByLaunchDateParams) ([]CountBookingsByLaunchDateRow, error) {
+	rows, err := q.db.Query(ctx, countBookingsByLaunchDate, arg.LaunchPadID, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountBookingsByLaunchDateRow
+	for rows.Next() {
+		var i CountBookingsByLaunchDateRow
+		if err := rows.Scan(&i.LaunchDate, &i.DestinationID, &i.Bookings); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createBlackout = `-- name: CreateBlackout :exec
+INSERT INTO blackouts (id, launch_pad_id, starts_at, ends_at, reason, created_at, updated_at)
+VALUES ($1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6,
+        $7)
+`
+
+type CreateBlackoutParams struct {
+	ID          uuid.UUID
+	LaunchPadID pgtype.Text
+	StartsAt    pgtype.Timestamptz
+	EndsAt      pgtype.Timestamptz
+	Reason      string
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+func (q *Queries) CreateBlackout(ctx context.Context, arg CreateBlackoutParams) error {
+	_, err := q.db.Exec(ctx, createBlackout,
+		arg.ID,
+		arg.LaunchPadID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Reason,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
 const createBooking = `-- name: CreateBooking :exec
-INSERT INTO bookings (id, first_name, last_name, gender, birthday, launch_pad_id, destination_id, launch_date,
-                      created_at, updated_at)
+INSERT INTO bookings (id, launch_pad_id, destination_id, launch_date, created_at, updated_at, status, version,
+                      pii_key_id, pii_data_key, first_name_encrypted, last_name_encrypted, gender_encrypted,
+                      birthday_encrypted, first_name_index, last_name_index)
 VALUES ($1,
         $2,
         $3,
@@ -24,51 +247,286 @@ VALUES ($1,
         $7,
         $8,
         $9,
-        $10)
+        $10,
+        $11,
+        $12,
+        $13,
+        $14,
+        $15,
+        $16)
 `
 
 type CreateBookingParams struct {
-	ID            uuid.UUID
-	FirstName     string
-	LastName      string
-	Gender        string
-	Birthday      pgtype.Timestamptz
-	LaunchPadID   string
-	DestinationID string
-	LaunchDate    pgtype.Timestamptz
-	CreatedAt     pgtype.Timestamptz
-	UpdatedAt     pgtype.Timestamptz
+	ID                 uuid.UUID
+	LaunchPadID        string
+	DestinationID      string
+	LaunchDate         pgtype.Timestamptz
+	CreatedAt          pgtype.Timestamptz
+	UpdatedAt          pgtype.Timestamptz
+	Status             string
+	Version            int32
+	PiiKeyID           pgtype.Text
+	PiiDataKey         []byte
+	FirstNameEncrypted []byte
+	LastNameEncrypted  []byte
+	GenderEncrypted    []byte
+	BirthdayEncrypted  []byte
+	FirstNameIndex     []byte
+	LastNameIndex      []byte
 }
 
 func (q *Queries) CreateBooking(ctx context.Context, arg CreateBookingParams) error {
 	_, err := q.db.Exec(ctx, createBooking,
 		arg.ID,
-		arg.FirstName,
-		arg.LastName,
-		arg.Gender,
-		arg.Birthday,
 		arg.LaunchPadID,
 		arg.DestinationID,
 		arg.LaunchDate,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.Status,
+		arg.Version,
+		arg.PiiKeyID,
+		arg.PiiDataKey,
+		arg.FirstNameEncrypted,
+		arg.LastNameEncrypted,
+		arg.GenderEncrypted,
+		arg.BirthdayEncrypted,
+		arg.FirstNameIndex,
+		arg.LastNameIndex,
 	)
 	return err
 }
 
-const deleteBooking = `-- name: DeleteBooking :one
+const createBookingAuditEntry = `-- name: CreateBookingAuditEntry :exec
+INSERT INTO booking_audit (booking_id, action, actor, occurred_at, before, after)
+VALUES ($1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6)
+`
+
+type CreateBookingAuditEntryParams struct {
+	BookingID  uuid.UUID
+	Action     string
+	Actor      string
+	OccurredAt pgtype.Timestamptz
+	Before     []byte
+	After      []byte
+}
+
+func (q *Queries) CreateBookingAuditEntry(ctx context.Context, arg CreateBookingAuditEntryParams) error {
+	_, err := q.db.Exec(ctx, createBookingAuditEntry,
+		arg.BookingID,
+		arg.Action,
+		arg.Actor,
+		arg.OccurredAt,
+		arg.Before,
+		arg.After,
+	)
+	return err
+}
+
+const createClosure = `-- name: CreateClosure :exec
+INSERT INTO closures (id, launch_pad_id, starts_at, ends_at, summary, source, created_at)
+VALUES ($1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6,
+        $7)
+`
+
+type CreateClosureParams struct {
+	ID          uuid.UUID
+	LaunchPadID string
+	StartsAt    pgtype.Timestamptz
+	EndsAt      pgtype.Timestamptz
+	Summary     string
+	Source      string
+	CreatedAt   pgtype.Timestamptz
+}
+
+func (q *Queries) CreateClosure(ctx context.Context, arg CreateClosureParams) error {
+	_, err := q.db.Exec(ctx, createClosure,
+		arg.ID,
+		arg.LaunchPadID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Summary,
+		arg.Source,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const createOutboxEvent = `-- name: CreateOutboxEvent :exec
+INSERT INTO outbox_events (id, booking_id, event_type, payload, created_at, available_at)
+VALUES ($1,
+        $2,
+        $3,
+        $4,
+        now(),
+        now())
+`
+
+type CreateOutboxEventParams struct {
+	ID        uuid.UUID
+	BookingID uuid.UUID
+	EventType string
+	Payload   []byte
+}
+
+func (q *Queries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) error {
+	_, err := q.db.Exec(ctx, createOutboxEvent,
+		arg.ID,
+		arg.BookingID,
+		arg.EventType,
+		arg.Payload,
+	)
+	return err
+}
+
+const deleteBlackout = `-- name: DeleteBlackout :one
 DELETE
-FROM bookings
+FROM blackouts
 WHERE id = $1
 RETURNING id
 `
 
-func (q *Queries) DeleteBooking(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
-	row := q.db.QueryRow(ctx, deleteBooking, id)
+func (q *Queries) DeleteBlackout(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, deleteBlackout, id)
 	err := row.Scan(&id)
 	return id, err
 }
 
+const deleteBooking = `-- name: DeleteBooking :one
+DELETE
+FROM bookings
+WHERE id = $1
+  AND version = $2
+RETURNING id,
+    first_name,
+    last_name,
+    gender,
+    birthday,
+    launch_pad_id,
+    destination_id,
+    launch_date,
+    created_at,
+    updated_at,
+    status,
+    version,
+    pii_key_id,
+    pii_data_key,
+    first_name_encrypted,
+    last_name_encrypted,
+    gender_encrypted,
+    birthday_encrypted,
+    first_name_index,
+    last_name_index,
+    anonymized_at
+`
+
+type DeleteBookingParams struct {
+	ID      uuid.UUID
+	Version int32
+}
+
+func (q *Queries) DeleteBooking(ctx context.Context, arg DeleteBookingParams) (Booking, error) {
+	row := q.db.QueryRow(ctx, deleteBooking, arg.ID, arg.Version)
+	var i Booking
+	err := row.Scan(
+		&i.ID,
+		&i.FirstName,
+		&i.LastName,
+		&i.Gender,
+		&i.Birthday,
+		&i.LaunchPadID,
+		&i.DestinationID,
+		&i.LaunchDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.Version,
+		&i.PiiKeyID,
+		&i.PiiDataKey,
+		&i.FirstNameEncrypted,
+		&i.LastNameEncrypted,
+		&i.GenderEncrypted,
+		&i.BirthdayEncrypted,
+		&i.FirstNameIndex,
+		&i.LastNameIndex,
+		&i.AnonymizedAt,
+	)
+	return i, err
+}
+
+const deleteCacheEntry = `-- name: DeleteCacheEntry :exec
+DELETE
+FROM cache_entries
+WHERE key = $1
+`
+
+func (q *Queries) DeleteCacheEntry(ctx context.Context, key string) error {
+	_, err := q.db.Exec(ctx, deleteCacheEntry, key)
+	return err
+}
+
+const deleteClosuresBySource = `-- name: DeleteClosuresBySource :exec
+DELETE
+FROM closures
+WHERE source = $1
+`
+
+func (q *Queries) DeleteClosuresBySource(ctx context.Context, source string) error {
+	_, err := q.db.Exec(ctx, deleteClosuresBySource, source)
+	return err
+}
+
+const deleteExpiredCacheEntries = `-- name: DeleteExpiredCacheEntries :execrows
+DELETE
+FROM cache_entries
+WHERE expires_at <= $1
+`
+
+func (q *Queries) DeleteExpiredCacheEntries(ctx context.Context, expiresAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredCacheEntries, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getBlackoutByID = `-- name: GetBlackoutByID :one
+SELECT id,
+       launch_pad_id,
+       starts_at,
+       ends_at,
+       reason,
+       created_at,
+       updated_at
+FROM blackouts
+WHERE id = $1
+`
+
+func (q *Queries) GetBlackoutByID(ctx context.Context, id uuid.UUID) (Blackout, error) {
+	row := q.db.QueryRow(ctx, getBlackoutByID, id)
+	var i Blackout
+	err := row.Scan(
+		&i.ID,
+		&i.LaunchPadID,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Reason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getBookingByID = `-- name: GetBookingByID :one
 SELECT id,
        first_name,
@@ -79,7 +537,18 @@ SELECT id,
        destination_id,
        launch_date,
        created_at,
-       updated_at
+       updated_at,
+       status,
+       version,
+       pii_key_id,
+       pii_data_key,
+       first_name_encrypted,
+       last_name_encrypted,
+       gender_encrypted,
+       birthday_encrypted,
+       first_name_index,
+       last_name_index,
+       anonymized_at
 FROM bookings
 WHERE id = $1
 `
@@ -98,10 +567,138 @@ func (q *Queries) GetBookingByID(ctx context.Context, id uuid.UUID) (Booking, er
 		&i.LaunchDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Status,
+		&i.Version,
+		&i.PiiKeyID,
+		&i.PiiDataKey,
+		&i.FirstNameEncrypted,
+		&i.LastNameEncrypted,
+		&i.GenderEncrypted,
+		&i.BirthdayEncrypted,
+		&i.FirstNameIndex,
+		&i.LastNameIndex,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
 
+const getCacheEntry = `-- name: GetCacheEntry :one
+SELECT key,
+       value,
+       stored_at,
+       expires_at
+FROM cache_entries
+WHERE key = $1
+  AND (expires_at IS NULL OR expires_at > $2)
+`
+
+type GetCacheEntryParams struct {
+	Key string
+	Now pgtype.Timestamptz
+}
+
+func (q *Queries) GetCacheEntry(ctx context.Context, arg GetCacheEntryParams) (CacheEntry, error) {
+	row := q.db.QueryRow(ctx, getCacheEntry, arg.Key, arg.Now)
+	var i CacheEntry
+	err := row.Scan(
+		&i.Key,
+		&i.Value,
+		&i.StoredAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const listBlackouts = `-- name: ListBlackouts :many
+SELECT id,
+       launch_pad_id,
+       starts_at,
+       ends_at,
+       reason,
+       created_at,
+       updated_at
+FROM blackouts
+WHERE ($1::text IS NULL OR launch_pad_id IS NULL OR launch_pad_id = $1)
+  AND ends_at > coalesce($2, '-infinity'::timestamptz)
+  AND starts_at < coalesce($3, 'infinity'::timestamptz)
+ORDER BY starts_at
+`
+
+type ListBlackoutsParams struct {
+	LaunchPadID pgtype.Text
+	StartsAfter pgtype.Timestamptz
+	EndsBefore  pgtype.Timestamptz
+}
+
+func (q *Queries) ListBlackouts(ctx context.Context, arg ListBlackoutsParams) ([]Blackout, error) {
+	rows, err := q.db.Query(ctx, listBlackouts, arg.LaunchPadID, arg.StartsAfter, arg.EndsBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Blackout
+	for rows.Next() {
+		var i Blackout
+		if err := rows.Scan(
+			&i.ID,
+			&i.LaunchPadID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Reason,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookingAuditEntries = `-- name: ListBookingAuditEntries :many
+SELECT id,
+       booking_id,
+       action,
+       actor,
+       occurred_at,
+       before,
+       after
+FROM booking_audit
+WHERE booking_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListBookingAuditEntries(ctx context.Context, bookingID uuid.UUID) ([]BookingAudit, error) {
+	rows, err := q.db.Query(ctx, listBookingAuditEntries, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookingAudit
+	for rows.Next() {
+		var i BookingAudit
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookingID,
+			&i.Action,
+			&i.Actor,
+			&i.OccurredAt,
+			&i.Before,
+			&i.After,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listBookings = `-- name: ListBookings :many
 SELECT id,
        first_name,
@@ -112,21 +709,38 @@ SELECT id,
        destination_id,
        launch_date,
        created_at,
-       updated_at
+       updated_at,
+       status,
+       version,
+       pii_key_id,
+       pii_data_key,
+       first_name_encrypted,
+       last_name_encrypted,
+       gender_encrypted,
+       birthday_encrypted,
+       first_name_index,
+       last_name_index,
+       anonymized_at
 FROM bookings
 WHERE launch_date = coalesce($1, launch_date)
   AND launch_pad_id = coalesce($2, launch_pad_id)
   AND destination_id = coalesce($3, destination_id)
-ORDER BY created_at DESC LIMIT $5
-OFFSET $4
+  AND status = coalesce($4, status)
+  AND ($5::bytea IS NULL OR first_name_index = $5)
+  AND ($6::bytea IS NULL OR last_name_index = $6)
+ORDER BY created_at DESC, id LIMIT $8
+OFFSET $7
 `
 
 type ListBookingsParams struct {
-	LaunchDate    pgtype.Timestamptz
-	LaunchPadID   pgtype.Text
-	DestinationID pgtype.Text
-	Offset        int32
-	Limit         int32
+	LaunchDate     pgtype.Timestamptz
+	LaunchPadID    pgtype.Text
+	DestinationID  pgtype.Text
+	Status         pgtype.Text
+	FirstNameIndex []byte
+	LastNameIndex  []byte
+	Offset         int32
+	Limit          int32
 }
 
 func (q *Queries) ListBookings(ctx context.Context, arg ListBookingsParams) ([]Booking, error) {
@@ -134,6 +748,9 @@ func (q *Queries) ListBookings(ctx context.Context, arg ListBookingsParams) ([]B
 		arg.LaunchDate,
 		arg.LaunchPadID,
 		arg.DestinationID,
+		arg.Status,
+		arg.FirstNameIndex,
+		arg.LastNameIndex,
 		arg.Offset,
 		arg.Limit,
 	)
@@ -155,6 +772,262 @@ func (q *Queries) ListBookings(ctx context.Context, arg ListBookingsParams) ([]B
 			&i.LaunchDate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Status,
+			&i.Version,
+			&i.PiiKeyID,
+			&i.PiiDataKey,
+			&i.FirstNameEncrypted,
+			&i.LastNameEncrypted,
+			&i.GenderEncrypted,
+			&i.BirthdayEncrypted,
+			&i.FirstNameIndex,
+			&i.LastNameIndex,
+			&i.AnonymizedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookingsToAnonymize = `-- name: ListBookingsToAnonymize :many
+SELECT id, launch_date
+FROM bookings
+WHERE anonymized_at IS NULL
+  AND launch_date < $1
+ORDER BY launch_date, id LIMIT $3
+OFFSET $2
+`
+
+type ListBookingsToAnonymizeParams struct {
+	LaunchedBefore pgtype.Timestamptz
+	Offset         int32
+	Limit          int32
+}
+
+type ListBookingsToAnonymizeRow struct {
+	ID         uuid.UUID
+	LaunchDate pgtype.Timestamptz
+}
+
+func (q *Queries) ListBookingsToAnonymize(ctx context.Context, arg ListBookingsToAnonymizeParams) ([]ListBookingsToAnonymizeRow, error) {
+	rows, err := q.db.Query(ctx, listBookingsToAnonymize, arg.LaunchedBefore, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBookingsToAnonymizeRow
+	for rows.Next() {
+		var i ListBookingsToAnonymizeRow
+		if err := rows.Scan(&i.ID, &i.LaunchDate); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookingsToPurge = `-- name: ListBookingsToPurge :many
+SELECT id, launch_date
+FROM bookings
+WHERE launch_date < $1
+ORDER BY launch_date, id LIMIT $3
+OFFSET $2
+`
+
+type ListBookingsToPurgeParams struct {
+	LaunchedBefore pgtype.Timestamptz
+	Offset         int32
+	Limit          int32
+}
+
+type ListBookingsToPurgeRow struct {
+	ID         uuid.UUID
+	LaunchDate pgtype.Timestamptz
+}
+
+func (q *Queries) ListBookingsToPurge(ctx context.Context, arg ListBookingsToPurgeParams) ([]ListBookingsToPurgeRow, error) {
+	rows, err := q.db.Query(ctx, listBookingsToPurge, arg.LaunchedBefore, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBookingsToPurgeRow
+	for rows.Next() {
+		var i ListBookingsToPurgeRow
+		if err := rows.Scan(&i.ID, &i.LaunchDate); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookingsToReencrypt = `-- name: ListBookingsToReencrypt :many
+SELECT id,
+       first_name,
+       last_name,
+       gender,
+       birthday,
+       launch_pad_id,
+       destination_id,
+       launch_date,
+       created_at,
+       updated_at,
+       status,
+       version,
+       pii_key_id,
+       pii_data_key,
+       first_name_encrypted,
+       last_name_encrypted,
+       gender_encrypted,
+       birthday_encrypted,
+       first_name_index,
+       last_name_index,
+       anonymized_at
+FROM bookings
+WHERE pii_key_id IS DISTINCT FROM $1::text
+  AND anonymized_at IS NULL
+ORDER BY id
+LIMIT $2 FOR UPDATE SKIP LOCKED
+`
+
+type ListBookingsToReencryptParams struct {
+	ActiveKeyID string
+	Limit       int32
+}
+
+func (q *Queries) ListBookingsToReencrypt(ctx context.Context, arg ListBookingsToReencryptParams) ([]Booking, error) {
+	rows, err := q.db.Query(ctx, listBookingsToReencrypt, arg.ActiveKeyID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Booking
+	for rows.Next() {
+		var i Booking
+		if err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.Gender,
+			&i.Birthday,
+			&i.LaunchPadID,
+			&i.DestinationID,
+			&i.LaunchDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.Version,
+			&i.PiiKeyID,
+			&i.PiiDataKey,
+			&i.FirstNameEncrypted,
+			&i.LastNameEncrypted,
+			&i.GenderEncrypted,
+			&i.BirthdayEncrypted,
+			&i.FirstNameIndex,
+			&i.LastNameIndex,
+			&i.AnonymizedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCacheEntries = `-- name: ListCacheEntries :many
+SELECT key,
+       stored_at,
+       expires_at
+FROM cache_entries
+WHERE starts_with(key, $1::text)
+  AND (expires_at IS NULL OR expires_at > $2)
+ORDER BY key
+`
+
+type ListCacheEntriesParams struct {
+	Prefix string
+	Now    pgtype.Timestamptz
+}
+
+type ListCacheEntriesRow struct {
+	Key       string
+	StoredAt  pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+func (q *Queries) ListCacheEntries(ctx context.Context, arg ListCacheEntriesParams) ([]ListCacheEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listCacheEntries, arg.Prefix, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCacheEntriesRow
+	for rows.Next() {
+		var i ListCacheEntriesRow
+		if err := rows.Scan(&i.Key, &i.StoredAt, &i.ExpiresAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listClosures = `-- name: ListClosures :many
+SELECT id,
+       launch_pad_id,
+       starts_at,
+       ends_at,
+       summary,
+       source,
+       created_at
+FROM closures
+WHERE launch_pad_id = $1
+  AND starts_at < $2
+  AND ends_at > $3
+ORDER BY starts_at
+`
+
+type ListClosuresParams struct {
+	LaunchPadID string
+	EndsBefore  pgtype.Timestamptz
+	StartsAfter pgtype.Timestamptz
+}
+
+func (q *Queries) ListClosures(ctx context.Context, arg ListClosuresParams) ([]Closure, error) {
+	rows, err := q.db.Query(ctx, listClosures, arg.LaunchPadID, arg.EndsBefore, arg.StartsAfter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Closure
+	for rows.Next() {
+		var i Closure
+		if err := rows.Scan(
+			&i.ID,
+			&i.LaunchPadID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Summary,
+			&i.Source,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -165,3 +1038,307 @@ func (q *Queries) ListBookings(ctx context.Context, arg ListBookingsParams) ([]B
 	}
 	return items, nil
 }
+
+const lockLaunchSlot = `-- name: LockLaunchSlot :exec
+SELECT pg_advisory_xact_lock(hashtextextended($1::text, 0))
+`
+
+func (q *Queries) LockLaunchSlot(ctx context.Context, key string) error {
+	_, err := q.db.Exec(ctx, lockLaunchSlot, key)
+	return err
+}
+
+const markOutboxEventFailed = `-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events
+SET status       = $1,
+    attempts     = attempts + 1,
+    last_error   = $2,
+    available_at = now() + make_interval(secs => $3::float8),
+    locked_until = NULL
+WHERE id = $4
+`
+
+type MarkOutboxEventFailedParams struct {
+	Status         string
+	LastError      string
+	RetryInSeconds float64
+	ID             uuid.UUID
+}
+
+func (q *Queries) MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error {
+	_, err := q.db.Exec(ctx, markOutboxEventFailed,
+		arg.Status,
+		arg.LastError,
+		arg.RetryInSeconds,
+		arg.ID,
+	)
+	return err
+}
+
+const markOutboxEventPublished = `-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+SET status       = 'published',
+    attempts     = attempts + 1,
+    published_at = now(),
+    locked_until = NULL
+WHERE id = $1
+`
+
+func (q *Queries) MarkOutboxEventPublished(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markOutboxEventPublished, id)
+	return err
+}
+
+const notifyCacheInvalidation = `-- name: NotifyCacheInvalidation :exec
+SELECT pg_notify('cache_invalidation', $1)
+`
+
+func (q *Queries) NotifyCacheInvalidation(ctx context.Context, payload string) error {
+	_, err := q.db.Exec(ctx, notifyCacheInvalidation, payload)
+	return err
+}
+
+const purgeBooking = `-- name: PurgeBooking :one
+DELETE
+FROM bookings
+WHERE id = $1
+RETURNING id,
+    first_name,
+    last_name,
+    gender,
+    birthday,
+    launch_pad_id,
+    destination_id,
+    launch_date,
+    created_at,
+    updated_at,
+    status,
+    version,
+    pii_key_id,
+    pii_data_key,
+    first_name_encrypted,
+    last_name_encrypted,
+    gender_encrypted,
+    birthday_encrypted,
+    first_name_index,
+    last_name_index,
+    anonymized_at
+`
+
+func (q *Queries) PurgeBooking(ctx context.Context, id uuid.UUID) (Booking, error) {
+	row := q.db.QueryRow(ctx, purgeBooking, id)
+	var i Booking
+	err := row.Scan(
+		&i.ID,
+		&i.FirstName,
+		&i.LastName,
+		&i.Gender,
+		&i.Birthday,
+		&i.LaunchPadID,
+		&i.DestinationID,
+		&i.LaunchDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.Version,
+		&i.PiiKeyID,
+		&i.PiiDataKey,
+		&i.FirstNameEncrypted,
+		&i.LastNameEncrypted,
+		&i.GenderEncrypted,
+		&i.BirthdayEncrypted,
+		&i.FirstNameIndex,
+		&i.LastNameIndex,
+		&i.AnonymizedAt,
+	)
+	return i, err
+}
+
+const setBookingPII = `-- name: SetBookingPII :exec
+UPDATE bookings
+SET first_name           = NULL,
+    last_name            = NULL,
+    gender               = NULL,
+    birthday             = NULL,
+    pii_key_id           = $1,
+    pii_data_key         = $2,
+    first_name_encrypted = $3,
+    last_name_encrypted  = $4,
+    gender_encrypted     = $5,
+    birthday_encrypted   = $6,
+    first_name_index     = $7,
+    last_name_index      = $8
+WHERE id = $9
+`
+
+type SetBookingPIIParams struct {
+	PiiKeyID           pgtype.Text
+	PiiDataKey         []byte
+	FirstNameEncrypted []byte
+	LastNameEncrypted  []byte
+	GenderEncrypted    []byte
+	BirthdayEncrypted  []byte
+	FirstNameIndex     []byte
+	LastNameIndex      []byte
+	ID                 uuid.UUID
+}
+
+func (q *Queries) SetBookingPII(ctx context.Context, arg SetBookingPIIParams) error {
+	_, err := q.db.Exec(ctx, setBookingPII,
+		arg.PiiKeyID,
+		arg.PiiDataKey,
+		arg.FirstNameEncrypted,
+		arg.LastNameEncrypted,
+		arg.GenderEncrypted,
+		arg.BirthdayEncrypted,
+		arg.FirstNameIndex,
+		arg.LastNameIndex,
+		arg.ID,
+	)
+	return err
+}
+
+const setCacheEntry = `-- name: SetCacheEntry :exec
+INSERT INTO cache_entries (key, value, stored_at, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (key) DO UPDATE SET value      = excluded.value,
+                                stored_at  = excluded.stored_at,
+                                expires_at = excluded.expires_at
+`
+
+type SetCacheEntryParams struct {
+	Key       string
+	Value     []byte
+	StoredAt  pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+func (q *Queries) SetCacheEntry(ctx context.Context, arg SetCacheEntryParams) error {
+	_, err := q.db.Exec(ctx, setCacheEntry,
+		arg.Key,
+		arg.Value,
+		arg.StoredAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const setLockTimeout = `-- name: SetLockTimeout :exec
+SELECT set_config('lock_timeout', $1::text, true)
+`
+
+func (q *Queries) SetLockTimeout(ctx context.Context, timeout string) error {
+	_, err := q.db.Exec(ctx, setLockTimeout, timeout)
+	return err
+}
+
+const updateBlackout = `-- name: UpdateBlackout :one
+UPDATE blackouts
+SET launch_pad_id = $2,
+    starts_at     = $3,
+    ends_at       = $4,
+    reason        = $5,
+    updated_at    = $6
+WHERE id = $1
+RETURNING id
+`
+
+type UpdateBlackoutParams struct {
+	ID          uuid.UUID
+	LaunchPadID pgtype.Text
+	StartsAt    pgtype.Timestamptz
+	EndsAt      pgtype.Timestamptz
+	Reason      string
+	UpdatedAt   pgtype.Timestamptz
+}
+
+func (q *Queries) UpdateBlackout(ctx context.Context, arg UpdateBlackoutParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, updateBlackout,
+		arg.ID,
+		arg.LaunchPadID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Reason,
+		arg.UpdatedAt,
+	)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateBooking = `-- name: UpdateBooking :one
+UPDATE bookings
+SET first_name           = NULL,
+    last_name            = NULL,
+    gender               = NULL,
+    birthday             = NULL,
+    pii_key_id           = $1,
+    pii_data_key         = $2,
+    first_name_encrypted = $3,
+    last_name_encrypted  = $4,
+    gender_encrypted     = $5,
+    birthday_encrypted   = $6,
+    first_name_index     = $7,
+    last_name_index      = $8,
+    anonymized_at        = NULL,
+    updated_at           = $9,
+    version              = version + 1
+WHERE id = $10
+  AND version = $11
+RETURNING id
+`
+
+type UpdateBookingParams struct {
+	PiiKeyID           pgtype.Text
+	PiiDataKey         []byte
+	FirstNameEncrypted []byte
+	LastNameEncrypted  []byte
+	GenderEncrypted    []byte
+	BirthdayEncrypted  []byte
+	FirstNameIndex     []byte
+	LastNameIndex      []byte
+	UpdatedAt          pgtype.Timestamptz
+	ID                 uuid.UUID
+	Version            int32
+}
+
+func (q *Queries) UpdateBooking(ctx context.Context, arg UpdateBookingParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, updateBooking,
+		arg.PiiKeyID,
+		arg.PiiDataKey,
+		arg.FirstNameEncrypted,
+		arg.LastNameEncrypted,
+		arg.GenderEncrypted,
+		arg.BirthdayEncrypted,
+		arg.FirstNameIndex,
+		arg.LastNameIndex,
+		arg.UpdatedAt,
+		arg.ID,
+		arg.Version,
+	)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateBookingStatus = `-- name: UpdateBookingStatus :one
+UPDATE bookings
+SET status     = $2,
+    updated_at = $3,
+    version    = version + 1
+WHERE id = $1
+RETURNING id
+`
+
+type UpdateBookingStatusParams struct {
+	ID        uuid.UUID
+	Status    string
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) UpdateBookingStatus(ctx context.Context, arg UpdateBookingStatusParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, updateBookingStatus, arg.ID, arg.Status, arg.UpdatedAt)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database/queries"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+func (q *pg) ListBookingsToAnonymize(ctx context.Context, launchedBefore time.Time, pagination models.Pagination) ([]models.ExpiredBooking, error) {
+	rows, err := q.queries.ListBookingsToAnonymize(ctx, queries.ListBookingsToAnonymizeParams{
+		LaunchedBefore: pgtype.Timestamptz{Time: launchedBefore, Valid: true},
+		Offset:         int32(pagination.Offset),
+		Limit:          int32(pagination.Limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list bookings to anonymize: %w", err)
+	}
+	var result []models.ExpiredBooking
+	for _, row := range rows {
+		result = append(result, models.ExpiredBooking{
+			ID:         row.ID,
+			LaunchDate: row.LaunchDate.Time,
+		})
+	}
+	return result, nil
+}
+
+func (q *pg) ListBookingsToPurge(ctx context.Context, launchedBefore time.Time, pagination models.Pagination) ([]models.ExpiredBooking, error) {
+	rows, err := q.queries.ListBookingsToPurge(ctx, queries.ListBookingsToPurgeParams{
+		LaunchedBefore: pgtype.Timestamptz{Time: launchedBefore, Valid: true},
+		Offset:         int32(pagination.Offset),
+		Limit:          int32(pagination.Limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list bookings to purge: %w", err)
+	}
+	var result []models.ExpiredBooking
+	for _, row := range rows {
+		result = append(result, models.ExpiredBooking{
+			ID:         row.ID,
+			LaunchDate: row.LaunchDate.Time,
+		})
+	}
+	return result, nil
+}
+
+func (q *pg) AnonymizeBooking(ctx context.Context, id uuid.UUID, at time.Time) (*models.Booking, error) {
+	var result *models.Booking
+	err := q.inTx(ctx, func(txQueries *queries.Queries) error {
+		stored, err := txQueries.AnonymizeBooking(ctx, queries.AnonymizeBookingParams{
+			AnonymizedAt: pgtype.Timestamptz{Time: at, Valid: true},
+			ID:           id,
+		})
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			// Deleted, or anonymized in the meantime
+			return nil
+		case err != nil:
+			return fmt.Errorf("unable to anonymize booking: %w", err)
+		}
+		booking := bookingFromRow(stored)
+		result = &booking
+		return createBookingEvent(ctx, txQueries, models.EventBookingAnonymized, booking)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (q *pg) PurgeBooking(ctx context.Context, id uuid.UUID) error {
+	return q.inTx(ctx, func(txQueries *queries.Queries) error {
+		stored, err := txQueries.PurgeBooking(ctx, id)
+		if err != nil {
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+				return ErrNotFound
+			default:
+				return fmt.Errorf("unable to purge booking: %w", err)
+			}
+		}
+		// The event is stored without the personal data, which does not need the keys of the booking
+		return createBookingEvent(ctx, txQueries, models.EventBookingPurged, withoutPersonalData(bookingFromRow(stored)))
+	})
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+func TestRetention(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close(context.Background())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	newBooking := func(launchDate time.Time) models.Booking {
+		booking := models.Booking{
+			ID:            uuid.New(),
+			Status:        models.BookingStatusConfirmed,
+			FirstName:     "John",
+			LastName:      "Doe",
+			Gender:        "male",
+			Birthday:      now.AddDate(-25, 0, 0),
+			LaunchPadID:   "LP-001",
+			DestinationID: "DS-001",
+			LaunchDate:    launchDate,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			Version:       1,
+		}
+		require.NoError(t, db.Create(ctx, booking))
+		require.NoError(t, db.AppendAudit(ctx, models.AuditEntry{
+			BookingID:  booking.ID,
+			Action:     models.AuditActionCreated,
+			Actor:      "alice",
+			OccurredAt: now,
+			After:      &booking,
+		}))
+		return booking
+	}
+	old := newBooking(now.AddDate(-2, 0, 0))
+	deleted := newBooking(now.AddDate(-1, 0, 0))
+	require.NoError(t, db.Delete(ctx, deleted.ID, deleted.Version))
+	require.NoError(t, db.AppendAudit(ctx, models.AuditEntry{
+		BookingID:  deleted.ID,
+		Action:     models.AuditActionDeleted,
+		Actor:      "alice",
+		OccurredAt: now,
+		Before:     &deleted,
+	}))
+	recent := newBooking(now.AddDate(0, -1, 0))
+
+	anonymizeBefore := now.AddDate(0, -6, 0)
+	// Only the bookings are anonymized, the history of the deleted one was stored without the personal data
+	toAnonymize, err := db.ListBookingsToAnonymize(ctx, anonymizeBefore, models.Pagination{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, toAnonymize, 1)
+	assert.Equal(t, old.ID, toAnonymize[0].ID)
+	page, err := db.ListBookingsToAnonymize(ctx, anonymizeBefore, models.Pagination{Offset: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page)
+
+	anonymized, err := db.AnonymizeBooking(ctx, old.ID, now)
+	require.NoError(t, err)
+	require.NotNil(t, anonymized)
+	assert.Empty(t, anonymized.FirstName)
+	assert.Equal(t, 2, anonymized.Version)
+	got, err := db.GetByID(ctx, old.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.LastName)
+	assert.True(t, got.Birthday.IsZero())
+	assert.Equal(t, old.LaunchPadID, got.LaunchPadID)
+	var keyID *string
+	require.NoError(t, db.(*pg).pool.QueryRow(ctx, "SELECT pii_key_id FROM bookings WHERE id = $1", old.ID).Scan(&keyID))
+	assert.Nil(t, keyID)
+	// Already anonymized
+	again, err := db.AnonymizeBooking(ctx, old.ID, now)
+	require.NoError(t, err)
+	assert.Nil(t, again)
+
+	gone, err := db.AnonymizeBooking(ctx, deleted.ID, now)
+	require.NoError(t, err)
+	assert.Nil(t, gone)
+	toAnonymize, err = db.ListBookingsToAnonymize(ctx, anonymizeBefore, models.Pagination{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, toAnonymize)
+
+	// The history and the events keep everything but the personal data
+	for _, id := range []uuid.UUID{old.ID, deleted.ID} {
+		history, err := db.ListAudit(ctx, id)
+		require.NoError(t, err)
+		require.NotEmpty(t, history)
+		for _, entry := range history {
+			for _, snapshot := range []*models.Booking{entry.Before, entry.After} {
+				if snapshot != nil {
+					assert.Empty(t, snapshot.FirstName)
+					assert.True(t, snapshot.Birthday.IsZero())
+					assert.Equal(t, id, snapshot.ID)
+				}
+			}
+		}
+		var withPII int
+		require.NoError(t, db.(*pg).pool.QueryRow(ctx,
+			"SELECT count(*) FROM outbox_events WHERE booking_id = $1 AND payload ->> 'first_name' IS NOT NULL", id).
+			Scan(&withPII))
+		assert.Zero(t, withPII)
+	}
+	// The history is append-only, the retention job cannot change it either
+	_, err = db.(*pg).pool.Exec(ctx, "UPDATE booking_audit SET after = NULL WHERE booking_id = $1", recent.ID)
+	assert.ErrorContains(t, err, "append-only")
+	tx, err := db.(*pg).pool.Begin(ctx)
+	require.NoError(t, err)
+	_, err = tx.Exec(ctx, "SELECT set_config('bookings.redact_audit', 'on', true)")
+	require.NoError(t, err)
+	_, err = tx.Exec(ctx, "UPDATE booking_audit SET after = after - 'first_name' WHERE booking_id = $1", recent.ID)
+	assert.ErrorContains(t, err, "append-only")
+	require.NoError(t, tx.Rollback(ctx))
+
+	toPurge, err := db.ListBookingsToPurge(ctx, now.AddDate(0, -2, 0), models.Pagination{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, toPurge, 1)
+	assert.Equal(t, old.ID, toPurge[0].ID)
+	require.NoError(t, db.PurgeBooking(ctx, old.ID))
+	_, err = db.GetByID(ctx, old.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, db.PurgeBooking(ctx, old.ID), ErrNotFound)
+	history, err := db.ListAudit(ctx, old.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, history)
+	var purgedEvents int
+	require.NoError(t, db.(*pg).pool.QueryRow(ctx,
+		"SELECT count(*) FROM outbox_events WHERE booking_id = $1 AND event_type = $2", old.ID, models.EventBookingPurged).
+		Scan(&purgedEvents))
+	assert.Equal(t, 1, purgedEvents)
+
+	// Editing an anonymized booking makes it due for anonymization again
+	anonymized, err = db.AnonymizeBooking(ctx, recent.ID, now)
+	require.NoError(t, err)
+	recent.FirstName = "Jane"
+	recent.Version = anonymized.Version
+	require.NoError(t, db.Update(ctx, recent))
+	toAnonymize, err = db.ListBookingsToAnonymize(ctx, now, models.Pagination{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, toAnonymize, 1)
+	assert.Equal(t, recent.ID, toAnonymize[0].ID)
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database/queries"
+)
+
+// IsolationLevel of a transaction
+type IsolationLevel string
+
+const (
+	IsolationReadCommitted  IsolationLevel = "read-committed"
+	IsolationRepeatableRead IsolationLevel = "repeatable-read"
+	IsolationSerializable   IsolationLevel = "serializable"
+)
+
+func ParseIsolationLevel(value string) (IsolationLevel, error) {
+	switch IsolationLevel(value) {
+	case IsolationReadCommitted, IsolationRepeatableRead, IsolationSerializable:
+		return IsolationLevel(value), nil
+	default:
+		return "", fmt.Errorf("unknown isolation level %q", value)
+	}
+}
+
+func (l IsolationLevel) pgx() pgx.TxIsoLevel {
+	switch l {
+	case IsolationRepeatableRead:
+		return pgx.RepeatableRead
+	case IsolationSerializable:
+		return pgx.Serializable
+	default:
+		return pgx.ReadCommitted
+	}
+}
+
+// TxConfig configures the transactions of WithTx
+type TxConfig struct {
+	Isolation IsolationLevel
+	// MaxRetries is the number of times a transaction is run again after a serialization failure or a deadlock
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled on every retry
+	RetryBackoff time.Duration
+}
+
+// DefaultTxConfig runs transactions with the default isolation of Postgres
+func DefaultTxConfig() TxConfig {
+	return TxConfig{
+		Isolation:    IsolationReadCommitted,
+		MaxRetries:   3,
+		RetryBackoff: 10 * time.Millisecond,
+	}
+}
+
+// TxOption overrides the TxConfig of a database or of a single transaction
+type TxOption func(config *TxConfig)
+
+func WithIsolation(level IsolationLevel) TxOption {
+	return func(config *TxConfig) {
+		config.Isolation = level
+	}
+}
+
+func WithMaxRetries(retries int) TxOption {
+	return func(config *TxConfig) {
+		config.MaxRetries = retries
+	}
+}
+
+func (q *pg) WithTx(ctx context.Context, fn func(tx Database) error, opts ...TxOption) error {
+	if q.tx != nil {
+		// Nested transactions run in a savepoint of the outer one, which is the one retried
+		return q.runTx(ctx, q.tx.Begin, fn)
+	}
+	config := q.txConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	begin := func(ctx context.Context) (pgx.Tx, error) {
+		return q.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: config.Isolation.pgx()})
+	}
+	backoff := config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := q.runTx(ctx, begin, fn)
+		if err == nil || !isRetriable(err) || attempt >= config.MaxRetries {
+			return err
+		}
+		log.WithError(err).WithField("attempt", attempt+1).Warn("transaction conflicted, retrying")
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// runTx runs fn in a transaction started by begin, committed if fn succeeds and rolled back otherwise
+func (q *pg) runTx(ctx context.Context, begin func(ctx context.Context) (pgx.Tx, error), fn func(tx Database) error) error {
+	tx, err := begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() {
+		// Rollback is a no-op after commit
+		_ = tx.Rollback(ctx)
+	}()
+	err = fn(&pg{
+		pool:     q.pool,
+		tx:       tx,
+		queries:  q.queries.WithTx(tx),
+		txConfig: q.txConfig,
+		keys:     q.keys,
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (q *pg) LockLaunchSlot(ctx context.Context, launchPadID string, launchDate time.Time, timeout time.Duration) error {
+	if q.tx == nil {
+		return ErrNoTransaction
+	}
+	// The timeout is local to the transaction
+	err := q.queries.SetLockTimeout(ctx, strconv.FormatInt(timeout.Milliseconds(), 10))
+	if err != nil {
+		return fmt.Errorf("unable to set lock timeout: %w", err)
+	}
+	err = q.queries.LockLaunchSlot(ctx, launchSlotKey(launchPadID, launchDate))
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.As(err, &pgErr) && pgErr.Code == "55P03": // lock_not_available
+		return ErrLockTimeout
+	case err != nil:
+		return fmt.Errorf("unable to lock launch slot: %w", err)
+	}
+	return nil
+}
+
+// launchSlotKey is hashed into the key of the advisory lock of the launch pad and day
+func launchSlotKey(launchPadID string, launchDate time.Time) string {
+	return "launch-slot:" + launchPadID + ":" + launchDate.UTC().Format("2006-01-02")
+}
+
+// inTx runs fn with the queries of a transaction, or of a savepoint when already in one,
+// committed if fn returns nil and rolled back otherwise. Unlike WithTx, it is not retried.
+func (q *pg) inTx(ctx context.Context, fn func(txQueries *queries.Queries) error) error {
+	var tx pgx.Tx
+	var err error
+	if q.tx != nil {
+		tx, err = q.tx.Begin(ctx)
+	} else {
+		tx, err = q.pool.Begin(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() {
+		// Rollback is a no-op after commit
+		_ = tx.Rollback(ctx)
+	}()
+	err = fn(q.queries.WithTx(tx))
+	if err != nil {
+		return err
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// isRetriable reports whether the transaction failed because of a concurrent one and can be run again
+func isRetriable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch pgErr.Code {
+	case "40001", // serialization_failure
+		"40P01": // deadlock_detected
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+func TestParseIsolationLevel(t *testing.T) {
+	level, err := ParseIsolationLevel("serializable")
+	assert.NoError(t, err)
+	assert.Equal(t, IsolationSerializable, level)
+
+	_, err = ParseIsolationLevel("snapshot")
+	assert.Error(t, err)
+}
+
+func TestIsRetriable(t *testing.T) {
+	assert.True(t, isRetriable(fmt.Errorf("unable to commit transaction: %w", &pgconn.PgError{Code: "40001"})))
+	assert.True(t, isRetriable(&pgconn.PgError{Code: "40P01"}))
+	assert.False(t, isRetriable(&pgconn.PgError{Code: "23505"}), "unique violations are not retried")
+	assert.False(t, isRetriable(errors.New("connection reset")))
+}
+
+func TestLockLaunchSlot_NoTransaction(t *testing.T) {
+	err := (&pg{}).LockLaunchSlot(context.Background(), "LP-001", time.Now(), time.Second)
+	assert.ErrorIs(t, err, ErrNoTransaction)
+}
+
+func TestLockLaunchSlot(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close(context.Background())
+	ctx := context.Background()
+	day := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+
+	err := db.WithTx(ctx, func(tx Database) error {
+		err := tx.LockLaunchSlot(ctx, "LP-001", day, time.Second)
+		require.NoError(t, err)
+
+		// Another transaction waits for the same launch pad and day, but not for another day
+		err = db.WithTx(ctx, func(other Database) error {
+			return other.LockLaunchSlot(ctx, "LP-001", day.Add(13*time.Hour), 50*time.Millisecond)
+		})
+		assert.ErrorIs(t, err, ErrLockTimeout)
+		return db.WithTx(ctx, func(other Database) error {
+			return other.LockLaunchSlot(ctx, "LP-001", day.AddDate(0, 0, 1), 50*time.Millisecond)
+		})
+	})
+	assert.NoError(t, err)
+
+	// The lock is released with the transaction
+	err = db.WithTx(ctx, func(tx Database) error {
+		return tx.LockLaunchSlot(ctx, "LP-001", day, 50*time.Millisecond)
+	})
+	assert.NoError(t, err)
+}
+
+func TestWithTx(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close(context.Background())
+	ctx := context.Background()
+
+	booking := func() models.Booking {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		return models.Booking{
+			ID:            uuid.New(),
+			Status:        models.BookingStatusConfirmed,
+			FirstName:     "John",
+			LastName:      "Doe",
+			Gender:        "male",
+			Birthday:      time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+			LaunchPadID:   "LP-001",
+			DestinationID: "DS-001",
+			LaunchDate:    time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC),
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			Version:       1,
+		}
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		committed := booking()
+		err := db.WithTx(ctx, func(tx Database) error {
+			return tx.Create(ctx, committed)
+		})
+		require.NoError(t, err)
+		_, err = db.GetByID(ctx, committed.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		rolledBack := booking()
+		err := db.WithTx(ctx, func(tx Database) error {
+			err := tx.Create(ctx, rolledBack)
+			require.NoError(t, err)
+			return errors.New("capacity exceeded")
+		})
+		assert.EqualError(t, err, "capacity exceeded")
+		_, err = db.GetByID(ctx, rolledBack.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("nested rollback", func(t *testing.T) {
+		outer, inner := booking(), booking()
+		err := db.WithTx(ctx, func(tx Database) error {
+			err := tx.Create(ctx, outer)
+			require.NoError(t, err)
+			err = tx.WithTx(ctx, func(tx Database) error {
+				err := tx.Create(ctx, inner)
+				require.NoError(t, err)
+				return errors.New("inner failed")
+			})
+			assert.Error(t, err)
+			return nil
+		})
+		require.NoError(t, err)
+		_, err = db.GetByID(ctx, outer.ID)
+		assert.NoError(t, err)
+		_, err = db.GetByID(ctx, inner.ID)
+		assert.ErrorIs(t, err, ErrNotFound, "the savepoint of the nested transaction is rolled back")
+	})
+
+	t.Run("retry on serialization failures", func(t *testing.T) {
+		retried := booking()
+		attempts := 0
+		err := db.WithTx(ctx, func(tx Database) error {
+			attempts++
+			err := tx.Create(ctx, retried)
+			require.NoError(t, err)
+			if attempts < 2 {
+				return &pgconn.PgError{Code: "40001"}
+			}
+			return nil
+		}, WithIsolation(IsolationSerializable))
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+		_, err = db.GetByID(ctx, retried.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		attempts := 0
+		err := db.WithTx(ctx, func(tx Database) error {
+			attempts++
+			return &pgconn.PgError{Code: "40P01"}
+		}, WithMaxRetries(1))
+		assert.True(t, isRetriable(err))
+		assert.Equal(t, 2, attempts)
+	})
+}
@@ -0,0 +1,217 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrUnknownKey is returned when a data key was encrypted with a key missing from the keyring
+var ErrUnknownKey = errors.New("unknown key")
+
+// KeySize is the size of the keys of the keyring and of the data keys, AES-256
+const KeySize = 32
+
+// Keyfile is the JSON file the keys are loaded from, the keys are base64 encoded
+type Keyfile struct {
+	// ActiveKeyID is the key new data keys are encrypted with
+	ActiveKeyID string `json:"active_key_id"`
+	// Keys are the keys by ID, a retired key is kept until no booking is encrypted with it anymore
+	Keys map[string]string `json:"keys"`
+	// BlindIndexKey is the HMAC key of the blind indexes. Changing it requires a new active key, so that
+	// reencrypting the bookings also recomputes their indexes.
+	BlindIndexKey string `json:"blind_index_key"`
+}
+
+// Keyring encrypts the data keys of the rows, each row has its own data key stored encrypted next to it
+// (envelope encryption), so rotating a key only reencrypts data keys
+type Keyring interface {
+	ActiveKeyID() string
+	// NewDataKey generates a data key encrypted with the active key
+	NewDataKey() (*DataKey, error)
+	// OpenDataKey decrypts a data key encrypted with the key keyID, it returns ErrUnknownKey if the key is missing
+	OpenDataKey(keyID string, encrypted []byte) (*DataKey, error)
+	// BlindIndex is a keyed hash of value ignoring case and extra spaces, equal values have equal indexes
+	// so they can be looked up without decrypting them
+	BlindIndex(value string) []byte
+}
+
+type keyring struct {
+	activeKeyID   string
+	keys          map[string]cipher.AEAD
+	blindIndexKey []byte
+}
+
+// NewKeyring returns a keyring of keys by ID, activeKeyID must be one of them
+func NewKeyring(activeKeyID string, keys map[string][]byte, blindIndexKey []byte) (Keyring, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active key %q: %w", activeKeyID, ErrUnknownKey)
+	}
+	if len(blindIndexKey) != KeySize {
+		return nil, fmt.Errorf("blind index key must be %d bytes, got %d", KeySize, len(blindIndexKey))
+	}
+	result := &keyring{
+		activeKeyID:   activeKeyID,
+		keys:          make(map[string]cipher.AEAD, len(keys)),
+		blindIndexKey: blindIndexKey,
+	}
+	for id, key := range keys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		result.keys[id] = aead
+	}
+	return result, nil
+}
+
+// GenerateKeyfile returns a Keyfile with a random key keyID, active, and a random blind index key
+func GenerateKeyfile(keyID string) (*Keyfile, error) {
+	if keyID == "" {
+		return nil, errors.New("key id is required")
+	}
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate key: %w", err)
+	}
+	blindIndexKey := make([]byte, KeySize)
+	_, err = rand.Read(blindIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate blind index key: %w", err)
+	}
+	return &Keyfile{
+		ActiveKeyID:   keyID,
+		Keys:          map[string]string{keyID: base64.StdEncoding.EncodeToString(key)},
+		BlindIndexKey: base64.StdEncoding.EncodeToString(blindIndexKey),
+	}, nil
+}
+
+// LoadKeyfile returns the keyring of the Keyfile at path
+func LoadKeyfile(path string) (Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read keyfile: %w", err)
+	}
+	var keyfile Keyfile
+	err = json.Unmarshal(data, &keyfile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse keyfile: %w", err)
+	}
+	keys := make(map[string][]byte, len(keyfile.Keys))
+	for id, encoded := range keyfile.Keys {
+		keys[id], err = base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode key %q: %w", id, err)
+		}
+	}
+	blindIndexKey, err := base64.StdEncoding.DecodeString(keyfile.BlindIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode blind index key: %w", err)
+	}
+	return NewKeyring(keyfile.ActiveKeyID, keys, blindIndexKey)
+}
+
+func (k *keyring) ActiveKeyID() string {
+	return k.activeKeyID
+}
+
+func (k *keyring) NewDataKey() (*DataKey, error) {
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate data key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := seal(k.keys[k.activeKeyID], key, []byte(k.activeKeyID))
+	if err != nil {
+		return nil, fmt.Errorf("unable to encrypt data key: %w", err)
+	}
+	return &DataKey{KeyID: k.activeKeyID, Encrypted: encrypted, aead: aead}, nil
+}
+
+func (k *keyring) OpenDataKey(keyID string, encrypted []byte) (*DataKey, error) {
+	keyAEAD, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key %q: %w", keyID, ErrUnknownKey)
+	}
+	key, err := open(keyAEAD, encrypted, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt data key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &DataKey{KeyID: keyID, Encrypted: encrypted, aead: aead}, nil
+}
+
+func (k *keyring) BlindIndex(value string) []byte {
+	mac := hmac.New(sha256.New, k.blindIndexKey)
+	mac.Write([]byte(strings.ToLower(strings.Join(strings.Fields(value), " "))))
+	return mac.Sum(nil)
+}
+
+// DataKey encrypts the values of a single row
+type DataKey struct {
+	// KeyID is the key of the keyring the data key is encrypted with
+	KeyID string
+	// Encrypted is the data key encrypted with the key KeyID, stored next to the values
+	Encrypted []byte
+	aead      cipher.AEAD
+}
+
+// Encrypt encrypts plaintext with AES-GCM. The same associatedData, e.g. the ID of the row and the name of the column,
+// must be given to decrypt it, so an encrypted value cannot be moved to another row or column.
+func (k *DataKey) Encrypt(plaintext []byte, associatedData []byte) ([]byte, error) {
+	return seal(k.aead, plaintext, associatedData)
+}
+
+// Decrypt decrypts a value of Encrypt
+func (k *DataKey) Decrypt(ciphertext []byte, associatedData []byte) ([]byte, error) {
+	return open(k.aead, ciphertext, associatedData)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal returns the random nonce followed by the ciphertext
+func seal(aead cipher.AEAD, plaintext []byte, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func open(aead cipher.AEAD, ciphertext []byte, associatedData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, associatedData)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,131 @@
+package encryption_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/encryption"
+)
+
+var (
+	oldKey        = bytes.Repeat([]byte{1}, encryption.KeySize)
+	newKey        = bytes.Repeat([]byte{2}, encryption.KeySize)
+	blindIndexKey = bytes.Repeat([]byte{3}, encryption.KeySize)
+)
+
+func TestDataKey(t *testing.T) {
+	keyring, err := encryption.NewKeyring("old", map[string][]byte{"old": oldKey}, blindIndexKey)
+	require.NoError(t, err)
+
+	dataKey, err := keyring.NewDataKey()
+	require.NoError(t, err)
+	assert.Equal(t, "old", dataKey.KeyID)
+	ciphertext, err := dataKey.Encrypt([]byte("John"), []byte("row-1/first_name"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(ciphertext), "John")
+
+	opened, err := keyring.OpenDataKey(dataKey.KeyID, dataKey.Encrypted)
+	require.NoError(t, err)
+	plaintext, err := opened.Decrypt(ciphertext, []byte("row-1/first_name"))
+	require.NoError(t, err)
+	assert.Equal(t, "John", string(plaintext))
+
+	// A value moved to another row or column cannot be decrypted
+	_, err = opened.Decrypt(ciphertext, []byte("row-2/first_name"))
+	assert.Error(t, err)
+	_, err = opened.Decrypt(ciphertext[1:], []byte("row-1/first_name"))
+	assert.Error(t, err)
+}
+
+func TestRotation(t *testing.T) {
+	before, err := encryption.NewKeyring("old", map[string][]byte{"old": oldKey}, blindIndexKey)
+	require.NoError(t, err)
+	dataKey, err := before.NewDataKey()
+	require.NoError(t, err)
+
+	after, err := encryption.NewKeyring("new", map[string][]byte{"old": oldKey, "new": newKey}, blindIndexKey)
+	require.NoError(t, err)
+	_, err = after.OpenDataKey("old", dataKey.Encrypted)
+	assert.NoError(t, err)
+	rotated, err := after.NewDataKey()
+	require.NoError(t, err)
+	assert.Equal(t, "new", rotated.KeyID)
+
+	// Data keys of a removed key cannot be opened anymore
+	retired, err := encryption.NewKeyring("new", map[string][]byte{"new": newKey}, blindIndexKey)
+	require.NoError(t, err)
+	_, err = retired.OpenDataKey("old", dataKey.Encrypted)
+	assert.ErrorIs(t, err, encryption.ErrUnknownKey)
+	// Nor with another key under the same ID
+	_, err = retired.OpenDataKey("new", dataKey.Encrypted)
+	assert.Error(t, err)
+}
+
+func TestBlindIndex(t *testing.T) {
+	keyring, err := encryption.NewKeyring("old", map[string][]byte{"old": oldKey}, blindIndexKey)
+	require.NoError(t, err)
+
+	assert.Equal(t, keyring.BlindIndex("Mary Ann"), keyring.BlindIndex("  mary   ANN "))
+	assert.NotEqual(t, keyring.BlindIndex("Mary"), keyring.BlindIndex("Maria"))
+
+	other, err := encryption.NewKeyring("old", map[string][]byte{"old": oldKey}, newKey)
+	require.NoError(t, err)
+	assert.NotEqual(t, keyring.BlindIndex("Mary"), other.BlindIndex("Mary"))
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	_, err := encryption.NewKeyring("missing", map[string][]byte{"old": oldKey}, blindIndexKey)
+	assert.ErrorIs(t, err, encryption.ErrUnknownKey)
+	_, err = encryption.NewKeyring("old", map[string][]byte{"old": oldKey[:16]}, blindIndexKey)
+	assert.ErrorContains(t, err, "invalid key")
+	_, err = encryption.NewKeyring("old", map[string][]byte{"old": oldKey}, nil)
+	assert.ErrorContains(t, err, "blind index key")
+}
+
+func TestLoadKeyfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyfile.json")
+	data, err := json.Marshal(encryption.Keyfile{
+		ActiveKeyID:   "new",
+		Keys:          map[string]string{"old": base64.StdEncoding.EncodeToString(oldKey), "new": base64.StdEncoding.EncodeToString(newKey)},
+		BlindIndexKey: base64.StdEncoding.EncodeToString(blindIndexKey),
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	keyring, err := encryption.LoadKeyfile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", keyring.ActiveKeyID())
+
+	_, err = encryption.LoadKeyfile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "unable to read keyfile")
+}
+
+func TestGenerateKeyfile(t *testing.T) {
+	keyfile, err := encryption.GenerateKeyfile("2024-06")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06", keyfile.ActiveKeyID)
+	assert.Len(t, keyfile.Keys, 1)
+	assert.NotEqual(t, keyfile.Keys["2024-06"], keyfile.BlindIndexKey)
+
+	path := filepath.Join(t.TempDir(), "keyfile.json")
+	data, err := json.Marshal(keyfile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	keyring, err := encryption.LoadKeyfile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06", keyring.ActiveKeyID())
+
+	other, err := encryption.GenerateKeyfile("2024-06")
+	require.NoError(t, err)
+	assert.NotEqual(t, keyfile.Keys["2024-06"], other.Keys["2024-06"])
+
+	_, err = encryption.GenerateKeyfile("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	storedAt  time.Time
+	expiresAt time.Time
+}
+
+// Cache is a goroutine-safe cache with per entry TTL, the least recently used entry is evicted when it is full
+type Cache[K comparable, V any] struct {
+	clock      clockwork.Clock
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	// order has the most recently used entry at the front
+	order *list.List
+}
+
+// New creates a cache holding at most maxEntries entries, zero means unbounded
+func New[K comparable, V any](clock clockwork.Clock, maxEntries int) *Cache[K, V] {
+	return &Cache[K, V]{
+		clock:      clock,
+		maxEntries: maxEntries,
+		entries:    make(map[K]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns the value and the time it was stored, expired entries are never returned
+func (c *Cache[K, V]) Get(key K) (V, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, time.Time{}, false
+	}
+	e := element.Value.(*entry[K, V])
+	if !e.expiresAt.IsZero() && !c.clock.Now().Before(e.expiresAt) {
+		c.remove(element)
+		var zero V
+		return zero, time.Time{}, false
+	}
+	c.order.MoveToFront(element)
+	return e.value, e.storedAt, true
+}
+
+// Set stores the value for ttl, a ttl of zero or less never expires
+func (c *Cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	now := c.clock.Now()
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = now.Add(ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry[K, V])
+		e.value = value
+		e.storedAt = now
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&entry[K, V]{
+		key:       key,
+		value:     value,
+		storedAt:  now,
+		expiresAt: expiresAt,
+	})
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+// Range calls fn for the entries that did not expire, from the most to the least recently used, until fn returns false.
+// It does not change how recently the entries were used.
+func (c *Cache[K, V]) Range(fn func(key K, value V, storedAt time.Time) bool) {
+	now := c.clock.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for element := c.order.Front(); element != nil; element = element.Next() {
+		e := element.Value.(*entry[K, V])
+		if !e.expiresAt.IsZero() && !now.Before(e.expiresAt) {
+			continue
+		}
+		if !fn(e.key, e.value, e.storedAt) {
+			return
+		}
+	}
+}
+
+// Len returns the number of entries, including the expired ones that were not accessed since they expired
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *Cache[K, V]) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry[K, V]).key)
+}
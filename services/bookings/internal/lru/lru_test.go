@@ -0,0 +1,107 @@
+package lru
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_TTL(t *testing.T) {
+	now := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(now)
+	c := New[string, int](clock, 0)
+
+	c.Set("short", 1, time.Minute)
+	c.Set("forever", 2, 0)
+
+	value, storedAt, ok := c.Get("short")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, now, storedAt)
+
+	clock.Advance(time.Minute)
+	_, _, ok = c.Get("short")
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.Len(), "expired entries are removed when they are accessed")
+
+	clock.Advance(24 * time.Hour)
+	value, _, ok = c.Get("forever")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+}
+
+func TestCache_LRUEviction(t *testing.T) {
+	c := New[string, int](clockwork.NewFakeClock(), 2)
+
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	// Using a makes b the least recently used entry
+	_, _, ok := c.Get("a")
+	assert.True(t, ok)
+	c.Set("c", 3, 0)
+
+	_, _, ok = c.Get("b")
+	assert.False(t, ok)
+	_, _, ok = c.Get("a")
+	assert.True(t, ok)
+	_, _, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	// Updating an entry does not grow the cache
+	c.Set("a", 10, 0)
+	value, _, _ := c.Get("a")
+	assert.Equal(t, 10, value)
+	assert.Equal(t, 2, c.Len())
+
+	c.Delete("a")
+	_, _, ok = c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	c := New[string, int](clockwork.NewFakeClock(), 50)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("key-%d", j)
+				c.Set(key, j, time.Minute)
+				c.Get(key)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, c.Len(), 50)
+}
+
+func TestCache_Range(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	c := New[string, int](clock, 0)
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, 0)
+	clock.Advance(time.Minute)
+
+	var keys []string
+	c.Range(func(key string, value int, _ time.Time) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, []string{"c", "b"}, keys, "expired entries are skipped")
+
+	keys = nil
+	c.Range(func(key string, value int, _ time.Time) bool {
+		keys = append(keys, key)
+		return false
+	})
+	assert.Equal(t, []string{"c"}, keys)
+}
@@ -0,0 +1,300 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrSchemaBehind is returned when migrations are pending
+	ErrSchemaBehind = errors.New("database schema is behind")
+	// ErrDirty is returned when a migration failed halfway, it has to be fixed by hand
+	ErrDirty = errors.New("database schema is dirty")
+)
+
+// lockKey is the key of the advisory lock held while migrating, so replicas starting together do not race
+const lockKey = 7245810342
+
+// fileName matches the golang-migrate file names, e.g. 000001_create_bookings_table.up.sql
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a version of the schema, Down reverts Up
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is the version of the database schema compared to the embedded migrations
+type Status struct {
+	// Current is the version of the database, zero if no migration was applied
+	Current uint64
+	Dirty   bool
+	// Latest is the version of the last migration
+	Latest  uint64
+	Pending []Migration
+}
+
+// Migrator applies the migrations to the database. The version is kept in the schema_migrations table
+// of golang-migrate, so databases migrated with the migrate CLI can be migrated further and the other way around.
+type Migrator interface {
+	// Up applies the pending migrations and returns the number applied
+	Up(ctx context.Context) (int, error)
+	// Down reverts the last steps migrations and returns the number reverted
+	Down(ctx context.Context, steps int) (int, error)
+	Status(ctx context.Context) (Status, error)
+	// CheckUpToDate returns ErrSchemaBehind if migrations are pending and ErrDirty if a migration failed.
+	// A schema ahead of the migrations, e.g. during a rolling deploy, is accepted.
+	CheckUpToDate(ctx context.Context) error
+	Close()
+}
+
+type migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+func New(ctx context.Context, connStr string, migrations []Migration) (Migrator, error) {
+	pool, err := pgxpool.New(ctx, connStr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create connection pool: %w", err)
+	}
+	return &migrator{
+		pool:       pool,
+		migrations: migrations,
+	}, nil
+}
+
+// Load reads the migrations of fsys, sorted by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations: %w", err)
+	}
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %q: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("unable to read migration %q: %w", entry.Name(), err)
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func (m *migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, dirty, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w at version %d", ErrDirty, current)
+		}
+		for _, migration := range m.migrations {
+			if migration.Version <= current {
+				continue
+			}
+			err := apply(ctx, conn, migration.Up, migration.Version)
+			if err != nil {
+				return fmt.Errorf("unable to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			log.WithField("version", migration.Version).Infof("applied migration %s", migration.Name)
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+func (m *migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, dirty, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w at version %d", ErrDirty, current)
+		}
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if migration.Version > current {
+				continue
+			}
+			// The schema goes back to the previous migration, or to no migration at all
+			var previous uint64
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+			err := apply(ctx, conn, migration.Down, previous)
+			if err != nil {
+				return fmt.Errorf("unable to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			log.WithField("version", migration.Version).Infof("reverted migration %s", migration.Name)
+			current = previous
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+func (m *migrator) Status(ctx context.Context) (Status, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return Status{}, fmt.Errorf("unable to acquire connection: %w", err)
+	}
+	defer conn.Release()
+	current, dirty, err := version(ctx, conn)
+	if err != nil {
+		return Status{}, err
+	}
+	status := Status{Current: current, Dirty: dirty}
+	for _, migration := range m.migrations {
+		status.Latest = migration.Version
+		if migration.Version > current {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+func (m *migrator) CheckUpToDate(ctx context.Context) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case status.Dirty:
+		return fmt.Errorf("%w at version %d", ErrDirty, status.Current)
+	case len(status.Pending) > 0:
+		return fmt.Errorf("%w: version %d, latest migration %d", ErrSchemaBehind, status.Current, status.Latest)
+	case status.Current > status.Latest:
+		log.WithFields(log.Fields{
+			"version":          status.Current,
+			"latest_migration": status.Latest,
+		}).Warn("database schema is ahead of the migrations of this binary")
+	}
+	return nil
+}
+
+func (m *migrator) Close() {
+	m.pool.Close()
+}
+
+// withLock runs f on a connection holding the migration lock
+func (m *migrator) withLock(ctx context.Context, f func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to acquire connection: %w", err)
+	}
+	defer conn.Release()
+	_, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockKey)
+	if err != nil {
+		return fmt.Errorf("unable to lock migrations: %w", err)
+	}
+	defer func() {
+		// The lock is released with the session anyway, failing to unlock is only logged
+		_, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+		if err != nil {
+			log.WithError(err).Warn("unable to unlock migrations")
+		}
+	}()
+	_, err = conn.Exec(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("unable to create schema_migrations table: %w", err)
+	}
+	return f(conn)
+}
+
+// version returns the version of the schema, zero if no migration was applied
+func version(ctx context.Context, conn *pgxpool.Conn) (uint64, bool, error) {
+	var exists bool
+	err := conn.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists)
+	if err != nil {
+		return 0, false, fmt.Errorf("unable to look up schema_migrations table: %w", err)
+	}
+	if !exists {
+		return 0, false, nil
+	}
+	var current int64
+	var dirty bool
+	err = conn.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&current, &dirty)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return 0, false, nil
+	case err != nil:
+		return 0, false, fmt.Errorf("unable to get schema version: %w", err)
+	}
+	return uint64(current), dirty, nil
+}
+
+// apply runs the SQL and records the new version in a single transaction, so a failed migration leaves no trace
+func apply(ctx context.Context, conn *pgxpool.Conn, sql string, newVersion uint64) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() {
+		// Rollback is a no-op after commit
+		_ = tx.Rollback(ctx)
+	}()
+	_, err = tx.Exec(ctx, sql)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, "DELETE FROM schema_migrations")
+	if err != nil {
+		return fmt.Errorf("unable to clear schema version: %w", err)
+	}
+	if newVersion > 0 {
+		_, err = tx.Exec(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", int64(newVersion))
+		if err != nil {
+			return fmt.Errorf("unable to set schema version: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
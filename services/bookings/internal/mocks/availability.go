@@ -14,6 +14,8 @@ import (
 	reflect "reflect"
 	time "time"
 
+	models "github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	availability "github.com/zsoltggs/tabeo-interview/services/bookings/internal/service/availability"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,17 +42,105 @@ func (m *MockAvailability) EXPECT() *MockAvailabilityMockRecorder {
 	return m.recorder
 }
 
+// Calendar mocks base method.
+func (m *MockAvailability) Calendar(arg0 context.Context, arg1, arg2 string, arg3, arg4 time.Time) ([]models.DayAvailability, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Calendar", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]models.DayAvailability)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Calendar indicates an expected call of Calendar.
+func (mr *MockAvailabilityMockRecorder) Calendar(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Calendar", reflect.TypeOf((*MockAvailability)(nil).Calendar), arg0, arg1, arg2, arg3, arg4)
+}
+
+// CheckBatch mocks base method.
+func (m *MockAvailability) CheckBatch(arg0 context.Context, arg1 []models.AvailabilityCheck) []models.AvailabilityCheckResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckBatch", arg0, arg1)
+	ret0, _ := ret[0].([]models.AvailabilityCheckResult)
+	return ret0
+}
+
+// CheckBatch indicates an expected call of CheckBatch.
+func (mr *MockAvailabilityMockRecorder) CheckBatch(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckBatch", reflect.TypeOf((*MockAvailability)(nil).CheckBatch), arg0, arg1)
+}
+
+// CheckLaunchSchedule mocks base method.
+func (m *MockAvailability) CheckLaunchSchedule(arg0 context.Context, arg1 string, arg2 time.Time) availability.LaunchScheduleCheck {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckLaunchSchedule", arg0, arg1, arg2)
+	ret0, _ := ret[0].(availability.LaunchScheduleCheck)
+	return ret0
+}
+
+// CheckLaunchSchedule indicates an expected call of CheckLaunchSchedule.
+func (mr *MockAvailabilityMockRecorder) CheckLaunchSchedule(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckLaunchSchedule", reflect.TypeOf((*MockAvailability)(nil).CheckLaunchSchedule), arg0, arg1, arg2)
+}
+
 // IsDateAvailable mocks base method.
-func (m *MockAvailability) IsDateAvailable(arg0 context.Context, arg1 string, arg2 time.Time) (bool, error) {
+func (m *MockAvailability) IsDateAvailable(arg0 context.Context, arg1, arg2 string, arg3 time.Time) (models.AvailabilityResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsDateAvailable", arg0, arg1, arg2)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "IsDateAvailable", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(models.AvailabilityResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // IsDateAvailable indicates an expected call of IsDateAvailable.
-func (mr *MockAvailabilityMockRecorder) IsDateAvailable(arg0, arg1, arg2 any) *gomock.Call {
+func (mr *MockAvailabilityMockRecorder) IsDateAvailable(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDateAvailable", reflect.TypeOf((*MockAvailability)(nil).IsDateAvailable), arg0, arg1, arg2, arg3)
+}
+
+// IsDateAvailableIn mocks base method.
+func (m *MockAvailability) IsDateAvailableIn(arg0 context.Context, arg1 availability.Store, arg2 availability.LaunchScheduleCheck, arg3, arg4 string, arg5 time.Time) (models.AvailabilityResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsDateAvailableIn", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(models.AvailabilityResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsDateAvailableIn indicates an expected call of IsDateAvailableIn.
+func (mr *MockAvailabilityMockRecorder) IsDateAvailableIn(arg0, arg1, arg2, arg3, arg4, arg5 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDateAvailableIn", reflect.TypeOf((*MockAvailability)(nil).IsDateAvailableIn), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// Suggest mocks base method.
+func (m *MockAvailability) Suggest(arg0 context.Context, arg1, arg2 string, arg3 time.Time) (models.Suggestions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suggest", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(models.Suggestions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Suggest indicates an expected call of Suggest.
+func (mr *MockAvailabilityMockRecorder) Suggest(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suggest", reflect.TypeOf((*MockAvailability)(nil).Suggest), arg0, arg1, arg2, arg3)
+}
+
+// VerifyBooking mocks base method.
+func (m *MockAvailability) VerifyBooking(arg0 context.Context, arg1 models.Booking) (models.AvailabilityResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyBooking", arg0, arg1)
+	ret0, _ := ret[0].(models.AvailabilityResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyBooking indicates an expected call of VerifyBooking.
+func (mr *MockAvailabilityMockRecorder) VerifyBooking(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDateAvailable", reflect.TypeOf((*MockAvailability)(nil).IsDateAvailable), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyBooking", reflect.TypeOf((*MockAvailability)(nil).VerifyBooking), arg0, arg1)
 }
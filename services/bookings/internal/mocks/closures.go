@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zsoltggs/tabeo-interview/services/bookings/internal/closures (interfaces: Importer)
+//
+// Generated by this command:
+//
+//	mockgen -package=mocks -destination=../mocks/closures.go github.com/zsoltggs/tabeo-interview/services/bookings/internal/closures Importer
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	closures "github.com/zsoltggs/tabeo-interview/services/bookings/internal/closures"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockImporter is a mock of Importer interface.
+type MockImporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockImporterMockRecorder
+}
+
+// MockImporterMockRecorder is the mock recorder for MockImporter.
+type MockImporterMockRecorder struct {
+	mock *MockImporter
+}
+
+// NewMockImporter creates a new mock instance.
+func NewMockImporter(ctrl *gomock.Controller) *MockImporter {
+	mock := &MockImporter{ctrl: ctrl}
+	mock.recorder = &MockImporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImporter) EXPECT() *MockImporterMockRecorder {
+	return m.recorder
+}
+
+// Import mocks base method.
+func (m *MockImporter) Import(arg0 context.Context, arg1 string, arg2 closures.Format, arg3 io.Reader) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockImporterMockRecorder) Import(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockImporter)(nil).Import), arg0, arg1, arg2, arg3)
+}
+
+// ImportDirectory mocks base method.
+func (m *MockImporter) ImportDirectory(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportDirectory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportDirectory indicates an expected call of ImportDirectory.
+func (mr *MockImporterMockRecorder) ImportDirectory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportDirectory", reflect.TypeOf((*MockImporter)(nil).ImportDirectory), arg0, arg1)
+}
@@ -12,8 +12,10 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	uuid "github.com/google/uuid"
+	database "github.com/zsoltggs/tabeo-interview/services/bookings/internal/database"
 	models "github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,6 +43,50 @@ func (m *MockDatabase) EXPECT() *MockDatabaseMockRecorder {
 	return m.recorder
 }
 
+// AnonymizeBooking mocks base method.
+func (m *MockDatabase) AnonymizeBooking(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time) (*models.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeBooking", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeBooking indicates an expected call of AnonymizeBooking.
+func (mr *MockDatabaseMockRecorder) AnonymizeBooking(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeBooking", reflect.TypeOf((*MockDatabase)(nil).AnonymizeBooking), arg0, arg1, arg2)
+}
+
+// AppendAudit mocks base method.
+func (m *MockDatabase) AppendAudit(arg0 context.Context, arg1 models.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendAudit", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendAudit indicates an expected call of AppendAudit.
+func (mr *MockDatabaseMockRecorder) AppendAudit(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendAudit", reflect.TypeOf((*MockDatabase)(nil).AppendAudit), arg0, arg1)
+}
+
+// ClaimOutboxEvents mocks base method.
+func (m *MockDatabase) ClaimOutboxEvents(arg0 context.Context, arg1 int, arg2 time.Duration) ([]models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboxEvents", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboxEvents indicates an expected call of ClaimOutboxEvents.
+func (mr *MockDatabaseMockRecorder) ClaimOutboxEvents(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxEvents", reflect.TypeOf((*MockDatabase)(nil).ClaimOutboxEvents), arg0, arg1, arg2)
+}
+
 // Close mocks base method.
 func (m *MockDatabase) Close(arg0 context.Context) {
 	m.ctrl.T.Helper()
@@ -53,6 +99,21 @@ func (mr *MockDatabaseMockRecorder) Close(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockDatabase)(nil).Close), arg0)
 }
 
+// CountBookingsByLaunchDate mocks base method.
+func (m *MockDatabase) CountBookingsByLaunchDate(arg0 context.Context, arg1 string, arg2, arg3 time.Time) ([]models.LaunchDateBookings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountBookingsByLaunchDate", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.LaunchDateBookings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountBookingsByLaunchDate indicates an expected call of CountBookingsByLaunchDate.
+func (mr *MockDatabaseMockRecorder) CountBookingsByLaunchDate(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountBookingsByLaunchDate", reflect.TypeOf((*MockDatabase)(nil).CountBookingsByLaunchDate), arg0, arg1, arg2, arg3)
+}
+
 // Create mocks base method.
 func (m *MockDatabase) Create(arg0 context.Context, arg1 models.Booking) error {
 	m.ctrl.T.Helper()
@@ -67,18 +128,61 @@ func (mr *MockDatabaseMockRecorder) Create(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDatabase)(nil).Create), arg0, arg1)
 }
 
+// CreateBlackout mocks base method.
+func (m *MockDatabase) CreateBlackout(arg0 context.Context, arg1 models.Blackout) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBlackout", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBlackout indicates an expected call of CreateBlackout.
+func (mr *MockDatabaseMockRecorder) CreateBlackout(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBlackout", reflect.TypeOf((*MockDatabase)(nil).CreateBlackout), arg0, arg1)
+}
+
 // Delete mocks base method.
-func (m *MockDatabase) Delete(arg0 context.Context, arg1 uuid.UUID) error {
+func (m *MockDatabase) Delete(arg0 context.Context, arg1 uuid.UUID, arg2 int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockDatabaseMockRecorder) Delete(arg0, arg1 any) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) Delete(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDatabase)(nil).Delete), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDatabase)(nil).Delete), arg0, arg1, arg2)
+}
+
+// DeleteBlackout mocks base method.
+func (m *MockDatabase) DeleteBlackout(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlackout", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBlackout indicates an expected call of DeleteBlackout.
+func (mr *MockDatabaseMockRecorder) DeleteBlackout(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlackout", reflect.TypeOf((*MockDatabase)(nil).DeleteBlackout), arg0, arg1)
+}
+
+// GetBlackout mocks base method.
+func (m *MockDatabase) GetBlackout(arg0 context.Context, arg1 uuid.UUID) (*models.Blackout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlackout", arg0, arg1)
+	ret0, _ := ret[0].(*models.Blackout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlackout indicates an expected call of GetBlackout.
+func (mr *MockDatabaseMockRecorder) GetBlackout(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlackout", reflect.TypeOf((*MockDatabase)(nil).GetBlackout), arg0, arg1)
 }
 
 // GetByID mocks base method.
@@ -124,3 +228,224 @@ func (mr *MockDatabaseMockRecorder) List(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDatabase)(nil).List), arg0, arg1, arg2)
 }
+
+// ListAudit mocks base method.
+func (m *MockDatabase) ListAudit(arg0 context.Context, arg1 uuid.UUID) ([]models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAudit", arg0, arg1)
+	ret0, _ := ret[0].([]models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAudit indicates an expected call of ListAudit.
+func (mr *MockDatabaseMockRecorder) ListAudit(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAudit", reflect.TypeOf((*MockDatabase)(nil).ListAudit), arg0, arg1)
+}
+
+// ListBlackouts mocks base method.
+func (m *MockDatabase) ListBlackouts(arg0 context.Context, arg1 models.BlackoutFilters) ([]models.Blackout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlackouts", arg0, arg1)
+	ret0, _ := ret[0].([]models.Blackout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlackouts indicates an expected call of ListBlackouts.
+func (mr *MockDatabaseMockRecorder) ListBlackouts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlackouts", reflect.TypeOf((*MockDatabase)(nil).ListBlackouts), arg0, arg1)
+}
+
+// ListBookingsToAnonymize mocks base method.
+func (m *MockDatabase) ListBookingsToAnonymize(arg0 context.Context, arg1 time.Time, arg2 models.Pagination) ([]models.ExpiredBooking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBookingsToAnonymize", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ExpiredBooking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBookingsToAnonymize indicates an expected call of ListBookingsToAnonymize.
+func (mr *MockDatabaseMockRecorder) ListBookingsToAnonymize(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBookingsToAnonymize", reflect.TypeOf((*MockDatabase)(nil).ListBookingsToAnonymize), arg0, arg1, arg2)
+}
+
+// ListBookingsToPurge mocks base method.
+func (m *MockDatabase) ListBookingsToPurge(arg0 context.Context, arg1 time.Time, arg2 models.Pagination) ([]models.ExpiredBooking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBookingsToPurge", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ExpiredBooking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBookingsToPurge indicates an expected call of ListBookingsToPurge.
+func (mr *MockDatabaseMockRecorder) ListBookingsToPurge(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBookingsToPurge", reflect.TypeOf((*MockDatabase)(nil).ListBookingsToPurge), arg0, arg1, arg2)
+}
+
+// ListClosures mocks base method.
+func (m *MockDatabase) ListClosures(arg0 context.Context, arg1 string, arg2, arg3 time.Time) ([]models.Closure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClosures", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.Closure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClosures indicates an expected call of ListClosures.
+func (mr *MockDatabaseMockRecorder) ListClosures(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClosures", reflect.TypeOf((*MockDatabase)(nil).ListClosures), arg0, arg1, arg2, arg3)
+}
+
+// LockLaunchSlot mocks base method.
+func (m *MockDatabase) LockLaunchSlot(arg0 context.Context, arg1 string, arg2 time.Time, arg3 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLaunchSlot", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLaunchSlot indicates an expected call of LockLaunchSlot.
+func (mr *MockDatabaseMockRecorder) LockLaunchSlot(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLaunchSlot", reflect.TypeOf((*MockDatabase)(nil).LockLaunchSlot), arg0, arg1, arg2, arg3)
+}
+
+// MarkOutboxEventFailed mocks base method.
+func (m *MockDatabase) MarkOutboxEventFailed(arg0 context.Context, arg1 uuid.UUID, arg2 string, arg3 time.Duration, arg4 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventFailed", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventFailed indicates an expected call of MarkOutboxEventFailed.
+func (mr *MockDatabaseMockRecorder) MarkOutboxEventFailed(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventFailed", reflect.TypeOf((*MockDatabase)(nil).MarkOutboxEventFailed), arg0, arg1, arg2, arg3, arg4)
+}
+
+// MarkOutboxEventPublished mocks base method.
+func (m *MockDatabase) MarkOutboxEventPublished(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventPublished", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventPublished indicates an expected call of MarkOutboxEventPublished.
+func (mr *MockDatabaseMockRecorder) MarkOutboxEventPublished(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventPublished", reflect.TypeOf((*MockDatabase)(nil).MarkOutboxEventPublished), arg0, arg1)
+}
+
+// PurgeBooking mocks base method.
+func (m *MockDatabase) PurgeBooking(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeBooking", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeBooking indicates an expected call of PurgeBooking.
+func (mr *MockDatabaseMockRecorder) PurgeBooking(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeBooking", reflect.TypeOf((*MockDatabase)(nil).PurgeBooking), arg0, arg1)
+}
+
+// ReencryptBookings mocks base method.
+func (m *MockDatabase) ReencryptBookings(arg0 context.Context, arg1 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReencryptBookings", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReencryptBookings indicates an expected call of ReencryptBookings.
+func (mr *MockDatabaseMockRecorder) ReencryptBookings(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReencryptBookings", reflect.TypeOf((*MockDatabase)(nil).ReencryptBookings), arg0, arg1)
+}
+
+// ReplaceClosures mocks base method.
+func (m *MockDatabase) ReplaceClosures(arg0 context.Context, arg1 string, arg2 []models.Closure) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceClosures", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceClosures indicates an expected call of ReplaceClosures.
+func (mr *MockDatabaseMockRecorder) ReplaceClosures(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceClosures", reflect.TypeOf((*MockDatabase)(nil).ReplaceClosures), arg0, arg1, arg2)
+}
+
+// Update mocks base method.
+func (m *MockDatabase) Update(arg0 context.Context, arg1 models.Booking) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDatabaseMockRecorder) Update(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDatabase)(nil).Update), arg0, arg1)
+}
+
+// UpdateBlackout mocks base method.
+func (m *MockDatabase) UpdateBlackout(arg0 context.Context, arg1 models.Blackout) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBlackout", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBlackout indicates an expected call of UpdateBlackout.
+func (mr *MockDatabaseMockRecorder) UpdateBlackout(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBlackout", reflect.TypeOf((*MockDatabase)(nil).UpdateBlackout), arg0, arg1)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockDatabase) UpdateStatus(arg0 context.Context, arg1 uuid.UUID, arg2 string, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockDatabaseMockRecorder) UpdateStatus(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockDatabase)(nil).UpdateStatus), arg0, arg1, arg2, arg3)
+}
+
+// WithTx mocks base method.
+func (m *MockDatabase) WithTx(arg0 context.Context, arg1 func(database.Database) error, arg2 ...database.TxOption) error {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithTx", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockDatabaseMockRecorder) WithTx(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockDatabase)(nil).WithTx), varargs...)
+}
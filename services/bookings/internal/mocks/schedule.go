@@ -0,0 +1,116 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zsoltggs/tabeo-interview/services/bookings/internal/schedule (interfaces: LaunchScheduleProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package=mocks -destination=../mocks/schedule.go github.com/zsoltggs/tabeo-interview/services/bookings/internal/schedule LaunchScheduleProvider
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	schedule "github.com/zsoltggs/tabeo-interview/services/bookings/internal/schedule"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLaunchScheduleProvider is a mock of LaunchScheduleProvider interface.
+type MockLaunchScheduleProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockLaunchScheduleProviderMockRecorder
+}
+
+// MockLaunchScheduleProviderMockRecorder is the mock recorder for MockLaunchScheduleProvider.
+type MockLaunchScheduleProviderMockRecorder struct {
+	mock *MockLaunchScheduleProvider
+}
+
+// NewMockLaunchScheduleProvider creates a new mock instance.
+func NewMockLaunchScheduleProvider(ctrl *gomock.Controller) *MockLaunchScheduleProvider {
+	mock := &MockLaunchScheduleProvider{ctrl: ctrl}
+	mock.recorder = &MockLaunchScheduleProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLaunchScheduleProvider) EXPECT() *MockLaunchScheduleProviderMockRecorder {
+	return m.recorder
+}
+
+// GetLaunchPad mocks base method.
+func (m *MockLaunchScheduleProvider) GetLaunchPad(arg0 context.Context, arg1 string) (*schedule.LaunchPad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLaunchPad", arg0, arg1)
+	ret0, _ := ret[0].(*schedule.LaunchPad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLaunchPad indicates an expected call of GetLaunchPad.
+func (mr *MockLaunchScheduleProviderMockRecorder) GetLaunchPad(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLaunchPad", reflect.TypeOf((*MockLaunchScheduleProvider)(nil).GetLaunchPad), arg0, arg1)
+}
+
+// GetLaunchPads mocks base method.
+func (m *MockLaunchScheduleProvider) GetLaunchPads(arg0 context.Context) ([]schedule.LaunchPad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLaunchPads", arg0)
+	ret0, _ := ret[0].([]schedule.LaunchPad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLaunchPads indicates an expected call of GetLaunchPads.
+func (mr *MockLaunchScheduleProviderMockRecorder) GetLaunchPads(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLaunchPads", reflect.TypeOf((*MockLaunchScheduleProvider)(nil).GetLaunchPads), arg0)
+}
+
+// GetLaunchesForDate mocks base method.
+func (m *MockLaunchScheduleProvider) GetLaunchesForDate(arg0 context.Context, arg1 string, arg2 time.Time) ([]schedule.Launch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLaunchesForDate", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]schedule.Launch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLaunchesForDate indicates an expected call of GetLaunchesForDate.
+func (mr *MockLaunchScheduleProviderMockRecorder) GetLaunchesForDate(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLaunchesForDate", reflect.TypeOf((*MockLaunchScheduleProvider)(nil).GetLaunchesForDate), arg0, arg1, arg2)
+}
+
+// GetLaunchesForRange mocks base method.
+func (m *MockLaunchScheduleProvider) GetLaunchesForRange(arg0 context.Context, arg1 string, arg2, arg3 time.Time) ([]schedule.Launch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLaunchesForRange", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]schedule.Launch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLaunchesForRange indicates an expected call of GetLaunchesForRange.
+func (mr *MockLaunchScheduleProviderMockRecorder) GetLaunchesForRange(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLaunchesForRange", reflect.TypeOf((*MockLaunchScheduleProvider)(nil).GetLaunchesForRange), arg0, arg1, arg2, arg3)
+}
+
+// Name mocks base method.
+func (m *MockLaunchScheduleProvider) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockLaunchScheduleProviderMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockLaunchScheduleProvider)(nil).Name))
+}
@@ -57,17 +57,47 @@ func (mr *MockServiceMockRecorder) CreateBooking(arg0, arg1 any) *gomock.Call {
 }
 
 // DeleteBooking mocks base method.
-func (m *MockService) DeleteBooking(arg0 context.Context, arg1 uuid.UUID) error {
+func (m *MockService) DeleteBooking(arg0 context.Context, arg1 uuid.UUID, arg2 int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteBooking", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteBooking", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteBooking indicates an expected call of DeleteBooking.
-func (mr *MockServiceMockRecorder) DeleteBooking(arg0, arg1 any) *gomock.Call {
+func (mr *MockServiceMockRecorder) DeleteBooking(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBooking", reflect.TypeOf((*MockService)(nil).DeleteBooking), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBooking", reflect.TypeOf((*MockService)(nil).DeleteBooking), arg0, arg1, arg2)
+}
+
+// GetBooking mocks base method.
+func (m *MockService) GetBooking(arg0 context.Context, arg1 uuid.UUID) (*models.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBooking", arg0, arg1)
+	ret0, _ := ret[0].(*models.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBooking indicates an expected call of GetBooking.
+func (mr *MockServiceMockRecorder) GetBooking(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBooking", reflect.TypeOf((*MockService)(nil).GetBooking), arg0, arg1)
+}
+
+// GetBookingHistory mocks base method.
+func (m *MockService) GetBookingHistory(arg0 context.Context, arg1 uuid.UUID) ([]models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookingHistory", arg0, arg1)
+	ret0, _ := ret[0].([]models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookingHistory indicates an expected call of GetBookingHistory.
+func (mr *MockServiceMockRecorder) GetBookingHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingHistory", reflect.TypeOf((*MockService)(nil).GetBookingHistory), arg0, arg1)
 }
 
 // ListBookings mocks base method.
@@ -84,3 +114,32 @@ func (mr *MockServiceMockRecorder) ListBookings(arg0, arg1, arg2 any) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBookings", reflect.TypeOf((*MockService)(nil).ListBookings), arg0, arg1, arg2)
 }
+
+// UpdateBooking mocks base method.
+func (m *MockService) UpdateBooking(arg0 context.Context, arg1 uuid.UUID, arg2 int, arg3 models.UpdateBooking) (*models.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBooking", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateBooking indicates an expected call of UpdateBooking.
+func (mr *MockServiceMockRecorder) UpdateBooking(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBooking", reflect.TypeOf((*MockService)(nil).UpdateBooking), arg0, arg1, arg2, arg3)
+}
+
+// VerifyProvisionalBookings mocks base method.
+func (m *MockService) VerifyProvisionalBookings(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyProvisionalBookings", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyProvisionalBookings indicates an expected call of VerifyProvisionalBookings.
+func (mr *MockServiceMockRecorder) VerifyProvisionalBookings(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyProvisionalBookings", reflect.TypeOf((*MockService)(nil).VerifyProvisionalBookings), arg0)
+}
@@ -56,6 +56,21 @@ func (mr *MockSpaceXServiceMockRecorder) GetLaunchPadForID(arg0, arg1 any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLaunchPadForID", reflect.TypeOf((*MockSpaceXService)(nil).GetLaunchPadForID), arg0, arg1)
 }
 
+// GetLaunchPads mocks base method.
+func (m *MockSpaceXService) GetLaunchPads(arg0 context.Context) ([]smodels.Launchpad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLaunchPads", arg0)
+	ret0, _ := ret[0].([]smodels.Launchpad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLaunchPads indicates an expected call of GetLaunchPads.
+func (mr *MockSpaceXServiceMockRecorder) GetLaunchPads(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLaunchPads", reflect.TypeOf((*MockSpaceXService)(nil).GetLaunchPads), arg0)
+}
+
 // GetLaunchesForDate mocks base method.
 func (m *MockSpaceXService) GetLaunchesForDate(arg0 context.Context, arg1 string, arg2 time.Time) ([]smodels.Launch, error) {
 	m.ctrl.T.Helper()
@@ -70,3 +85,18 @@ func (mr *MockSpaceXServiceMockRecorder) GetLaunchesForDate(arg0, arg1, arg2 any
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLaunchesForDate", reflect.TypeOf((*MockSpaceXService)(nil).GetLaunchesForDate), arg0, arg1, arg2)
 }
+
+// GetLaunchesForRange mocks base method.
+func (m *MockSpaceXService) GetLaunchesForRange(arg0 context.Context, arg1 string, arg2, arg3 time.Time) ([]smodels.Launch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLaunchesForRange", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]smodels.Launch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLaunchesForRange indicates an expected call of GetLaunchesForRange.
+func (mr *MockSpaceXServiceMockRecorder) GetLaunchesForRange(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLaunchesForRange", reflect.TypeOf((*MockSpaceXService)(nil).GetLaunchesForRange), arg0, arg1, arg2, arg3)
+}
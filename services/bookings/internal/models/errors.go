@@ -1,6 +1,52 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var ErrNotFoundLaunchpad = errors.New("launch pad not found")
 var ErrNotAvailable = errors.New("unavailable date")
+var ErrUpstreamUnavailable = errors.New("upstream unavailable")
+var ErrInvalidRange = errors.New("invalid date range")
+
+// ErrSlotBusy is returned when concurrent bookings of the same launch pad and date hold it for too long,
+// the booking can be retried
+var ErrSlotBusy = errors.New("launch slot is busy")
+
+// UpstreamUnavailableError is returned when a third party dependency cannot be reached,
+// RetryAfter is a hint for the caller on when it is worth trying again
+type UpstreamUnavailableError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *UpstreamUnavailableError) Error() string {
+	if e.Err == nil {
+		return ErrUpstreamUnavailable.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrUpstreamUnavailable.Error(), e.Err.Error())
+}
+
+func (e *UpstreamUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+func (e *UpstreamUnavailableError) Is(target error) bool {
+	return target == ErrUpstreamUnavailable
+}
+
+// NotAvailableError is returned when a launch pad cannot be booked on a date, with alternatives if they are known
+type NotAvailableError struct {
+	Reasons     []UnavailabilityReason
+	Suggestions *Suggestions
+}
+
+func (e *NotAvailableError) Error() string {
+	return ErrNotAvailable.Error()
+}
+
+func (e *NotAvailableError) Is(target error) bool {
+	return target == ErrNotAvailable
+}
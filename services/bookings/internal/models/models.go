@@ -1,13 +1,27 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// BookingStatusConfirmed bookings were verified against the launch schedule
+	BookingStatusConfirmed = "confirmed"
+	// BookingStatusProvisional bookings were accepted while the launch schedule was unavailable
+	BookingStatusProvisional = "provisional"
+	// BookingStatusRejected bookings were provisional and turned out to clash with a launch
+	BookingStatusRejected = "rejected"
+)
+
+// LaunchPadStatusActive is the status of the launch pads in use, as reported by the launch schedule providers
+const LaunchPadStatusActive = "active"
+
 type Booking struct {
-	ID uuid.UUID `json:"id"`
+	ID     uuid.UUID `json:"id"`
+	Status string    `json:"status"`
 
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
@@ -20,6 +34,8 @@ type Booking struct {
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Version is incremented on every change of the booking, it starts at 1
+	Version int `json:"version"`
 }
 
 type CreateBooking struct {
@@ -33,13 +49,223 @@ type CreateBooking struct {
 	LaunchDate    time.Time `json:"launch_date"`
 }
 
+// UpdateBooking changes the details of the passenger of a booking, nil fields are left unchanged
+type UpdateBooking struct {
+	FirstName *string
+	LastName  *string
+	Gender    *string
+	Birthday  *time.Time
+}
+
 type Filters struct {
 	LaunchDate    *time.Time `json:"launch_date"`
 	LaunchPadID   *string    `json:"launch_pad_id"`
 	DestinationID *string    `json:"destination_id"`
+	Status        *string    `json:"status"`
+	// FirstName and LastName match the names ignoring case and extra spaces
+	FirstName *string `json:"first_name"`
+	LastName  *string `json:"last_name"`
 }
 
 type Pagination struct {
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
 }
+
+const (
+	// EventBookingCreated is published when a booking is created, its data is the booking
+	EventBookingCreated = "booking.created"
+	// EventBookingUpdated is published when the details of a booking are changed, its data is the updated booking
+	EventBookingUpdated = "booking.updated"
+	// EventBookingDeleted is published when a booking is deleted, its data is the booking as it was
+	EventBookingDeleted = "booking.deleted"
+	// EventBookingAnonymized is published when the retention policy erases the personal data of a booking,
+	// consumers erase their copies too. Its data is the booking without it.
+	EventBookingAnonymized = "booking.anonymized"
+	// EventBookingPurged is published when the retention policy deletes a booking,
+	// its data is the booking as it was without its personal data
+	EventBookingPurged = "booking.purged"
+)
+
+const (
+	// OutboxStatusPending events are waiting to be published, or to be retried
+	OutboxStatusPending = "pending"
+	// OutboxStatusPublished events were delivered to the publisher
+	OutboxStatusPublished = "published"
+	// OutboxStatusDead events failed to be published too many times and are no longer retried
+	OutboxStatusDead = "dead"
+)
+
+// OutboxEvent is a change of a booking, recorded in the outbox in the transaction of the change
+// and published by the relay. Events are delivered at least once, consumers deduplicate them by ID.
+type OutboxEvent struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	BookingID uuid.UUID `json:"booking_id"`
+	// Data is the JSON of the booking
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+	// Attempts is the number of times the event failed to be published
+	Attempts int `json:"-"`
+}
+
+const (
+	AuditActionCreated = "created"
+	AuditActionUpdated = "updated"
+	AuditActionDeleted = "deleted"
+	// AuditActionAnonymized entries record the retention policy erasing the personal data of a booking,
+	// including from its history. After is the anonymized booking, nil if it was deleted before.
+	AuditActionAnonymized = "anonymized"
+	// AuditActionPurged entries record the retention policy deleting a booking
+	AuditActionPurged = "purged"
+)
+
+// AuditEntry is a change of a booking in its history, with who made it and the booking before and after it.
+// Before is nil for a creation and After for a deletion. Both are nil for a purge.
+type AuditEntry struct {
+	BookingID  uuid.UUID `json:"booking_id"`
+	Action     string    `json:"action"`
+	Actor      string    `json:"actor"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Before     *Booking  `json:"before,omitempty"`
+	After      *Booking  `json:"after,omitempty"`
+}
+
+// ExpiredBooking is a booking past a retention period
+type ExpiredBooking struct {
+	ID         uuid.UUID `json:"id"`
+	LaunchDate time.Time `json:"launch_date"`
+}
+
+// AvailabilityResult is the outcome of checking a launch pad for a date
+type AvailabilityResult struct {
+	Available bool
+	// Provisional is set when the launch schedule could not be verified and the date has to be checked again later
+	Provisional bool
+	// Reasons explain why the date is not available, empty if it is
+	Reasons []UnavailabilityReason
+}
+
+// AvailabilityCheck is a launch pad and date checked in a batch, DestinationID is optional
+type AvailabilityCheck struct {
+	LaunchPadID   string
+	DestinationID string
+	Date          time.Time
+}
+
+// AvailabilityCheckResult is the outcome of an AvailabilityCheck, Err is set if the check failed
+type AvailabilityCheckResult struct {
+	Result AvailabilityResult
+	Err    error
+}
+
+// Closure blocks a launch pad between StartsAt (inclusive) and EndsAt (exclusive), imported from a partner calendar
+type Closure struct {
+	ID          uuid.UUID `json:"id"`
+	LaunchPadID string    `json:"launch_pad_id"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Summary     string    `json:"summary"`
+	// Source is the file or upload the closure was imported from, importing a source again replaces its closures
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Blackout blocks a launch pad between StartsAt (inclusive) and EndsAt (exclusive), defined by operators,
+// e.g. for maintenance. A blackout without LaunchPadID blocks every launch pad.
+type Blackout struct {
+	ID          uuid.UUID `json:"id"`
+	LaunchPadID string    `json:"launch_pad_id"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BlackoutFilters narrows the blackouts listed, nil fields are not filtered on
+type BlackoutFilters struct {
+	// LaunchPadID keeps the blackouts of the launch pad and the ones of every launch pad
+	LaunchPadID *string
+	// From and To keep the blackouts overlapping [From, To)
+	From *time.Time
+	To   *time.Time
+}
+
+// LaunchDateBookings is the number of bookings of a launch pad on a launch date to a destination
+type LaunchDateBookings struct {
+	LaunchDate    time.Time `json:"launch_date"`
+	DestinationID string    `json:"destination_id"`
+	Bookings      int       `json:"bookings"`
+}
+
+const (
+	// ReasonLaunch is a launch scheduled from the launch pad by a launch schedule provider
+	ReasonLaunch = "launch"
+	// ReasonFlightFull is our own flight from the launch pad having no seat left
+	ReasonFlightFull = "flight_full"
+	// ReasonClosure is a closure imported from a partner calendar
+	ReasonClosure = "closure"
+	// ReasonDestinationConflict is our own flight from the launch pad going to another destination
+	ReasonDestinationConflict = "destination_conflict"
+	// ReasonLaunchPadInactive is a launch pad that is not in use, e.g. retired or under construction
+	ReasonLaunchPadInactive = "launch_pad_inactive"
+	// ReasonBlackout is a blackout defined by operators
+	ReasonBlackout = "blackout"
+	// ReasonTurnaround is the recovery period of the launch pad before or after a launch of a provider
+	ReasonTurnaround = "turnaround"
+)
+
+// UnavailabilityReason explains why a launch pad cannot be booked on a day.
+// The details matching the type are set, the others are left empty.
+type UnavailabilityReason struct {
+	Type string `json:"type"`
+	// Description is the name of the launch, the summary of the closure...
+	Description string `json:"description"`
+	// Launch is the conflicting launch of a ReasonLaunch or ReasonTurnaround
+	Launch *ConflictingLaunch `json:"launch,omitempty"`
+	// Window is the closed period of a ReasonClosure or ReasonBlackout
+	Window *ClosedWindow `json:"window,omitempty"`
+	// Seats are the seats of our flight for a ReasonFlightFull
+	Seats *Seats `json:"seats,omitempty"`
+	// DestinationID is where our flight goes for a ReasonDestinationConflict
+	DestinationID string `json:"destination_id,omitempty"`
+	// LaunchPadStatus is the status of the launch pad for a ReasonLaunchPadInactive
+	LaunchPadStatus string `json:"launch_pad_status,omitempty"`
+}
+
+// ConflictingLaunch is a launch scheduled by a launch schedule provider
+type ConflictingLaunch struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	DateUTC  time.Time `json:"date_utc"`
+	Provider string    `json:"provider"`
+}
+
+// ClosedWindow is the period a launch pad is closed, from StartsAt (inclusive) to EndsAt (exclusive)
+type ClosedWindow struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Source   string    `json:"source"`
+}
+
+// Seats are the booked seats of a flight out of its capacity
+type Seats struct {
+	Booked   int `json:"booked"`
+	Capacity int `json:"capacity"`
+}
+
+// DayAvailability is the availability of a launch pad on a day, Reasons is empty if it is available
+type DayAvailability struct {
+	Date      time.Time              `json:"date"`
+	Available bool                   `json:"available"`
+	Reasons   []UnavailabilityReason `json:"reasons"`
+}
+
+// Suggestions are alternatives to a launch pad and date that cannot be booked
+type Suggestions struct {
+	// Dates are the nearest available dates on the same launch pad
+	Dates []time.Time `json:"dates"`
+	// LaunchPadIDs are the other active launch pads available on the same date
+	LaunchPadIDs []string `json:"launch_pad_ids"`
+}
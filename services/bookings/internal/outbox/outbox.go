@@ -0,0 +1,174 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+// Kind selects the publisher of the outbox events
+type Kind string
+
+const (
+	// KindInProcess hands the events to handlers of the service
+	KindInProcess Kind = "in-process"
+	// KindFile appends the events to a newline delimited JSON file
+	KindFile Kind = "file"
+	// KindWebhook posts the events to a webhook
+	KindWebhook Kind = "webhook"
+)
+
+func ParseKind(value string) (Kind, error) {
+	switch Kind(value) {
+	case KindInProcess, KindFile, KindWebhook:
+		return Kind(value), nil
+	default:
+		return "", fmt.Errorf("unknown outbox publisher %q", value)
+	}
+}
+
+//go:generate mockgen -package=mocks -destination=../mocks/outbox.go github.com/zsoltggs/tabeo-interview/services/bookings/internal/outbox Publisher
+type Publisher interface {
+	// Publish delivers the event, an event is published again if it fails
+	Publish(ctx context.Context, event models.OutboxEvent) error
+	Close() error
+}
+
+// RelayConfig configures how the outbox events are published
+type RelayConfig struct {
+	// BatchSize is the number of events claimed at once
+	BatchSize int
+	// Lease is how long the claimed events are reserved to the relay, the events of a batch not published by then
+	// are left to be claimed again
+	Lease time.Duration
+	// MaxAttempts is the number of failed attempts after which an event is dead lettered
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry of an event, doubled on every retry up to MaxRetryBackoff
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		BatchSize:       100,
+		Lease:           5 * time.Minute,
+		MaxAttempts:     10,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: 10 * time.Minute,
+	}
+}
+
+type Relay interface {
+	// RelayPending publishes the events due until none is left, it returns the number of events published
+	RelayPending(ctx context.Context) (int, error)
+}
+
+type relay struct {
+	db        database.Database
+	publisher Publisher
+	config    RelayConfig
+}
+
+// NewRelay publishes the outbox events with publisher. Events are claimed with a lease, published outside of any
+// transaction and then marked, so an event published right before a crash, or after its lease expired, is published
+// again: delivery is at least once.
+func NewRelay(db database.Database, publisher Publisher, config RelayConfig) Relay {
+	return &relay{
+		db:        db,
+		publisher: publisher,
+		config:    config,
+	}
+}
+
+func (r *relay) RelayPending(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		// Taken before claiming, so that publishing stops before the lease expires
+		deadline := time.Now().Add(r.config.Lease)
+		events, err := r.db.ClaimOutboxEvents(ctx, r.config.BatchSize, r.config.Lease)
+		if err != nil {
+			return published, fmt.Errorf("unable to relay outbox events: %w", err)
+		}
+		outcomes := r.publish(ctx, events, deadline)
+		err = r.db.WithTx(ctx, func(tx database.Database) error {
+			for _, outcome := range outcomes {
+				err := r.mark(ctx, tx, outcome)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return published, fmt.Errorf("unable to relay outbox events: %w", err)
+		}
+		for _, outcome := range outcomes {
+			if outcome.err == nil {
+				published++
+			}
+		}
+		// The events left out when the lease ran out are claimed again once it expires
+		if len(events) < r.config.BatchSize || len(outcomes) < len(events) {
+			return published, nil
+		}
+	}
+}
+
+// outcome is the result of publishing an event
+type outcome struct {
+	event models.OutboxEvent
+	err   error
+}
+
+// publish publishes the events in order until their lease runs out at deadline, it returns the outcome of the
+// ones attempted
+func (r *relay) publish(ctx context.Context, events []models.OutboxEvent, deadline time.Time) []outcome {
+	leaseCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	var result []outcome
+	for _, event := range events {
+		err := r.publisher.Publish(leaseCtx, event)
+		if leaseCtx.Err() != nil {
+			// Not an attempt that failed, the event was cut short
+			break
+		}
+		result = append(result, outcome{event: event, err: err})
+	}
+	return result
+}
+
+// mark records the outcome of publishing the event
+func (r *relay) mark(ctx context.Context, tx database.Database, outcome outcome) error {
+	event := outcome.event
+	if outcome.err == nil {
+		return tx.MarkOutboxEventPublished(ctx, event.ID)
+	}
+	attempts := event.Attempts + 1
+	dead := attempts >= r.config.MaxAttempts
+	logger := log.WithError(outcome.err).WithFields(log.Fields{
+		"event_id":   event.ID,
+		"event_type": event.Type,
+		"booking_id": event.BookingID,
+		"attempts":   attempts,
+	})
+	if dead {
+		logger.Error("unable to publish outbox event, giving up")
+	} else {
+		logger.Warn("unable to publish outbox event, it will be retried")
+	}
+	return tx.MarkOutboxEventFailed(ctx, event.ID, outcome.err.Error(), r.backoff(attempts), dead)
+}
+
+// backoff is the delay before the next attempt after attempts failed ones
+func (r *relay) backoff(attempts int) time.Duration {
+	backoff := r.config.RetryBackoff
+	for i := 1; i < attempts && backoff < r.config.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, r.config.MaxRetryBackoff)
+}
@@ -0,0 +1,168 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/outbox"
+)
+
+func event(n byte, attempts int) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:        uuid.UUID{n},
+		Type:      models.EventBookingCreated,
+		BookingID: uuid.UUID{0, n},
+		Data:      []byte(`{}`),
+		Attempts:  attempts,
+	}
+}
+
+// expectTx runs the transactions of the relay on mockDB
+func expectTx(mockDB *mocks.MockDatabase) {
+	mockDB.EXPECT().WithTx(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(tx database.Database) error, _ ...database.TxOption) error {
+			return fn(mockDB)
+		}).AnyTimes()
+}
+
+func TestParseKind(t *testing.T) {
+	kind, err := outbox.ParseKind("webhook")
+	require.NoError(t, err)
+	assert.Equal(t, outbox.KindWebhook, kind)
+
+	_, err = outbox.ParseKind("kafka")
+	assert.Error(t, err)
+}
+
+func TestRelayPending(t *testing.T) {
+	config := outbox.RelayConfig{
+		BatchSize:       2,
+		Lease:           time.Minute,
+		MaxAttempts:     3,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: 3 * time.Second,
+	}
+	publishErr := errors.New("connection refused")
+
+	tests := []struct {
+		name              string
+		setupMocks        func(mockDB *mocks.MockDatabase, mockPublisher *mocks.MockPublisher)
+		expectedPublished int
+		expectedErr       string
+	}{
+		{
+			name: "No event",
+			setupMocks: func(mockDB *mocks.MockDatabase, mockPublisher *mocks.MockPublisher) {
+				mockDB.EXPECT().ClaimOutboxEvents(gomock.Any(), 2, time.Minute).Return(nil, nil)
+			},
+		},
+		{
+			name: "Events published batch after batch",
+			setupMocks: func(mockDB *mocks.MockDatabase, mockPublisher *mocks.MockPublisher) {
+				gomock.InOrder(
+					mockDB.EXPECT().ClaimOutboxEvents(gomock.Any(), 2, time.Minute).Return([]models.OutboxEvent{event(1, 0), event(2, 0)}, nil),
+					mockDB.EXPECT().ClaimOutboxEvents(gomock.Any(), 2, time.Minute).Return([]models.OutboxEvent{event(3, 0)}, nil),
+				)
+				for n := byte(1); n <= 3; n++ {
+					mockPublisher.EXPECT().Publish(gomock.Any(), event(n, 0)).Return(nil)
+					mockDB.EXPECT().MarkOutboxEventPublished(gomock.Any(), uuid.UUID{n}).Return(nil)
+				}
+			},
+			expectedPublished: 3,
+		},
+		{
+			name: "Failed events are retried later with a backoff",
+			setupMocks: func(mockDB *mocks.MockDatabase, mockPublisher *mocks.MockPublisher) {
+				mockDB.EXPECT().ClaimOutboxEvents(gomock.Any(), 2, time.Minute).Return([]models.OutboxEvent{event(1, 0), event(2, 1)}, nil)
+				mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(publishErr).Times(2)
+				mockDB.EXPECT().MarkOutboxEventFailed(gomock.Any(), uuid.UUID{1}, "connection refused", time.Second, false).Return(nil)
+				mockDB.EXPECT().MarkOutboxEventFailed(gomock.Any(), uuid.UUID{2}, "connection refused", 2*time.Second, false).Return(nil)
+				// Nothing left to retry right away
+				mockDB.EXPECT().ClaimOutboxEvents(gomock.Any(), 2, time.Minute).Return(nil, nil)
+			},
+		},
+		{
+			name: "Events failing too many times are dead lettered",
+			setupMocks: func(mockDB *mocks.MockDatabase, mockPublisher *mocks.MockPublisher) {
+				mockDB.EXPECT().ClaimOutboxEvents(gomock.Any(), 2, time.Minute).Return([]models.OutboxEvent{event(1, 2)}, nil)
+				mockPublisher.EXPECT().Publish(gomock.Any(), event(1, 2)).Return(publishErr)
+				mockDB.EXPECT().MarkOutboxEventFailed(gomock.Any(), uuid.UUID{1}, "connection refused", 3*time.Second, true).Return(nil)
+			},
+		},
+		{
+			name: "Claim fails",
+			setupMocks: func(mockDB *mocks.MockDatabase, mockPublisher *mocks.MockPublisher) {
+				mockDB.EXPECT().ClaimOutboxEvents(gomock.Any(), 2, time.Minute).Return(nil, errors.New("connection reset"))
+			},
+			expectedErr: "unable to relay outbox events: connection reset",
+		},
+		{
+			name: "Marking fails after the events of previous batches were published",
+			setupMocks: func(mockDB *mocks.MockDatabase, mockPublisher *mocks.MockPublisher) {
+				gomock.InOrder(
+					mockDB.EXPECT().ClaimOutboxEvents(gomock.Any(), 2, time.Minute).Return([]models.OutboxEvent{event(1, 0), event(2, 0)}, nil),
+					mockDB.EXPECT().ClaimOutboxEvents(gomock.Any(), 2, time.Minute).Return([]models.OutboxEvent{event(3, 0)}, nil),
+				)
+				mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+				mockDB.EXPECT().MarkOutboxEventPublished(gomock.Any(), uuid.UUID{1}).Return(nil)
+				mockDB.EXPECT().MarkOutboxEventPublished(gomock.Any(), uuid.UUID{2}).Return(nil)
+				mockDB.EXPECT().MarkOutboxEventPublished(gomock.Any(), uuid.UUID{3}).Return(errors.New("connection reset"))
+			},
+			expectedPublished: 2,
+			expectedErr:       "unable to relay outbox events: connection reset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockDB := mocks.NewMockDatabase(ctrl)
+			mockPublisher := mocks.NewMockPublisher(ctrl)
+			expectTx(mockDB)
+			tt.setupMocks(mockDB, mockPublisher)
+
+			relay := outbox.NewRelay(mockDB, mockPublisher, config)
+			published, err := relay.RelayPending(context.Background())
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedPublished, published)
+		})
+	}
+}
+
+func TestRelayPending_LeaseRunsOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockPublisher := mocks.NewMockPublisher(ctrl)
+	expectTx(mockDB)
+	lease := 50 * time.Millisecond
+	mockDB.EXPECT().ClaimOutboxEvents(gomock.Any(), 2, lease).Return([]models.OutboxEvent{event(1, 0), event(2, 0)}, nil)
+	mockPublisher.EXPECT().Publish(gomock.Any(), event(1, 0)).Return(nil)
+	mockDB.EXPECT().MarkOutboxEventPublished(gomock.Any(), uuid.UUID{1}).Return(nil)
+	// The second event is left to be claimed again once its lease expired, it is not a failed attempt
+	mockPublisher.EXPECT().Publish(gomock.Any(), event(2, 0)).
+		DoAndReturn(func(ctx context.Context, _ models.OutboxEvent) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	config := outbox.DefaultRelayConfig()
+	config.BatchSize = 2
+	config.Lease = lease
+	published, err := outbox.NewRelay(mockDB, mockPublisher, config).RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+}
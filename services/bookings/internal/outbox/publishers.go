@@ -0,0 +1,123 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+// Handler consumes the events published in process
+type Handler func(ctx context.Context, event models.OutboxEvent) error
+
+type inProcess struct {
+	handlers []Handler
+}
+
+// NewInProcessPublisher hands the events to handlers, in order. If a handler fails the event is published
+// again to every handler, so handlers must be idempotent.
+func NewInProcessPublisher(handlers ...Handler) Publisher {
+	return &inProcess{handlers: handlers}
+}
+
+func (p *inProcess) Publish(ctx context.Context, event models.OutboxEvent) error {
+	for _, handler := range p.handlers {
+		err := handler(ctx, event)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *inProcess) Close() error {
+	return nil
+}
+
+type file struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFilePublisher appends the events to the file at path, one JSON object per line
+func NewFilePublisher(path string) (Publisher, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open outbox file: %w", err)
+	}
+	return &file{file: f}, nil
+}
+
+func (p *file) Publish(_ context.Context, event models.OutboxEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("unable to marshal event: %w", err)
+	}
+	line = append(line, '\n')
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.file.Write(line)
+	if err != nil {
+		return fmt.Errorf("unable to write event: %w", err)
+	}
+	// The event is marked published right after, it must not be lost with the page cache
+	err = p.file.Sync()
+	if err != nil {
+		return fmt.Errorf("unable to sync outbox file: %w", err)
+	}
+	return nil
+}
+
+func (p *file) Close() error {
+	return p.file.Close()
+}
+
+type webhook struct {
+	client *http.Client
+	url    string
+}
+
+// NewWebhookPublisher posts the events as JSON to url, any status other than 2xx is a failure.
+// The ID of the event is sent in the Idempotency-Key header for the receivers to drop duplicates.
+func NewWebhookPublisher(client *http.Client, url string) Publisher {
+	return &webhook{
+		client: client,
+		url:    url,
+	}
+}
+
+func (p *webhook) Publish(ctx context.Context, event models.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("unable to marshal event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", event.ID.String())
+	req.Header.Set("X-Event-Type", event.Type)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection is reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *webhook) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
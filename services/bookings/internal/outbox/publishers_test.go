@@ -0,0 +1,92 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/outbox"
+)
+
+func TestInProcessPublisher(t *testing.T) {
+	var received []string
+	handler := func(name string, err error) outbox.Handler {
+		return func(_ context.Context, event models.OutboxEvent) error {
+			received = append(received, name+":"+event.Type)
+			return err
+		}
+	}
+
+	publisher := outbox.NewInProcessPublisher(handler("billing", nil), handler("notifications", nil))
+	require.NoError(t, publisher.Publish(context.Background(), event(1, 0)))
+	assert.Equal(t, []string{"billing:booking.created", "notifications:booking.created"}, received)
+
+	received = nil
+	publisher = outbox.NewInProcessPublisher(handler("billing", errors.New("billing is down")), handler("notifications", nil))
+	assert.EqualError(t, publisher.Publish(context.Background(), event(1, 0)), "billing is down")
+	assert.Equal(t, []string{"billing:booking.created"}, received)
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	publisher, err := outbox.NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), event(1, 0)))
+	require.NoError(t, publisher.Publish(context.Background(), event(2, 0)))
+	require.NoError(t, publisher.Close())
+
+	// Events are appended to the existing file
+	publisher, err = outbox.NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), event(3, 0)))
+	require.NoError(t, publisher.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	require.Len(t, lines, 3)
+	for i, line := range lines {
+		var published models.OutboxEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &published))
+		assert.Equal(t, event(byte(i+1), 0), published)
+	}
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	var status int
+	var request *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	publisher := outbox.NewWebhookPublisher(server.Client(), server.URL+"/events")
+	defer publisher.Close()
+
+	status = http.StatusAccepted
+	require.NoError(t, publisher.Publish(context.Background(), event(1, 0)))
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "/events", request.URL.Path)
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	assert.Equal(t, event(1, 0).ID.String(), request.Header.Get("Idempotency-Key"))
+	assert.Equal(t, models.EventBookingCreated, request.Header.Get("X-Event-Type"))
+	var published models.OutboxEvent
+	require.NoError(t, json.Unmarshal(body, &published))
+	assert.Equal(t, event(1, 0), published)
+
+	status = http.StatusBadGateway
+	assert.EqualError(t, publisher.Publish(context.Background(), event(1, 0)), "webhook responded with status 502")
+}
@@ -0,0 +1,183 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/auth"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+// Policy is how long the bookings are kept after their launch, a zero period disables its step
+type Policy struct {
+	// AnonymizeAfterMonths is the number of months after the launch the personal data of a booking is erased
+	AnonymizeAfterMonths int
+	// DeleteAfterYears is the number of years after the launch a booking is deleted, its history is kept anonymized
+	DeleteAfterYears int
+	// BatchSize is the number of bookings listed at once
+	BatchSize int
+}
+
+// Enabled reports whether the policy changes anything
+func (p Policy) Enabled() bool {
+	return p.AnonymizeAfterMonths > 0 || p.DeleteAfterYears > 0
+}
+
+// Validate checks the bookings are anonymized before they are deleted, so the history they leave has no personal data
+func (p Policy) Validate() error {
+	if p.AnonymizeAfterMonths < 0 || p.DeleteAfterYears < 0 {
+		return errors.New("retention periods cannot be negative")
+	}
+	if p.BatchSize <= 0 {
+		return errors.New("retention batch size must be positive")
+	}
+	if p.DeleteAfterYears > 0 && (p.AnonymizeAfterMonths == 0 || p.AnonymizeAfterMonths > 12*p.DeleteAfterYears) {
+		return fmt.Errorf("bookings deleted after %d years must be anonymized by then", p.DeleteAfterYears)
+	}
+	return nil
+}
+
+// Report is what a run changed, or would change with a dry run
+type Report struct {
+	Anonymized []models.ExpiredBooking
+	Deleted    []models.ExpiredBooking
+}
+
+type Job interface {
+	// Run deletes the bookings past DeleteAfterYears, then anonymizes the ones past AnonymizeAfterMonths.
+	// With dryRun nothing is changed, the report lists what would be.
+	Run(ctx context.Context, dryRun bool) (Report, error)
+}
+
+type job struct {
+	db     database.Database
+	clock  clockwork.Clock
+	policy Policy
+}
+
+// New returns the job applying policy. Every booking is changed in a transaction of its own recording the change
+// in its history, replicas can run it at the same time.
+func New(db database.Database, clock clockwork.Clock, policy Policy) Job {
+	return &job{
+		db:     db,
+		clock:  clock,
+		policy: policy,
+	}
+}
+
+func (j *job) Run(ctx context.Context, dryRun bool) (Report, error) {
+	var report Report
+	now := j.clock.Now()
+	var deleteBefore time.Time
+	if j.policy.DeleteAfterYears > 0 {
+		deleteBefore = now.AddDate(-j.policy.DeleteAfterYears, 0, 0)
+		deleted, err := j.each(ctx, dryRun, deleteBefore, j.db.ListBookingsToPurge, j.purge)
+		report.Deleted = deleted
+		if err != nil {
+			return report, fmt.Errorf("unable to delete bookings: %w", err)
+		}
+	}
+	if j.policy.AnonymizeAfterMonths > 0 {
+		anonymizeBefore := now.AddDate(0, -j.policy.AnonymizeAfterMonths, 0)
+		anonymized, err := j.each(ctx, dryRun, anonymizeBefore, j.db.ListBookingsToAnonymize, j.anonymize)
+		for _, booking := range anonymized {
+			// A dry run lists the bookings it would have deleted first
+			if dryRun && booking.LaunchDate.Before(deleteBefore) {
+				continue
+			}
+			report.Anonymized = append(report.Anonymized, booking)
+		}
+		if err != nil {
+			return report, fmt.Errorf("unable to anonymize bookings: %w", err)
+		}
+	}
+	return report, nil
+}
+
+type listFunc func(ctx context.Context, launchedBefore time.Time, pagination models.Pagination) ([]models.ExpiredBooking, error)
+
+// each applies change to the bookings of list launched before launchedBefore, it returns the bookings changed.
+// change reports false for a booking changed by another replica in the meantime.
+func (j *job) each(ctx context.Context, dryRun bool, launchedBefore time.Time, list listFunc,
+	change func(ctx context.Context, booking models.ExpiredBooking) (bool, error)) ([]models.ExpiredBooking, error) {
+	var result []models.ExpiredBooking
+	pagination := models.Pagination{Limit: j.policy.BatchSize}
+	for {
+		bookings, err := list(ctx, launchedBefore, pagination)
+		if err != nil {
+			return result, err
+		}
+		for _, booking := range bookings {
+			if dryRun {
+				result = append(result, booking)
+				continue
+			}
+			changed, err := change(ctx, booking)
+			if err != nil {
+				return result, fmt.Errorf("booking %s: %w", booking.ID, err)
+			}
+			if changed {
+				result = append(result, booking)
+			}
+		}
+		if len(bookings) < pagination.Limit {
+			return result, nil
+		}
+		// The changed bookings are not listed anymore, a dry run pages through them
+		if dryRun {
+			pagination.Offset += len(bookings)
+		}
+	}
+}
+
+func (j *job) purge(ctx context.Context, booking models.ExpiredBooking) (bool, error) {
+	err := j.db.WithTx(ctx, func(tx database.Database) error {
+		err := tx.PurgeBooking(ctx, booking.ID)
+		if err != nil {
+			return err
+		}
+		return j.audit(ctx, tx, models.AuditActionPurged, booking, nil)
+	})
+	if errors.Is(err, database.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (j *job) anonymize(ctx context.Context, booking models.ExpiredBooking) (bool, error) {
+	changed := false
+	err := j.db.WithTx(ctx, func(tx database.Database) error {
+		anonymized, err := tx.AnonymizeBooking(ctx, booking.ID, j.clock.Now())
+		if err != nil {
+			return err
+		}
+		changed = anonymized != nil
+		if !changed {
+			return nil
+		}
+		return j.audit(ctx, tx, models.AuditActionAnonymized, booking, anonymized)
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+func (j *job) audit(ctx context.Context, tx database.Database, action string, booking models.ExpiredBooking, after *models.Booking) error {
+	err := tx.AppendAudit(ctx, models.AuditEntry{
+		BookingID:  booking.ID,
+		Action:     action,
+		Actor:      auth.SystemActor,
+		OccurredAt: j.clock.Now(),
+		After:      after,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot audit booking: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package retention_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/auth"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/retention"
+)
+
+var now = time.Date(2030, 6, 15, 12, 0, 0, 0, time.UTC)
+
+func expired(n byte, launchDate time.Time) models.ExpiredBooking {
+	return models.ExpiredBooking{ID: uuid.UUID{n}, LaunchDate: launchDate}
+}
+
+// expectTx runs the transactions of the job on mockDB
+func expectTx(mockDB *mocks.MockDatabase) {
+	mockDB.EXPECT().WithTx(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(tx database.Database) error, _ ...database.TxOption) error {
+			return fn(mockDB)
+		}).AnyTimes()
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      retention.Policy
+		expectedErr string
+	}{
+		{
+			name:   "disabled",
+			policy: retention.Policy{BatchSize: 10},
+		},
+		{
+			name:   "anonymize and delete",
+			policy: retention.Policy{AnonymizeAfterMonths: 6, DeleteAfterYears: 5, BatchSize: 10},
+		},
+		{
+			name:        "negative period",
+			policy:      retention.Policy{AnonymizeAfterMonths: -1, BatchSize: 10},
+			expectedErr: "retention periods cannot be negative",
+		},
+		{
+			name:        "no batch size",
+			policy:      retention.Policy{AnonymizeAfterMonths: 6},
+			expectedErr: "retention batch size must be positive",
+		},
+		{
+			name:        "deleted without anonymizing",
+			policy:      retention.Policy{DeleteAfterYears: 5, BatchSize: 10},
+			expectedErr: "bookings deleted after 5 years must be anonymized by then",
+		},
+		{
+			name:        "anonymized after deleting",
+			policy:      retention.Policy{AnonymizeAfterMonths: 61, DeleteAfterYears: 5, BatchSize: 10},
+			expectedErr: "bookings deleted after 5 years must be anonymized by then",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	policy := retention.Policy{AnonymizeAfterMonths: 6, DeleteAfterYears: 5, BatchSize: 2}
+	deleteBefore := now.AddDate(-5, 0, 0)
+	anonymizeBefore := now.AddDate(0, -6, 0)
+	old := expired(1, now.AddDate(-6, 0, 0))
+	purgedMeanwhile := expired(2, now.AddDate(-6, 0, 0))
+	launched := expired(3, now.AddDate(-4, 0, 0))
+	recent := expired(4, now.AddDate(-1, 0, 0))
+	anonymizedMeanwhile := expired(5, now.AddDate(-1, 0, 0))
+	listErr := errors.New("connection refused")
+
+	tests := []struct {
+		name           string
+		dryRun         bool
+		setupMocks     func(mockDB *mocks.MockDatabase)
+		expectedReport retention.Report
+		expectedErr    string
+	}{
+		{
+			name:   "dry run pages through the bookings without changing them",
+			dryRun: true,
+			setupMocks: func(mockDB *mocks.MockDatabase) {
+				mockDB.EXPECT().ListBookingsToPurge(gomock.Any(), deleteBefore, models.Pagination{Limit: 2}).
+					Return([]models.ExpiredBooking{old, purgedMeanwhile}, nil)
+				mockDB.EXPECT().ListBookingsToPurge(gomock.Any(), deleteBefore, models.Pagination{Offset: 2, Limit: 2}).
+					Return(nil, nil)
+				mockDB.EXPECT().ListBookingsToAnonymize(gomock.Any(), anonymizeBefore, models.Pagination{Limit: 2}).
+					Return([]models.ExpiredBooking{old, purgedMeanwhile}, nil)
+				mockDB.EXPECT().ListBookingsToAnonymize(gomock.Any(), anonymizeBefore, models.Pagination{Offset: 2, Limit: 2}).
+					Return([]models.ExpiredBooking{launched, recent}, nil)
+				mockDB.EXPECT().ListBookingsToAnonymize(gomock.Any(), anonymizeBefore, models.Pagination{Offset: 4, Limit: 2}).
+					Return(nil, nil)
+			},
+			expectedReport: retention.Report{
+				Anonymized: []models.ExpiredBooking{launched, recent},
+				Deleted:    []models.ExpiredBooking{old, purgedMeanwhile},
+			},
+		},
+		{
+			name: "deletes then anonymizes the bookings, skipping the ones changed by another replica",
+			setupMocks: func(mockDB *mocks.MockDatabase) {
+				expectTx(mockDB)
+				mockDB.EXPECT().ListBookingsToPurge(gomock.Any(), deleteBefore, models.Pagination{Limit: 2}).
+					Return([]models.ExpiredBooking{old, purgedMeanwhile}, nil)
+				mockDB.EXPECT().ListBookingsToPurge(gomock.Any(), deleteBefore, models.Pagination{Limit: 2}).
+					Return(nil, nil)
+				mockDB.EXPECT().PurgeBooking(gomock.Any(), old.ID).Return(nil)
+				mockDB.EXPECT().AppendAudit(gomock.Any(), models.AuditEntry{
+					BookingID:  old.ID,
+					Action:     models.AuditActionPurged,
+					Actor:      auth.SystemActor,
+					OccurredAt: now,
+				}).Return(nil)
+				mockDB.EXPECT().PurgeBooking(gomock.Any(), purgedMeanwhile.ID).Return(database.ErrNotFound)
+
+				mockDB.EXPECT().ListBookingsToAnonymize(gomock.Any(), anonymizeBefore, models.Pagination{Limit: 2}).
+					Return([]models.ExpiredBooking{launched, recent}, nil)
+				mockDB.EXPECT().ListBookingsToAnonymize(gomock.Any(), anonymizeBefore, models.Pagination{Limit: 2}).
+					Return([]models.ExpiredBooking{anonymizedMeanwhile}, nil)
+				launchedAnonymized := &models.Booking{ID: launched.ID, LaunchDate: launched.LaunchDate, Version: 3}
+				mockDB.EXPECT().AnonymizeBooking(gomock.Any(), launched.ID, now).Return(launchedAnonymized, nil)
+				mockDB.EXPECT().AppendAudit(gomock.Any(), models.AuditEntry{
+					BookingID:  launched.ID,
+					Action:     models.AuditActionAnonymized,
+					Actor:      auth.SystemActor,
+					OccurredAt: now,
+					After:      launchedAnonymized,
+				}).Return(nil)
+				anonymized := &models.Booking{ID: recent.ID, LaunchDate: recent.LaunchDate, Version: 2}
+				mockDB.EXPECT().AnonymizeBooking(gomock.Any(), recent.ID, now).Return(anonymized, nil)
+				mockDB.EXPECT().AppendAudit(gomock.Any(), models.AuditEntry{
+					BookingID:  recent.ID,
+					Action:     models.AuditActionAnonymized,
+					Actor:      auth.SystemActor,
+					OccurredAt: now,
+					After:      anonymized,
+				}).Return(nil)
+				mockDB.EXPECT().AnonymizeBooking(gomock.Any(), anonymizedMeanwhile.ID, now).Return(nil, nil)
+			},
+			expectedReport: retention.Report{
+				Anonymized: []models.ExpiredBooking{launched, recent},
+				Deleted:    []models.ExpiredBooking{old},
+			},
+		},
+		{
+			name: "stops at the first failure",
+			setupMocks: func(mockDB *mocks.MockDatabase) {
+				expectTx(mockDB)
+				mockDB.EXPECT().ListBookingsToPurge(gomock.Any(), deleteBefore, models.Pagination{Limit: 2}).
+					Return([]models.ExpiredBooking{old}, nil)
+				mockDB.EXPECT().PurgeBooking(gomock.Any(), old.ID).Return(nil)
+				mockDB.EXPECT().AppendAudit(gomock.Any(), gomock.Any()).Return(nil)
+				mockDB.EXPECT().ListBookingsToAnonymize(gomock.Any(), anonymizeBefore, models.Pagination{Limit: 2}).
+					Return(nil, listErr)
+			},
+			expectedReport: retention.Report{
+				Deleted: []models.ExpiredBooking{old},
+			},
+			expectedErr: "unable to anonymize bookings: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockDB := mocks.NewMockDatabase(ctrl)
+			tt.setupMocks(mockDB)
+
+			job := retention.New(mockDB, clockwork.NewFakeClockAt(now), policy)
+			report, err := job.Run(context.Background(), tt.dryRun)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedReport, report)
+		})
+	}
+}
+
+func TestRun_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDB := mocks.NewMockDatabase(ctrl)
+
+	report, err := retention.New(mockDB, clockwork.NewFakeClockAt(now), retention.Policy{BatchSize: 10}).
+		Run(context.Background(), false)
+	require.NoError(t, err)
+	assert.Empty(t, report)
+}
@@ -0,0 +1,213 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+// ProviderHealth is the outcome of the last call made to a provider
+type ProviderHealth struct {
+	Name          string     `json:"name"`
+	Healthy       bool       `json:"healthy"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+}
+
+// Composite asks every provider and merges their answers
+type Composite interface {
+	LaunchScheduleProvider
+	// Health returns the health of each provider in the order they were configured
+	Health() []ProviderHealth
+}
+
+type composite struct {
+	providers []LaunchScheduleProvider
+	clock     clockwork.Clock
+
+	mu     sync.Mutex
+	health map[string]*ProviderHealth
+}
+
+// NewComposite fans out every call to the providers in parallel.
+// A launch pad is valid if any provider knows it, and a date is only free if no provider has a launch on it,
+// therefore the launches of a failing provider cannot be ignored and the call fails.
+func NewComposite(clock clockwork.Clock, providers ...LaunchScheduleProvider) Composite {
+	health := make(map[string]*ProviderHealth, len(providers))
+	for _, provider := range providers {
+		// Providers are healthy until proven otherwise
+		health[provider.Name()] = &ProviderHealth{Name: provider.Name(), Healthy: true}
+	}
+	return &composite{
+		providers: providers,
+		clock:     clock,
+		health:    health,
+	}
+}
+
+func (c *composite) Name() string {
+	return "composite"
+}
+
+func (c *composite) GetLaunchPad(ctx context.Context, launchPadID string) (*LaunchPad, error) {
+	pads := make([]*LaunchPad, len(c.providers))
+	errs := make([]error, len(c.providers))
+	c.fanOut(func(i int, provider LaunchScheduleProvider) error {
+		pads[i], errs[i] = provider.GetLaunchPad(ctx, launchPadID)
+		if errors.Is(errs[i], models.ErrNotFoundLaunchpad) {
+			// Not knowing a launch pad is a valid answer
+			return nil
+		}
+		return errs[i]
+	})
+
+	// The first provider in the configured order wins
+	for _, pad := range pads {
+		if pad != nil {
+			return pad, nil
+		}
+	}
+	var failures []error
+	for i, err := range errs {
+		if err != nil && !errors.Is(err, models.ErrNotFoundLaunchpad) {
+			failures = append(failures, fmt.Errorf("provider %s: %w", c.providers[i].Name(), err))
+		}
+	}
+	if len(failures) != 0 {
+		return nil, errors.Join(failures...)
+	}
+	return nil, models.ErrNotFoundLaunchpad
+}
+
+// GetLaunchPads merges the launch pads of the providers, the first provider in the configured order wins.
+// Failing providers are left out unless every provider fails.
+func (c *composite) GetLaunchPads(ctx context.Context) ([]LaunchPad, error) {
+	pads := make([][]LaunchPad, len(c.providers))
+	errs := make([]error, len(c.providers))
+	c.fanOut(func(i int, provider LaunchScheduleProvider) error {
+		pads[i], errs[i] = provider.GetLaunchPads(ctx)
+		return errs[i]
+	})
+
+	var failures []error
+	seen := make(map[string]bool)
+	var result []LaunchPad
+	for i, providerPads := range pads {
+		if errs[i] != nil {
+			failures = append(failures, fmt.Errorf("provider %s: %w", c.providers[i].Name(), errs[i]))
+			continue
+		}
+		for _, pad := range providerPads {
+			if seen[pad.ID] {
+				continue
+			}
+			seen[pad.ID] = true
+			result = append(result, pad)
+		}
+	}
+	if len(failures) == len(c.providers) && len(failures) != 0 {
+		return nil, errors.Join(failures...)
+	}
+	return result, nil
+}
+
+func (c *composite) GetLaunchesForDate(ctx context.Context, launchPadID string, date time.Time) ([]Launch, error) {
+	return c.getLaunches(func(provider LaunchScheduleProvider) ([]Launch, error) {
+		return provider.GetLaunchesForDate(ctx, launchPadID, date)
+	})
+}
+
+func (c *composite) GetLaunchesForRange(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]Launch, error) {
+	return c.getLaunches(func(provider LaunchScheduleProvider) ([]Launch, error) {
+		return provider.GetLaunchesForRange(ctx, launchPadID, from, to)
+	})
+}
+
+// getLaunches merges the launches of every provider, it fails if any of them fails
+func (c *composite) getLaunches(get func(provider LaunchScheduleProvider) ([]Launch, error)) ([]Launch, error) {
+	launches := make([][]Launch, len(c.providers))
+	errs := make([]error, len(c.providers))
+	c.fanOut(func(i int, provider LaunchScheduleProvider) error {
+		launches[i], errs[i] = get(provider)
+		return errs[i]
+	})
+
+	var failures []error
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, fmt.Errorf("provider %s: %w", c.providers[i].Name(), err))
+		}
+	}
+	if len(failures) != 0 {
+		return nil, errors.Join(failures...)
+	}
+	return merge(launches), nil
+}
+
+func (c *composite) Health() []ProviderHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make([]ProviderHealth, 0, len(c.providers))
+	for _, provider := range c.providers {
+		result = append(result, *c.health[provider.Name()])
+	}
+	return result
+}
+
+// fanOut calls fn for every provider in parallel and records the health of the providers
+func (c *composite) fanOut(fn func(i int, provider LaunchScheduleProvider) error) {
+	var wg sync.WaitGroup
+	for i, provider := range c.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := fn(i, provider)
+			c.record(provider.Name(), err)
+		}()
+	}
+	wg.Wait()
+}
+
+func (c *composite) record(name string, err error) {
+	now := c.clock.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	health := c.health[name]
+	if err != nil {
+		health.Healthy = false
+		health.LastError = err.Error()
+		health.LastFailureAt = &now
+		return
+	}
+	health.Healthy = true
+	health.LastError = ""
+	health.LastSuccessAt = &now
+}
+
+// merge combines the launches of the providers, the same launch reported by several providers is kept once
+func merge(launches [][]Launch) []Launch {
+	seen := make(map[string]bool)
+	var result []Launch
+	for _, providerLaunches := range launches {
+		for _, launch := range providerLaunches {
+			key := fmt.Sprintf("%s_%s_%s", launch.LaunchPadID, launch.DateUTC.UTC().Format(time.RFC3339), launch.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, launch)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DateUTC.Before(result[j].DateUTC)
+	})
+	return result
+}
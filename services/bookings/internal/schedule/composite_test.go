@@ -0,0 +1,195 @@
+package schedule_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/schedule"
+)
+
+const launchPadID = "5e9e4501f509094ba4566f84"
+
+var date = time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+
+func newProvider(ctrl *gomock.Controller, name string) *mocks.MockLaunchScheduleProvider {
+	provider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	provider.EXPECT().Name().Return(name).AnyTimes()
+	return provider
+}
+
+func TestComposite_GetLaunchPad(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	spacex := newProvider(ctrl, "spacex")
+	blueOrigin := newProvider(ctrl, "blue-origin")
+	svc := schedule.NewComposite(clockwork.NewFakeClock(), spacex, blueOrigin)
+	ctx := context.Background()
+
+	t.Run("known by one provider", func(t *testing.T) {
+		spacex.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(nil, models.ErrNotFoundLaunchpad)
+		blueOrigin.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).
+			Return(&schedule.LaunchPad{ID: launchPadID, Provider: "blue-origin"}, nil)
+
+		pad, err := svc.GetLaunchPad(ctx, launchPadID)
+		require.NoError(t, err)
+		assert.Equal(t, "blue-origin", pad.Provider)
+	})
+
+	t.Run("unknown by every provider", func(t *testing.T) {
+		spacex.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(nil, models.ErrNotFoundLaunchpad)
+		blueOrigin.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(nil, models.ErrNotFoundLaunchpad)
+
+		_, err := svc.GetLaunchPad(ctx, launchPadID)
+		assert.ErrorIs(t, err, models.ErrNotFoundLaunchpad)
+	})
+
+	t.Run("known by one provider while the other fails", func(t *testing.T) {
+		spacex.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).
+			Return(&schedule.LaunchPad{ID: launchPadID, Provider: "spacex"}, nil)
+		blueOrigin.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(nil, errors.New("boom"))
+
+		pad, err := svc.GetLaunchPad(ctx, launchPadID)
+		require.NoError(t, err)
+		assert.Equal(t, "spacex", pad.Provider)
+	})
+
+	t.Run("unknown while a provider fails", func(t *testing.T) {
+		spacex.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(nil, models.ErrNotFoundLaunchpad)
+		blueOrigin.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(nil, models.ErrUpstreamUnavailable)
+
+		_, err := svc.GetLaunchPad(ctx, launchPadID)
+		assert.EqualError(t, err, "provider blue-origin: upstream unavailable")
+		assert.ErrorIs(t, err, models.ErrUpstreamUnavailable)
+	})
+}
+
+func TestComposite_GetLaunchPads(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	spacex := newProvider(ctrl, "spacex")
+	blueOrigin := newProvider(ctrl, "blue-origin")
+	svc := schedule.NewComposite(clockwork.NewFakeClock(), spacex, blueOrigin)
+	ctx := context.Background()
+
+	t.Run("merges, the first provider wins", func(t *testing.T) {
+		spacex.EXPECT().GetLaunchPads(gomock.Any()).
+			Return([]schedule.LaunchPad{{ID: launchPadID, Provider: "spacex"}}, nil)
+		blueOrigin.EXPECT().GetLaunchPads(gomock.Any()).
+			Return([]schedule.LaunchPad{{ID: launchPadID, Provider: "blue-origin"}, {ID: "lc-39a", Provider: "blue-origin"}}, nil)
+
+		pads, err := svc.GetLaunchPads(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []schedule.LaunchPad{
+			{ID: launchPadID, Provider: "spacex"},
+			{ID: "lc-39a", Provider: "blue-origin"},
+		}, pads)
+	})
+
+	t.Run("leaves out a failing provider", func(t *testing.T) {
+		spacex.EXPECT().GetLaunchPads(gomock.Any()).Return(nil, errors.New("boom"))
+		blueOrigin.EXPECT().GetLaunchPads(gomock.Any()).
+			Return([]schedule.LaunchPad{{ID: "lc-39a", Provider: "blue-origin"}}, nil)
+
+		pads, err := svc.GetLaunchPads(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []schedule.LaunchPad{{ID: "lc-39a", Provider: "blue-origin"}}, pads)
+	})
+
+	t.Run("fails if every provider fails", func(t *testing.T) {
+		spacex.EXPECT().GetLaunchPads(gomock.Any()).Return(nil, errors.New("boom"))
+		blueOrigin.EXPECT().GetLaunchPads(gomock.Any()).Return(nil, models.ErrUpstreamUnavailable)
+
+		_, err := svc.GetLaunchPads(ctx)
+		assert.ErrorIs(t, err, models.ErrUpstreamUnavailable)
+	})
+}
+
+func TestComposite_GetLaunchesForDate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	spacex := newProvider(ctrl, "spacex")
+	blueOrigin := newProvider(ctrl, "blue-origin")
+	svc := schedule.NewComposite(clockwork.NewFakeClock(), spacex, blueOrigin)
+	ctx := context.Background()
+
+	shared := schedule.Launch{Name: "Shared", DateUTC: date.Add(15 * time.Hour), LaunchPadID: launchPadID, Provider: "spacex"}
+	early := schedule.Launch{Name: "Early", DateUTC: date.Add(time.Hour), LaunchPadID: launchPadID, Provider: "blue-origin"}
+
+	t.Run("merges and deduplicates", func(t *testing.T) {
+		spacex.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return([]schedule.Launch{shared}, nil)
+		sharedByBlueOrigin := shared
+		sharedByBlueOrigin.Provider = "blue-origin"
+		blueOrigin.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).
+			Return([]schedule.Launch{sharedByBlueOrigin, early}, nil)
+
+		launches, err := svc.GetLaunchesForDate(ctx, launchPadID, date)
+		require.NoError(t, err)
+		assert.Equal(t, []schedule.Launch{early, shared}, launches)
+	})
+
+	t.Run("fails if any provider fails", func(t *testing.T) {
+		spacex.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return(nil, errors.New("boom"))
+		blueOrigin.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return(nil, nil)
+
+		_, err := svc.GetLaunchesForDate(ctx, launchPadID, date)
+		assert.EqualError(t, err, "provider spacex: boom")
+	})
+}
+
+func TestComposite_GetLaunchesForRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	spacex := newProvider(ctrl, "spacex")
+	blueOrigin := newProvider(ctrl, "blue-origin")
+	svc := schedule.NewComposite(clockwork.NewFakeClock(), spacex, blueOrigin)
+	ctx := context.Background()
+	to := date.AddDate(0, 0, 7)
+
+	later := schedule.Launch{Name: "Later", DateUTC: date.AddDate(0, 0, 3), LaunchPadID: launchPadID, Provider: "spacex"}
+	early := schedule.Launch{Name: "Early", DateUTC: date.Add(time.Hour), LaunchPadID: launchPadID, Provider: "blue-origin"}
+
+	t.Run("merges", func(t *testing.T) {
+		spacex.EXPECT().GetLaunchesForRange(gomock.Any(), launchPadID, date, to).Return([]schedule.Launch{later}, nil)
+		blueOrigin.EXPECT().GetLaunchesForRange(gomock.Any(), launchPadID, date, to).Return([]schedule.Launch{early}, nil)
+
+		launches, err := svc.GetLaunchesForRange(ctx, launchPadID, date, to)
+		require.NoError(t, err)
+		assert.Equal(t, []schedule.Launch{early, later}, launches)
+	})
+
+	t.Run("fails if any provider fails", func(t *testing.T) {
+		spacex.EXPECT().GetLaunchesForRange(gomock.Any(), launchPadID, date, to).Return(nil, nil)
+		blueOrigin.EXPECT().GetLaunchesForRange(gomock.Any(), launchPadID, date, to).Return(nil, errors.New("boom"))
+
+		_, err := svc.GetLaunchesForRange(ctx, launchPadID, date, to)
+		assert.EqualError(t, err, "provider blue-origin: boom")
+	})
+}
+
+func TestComposite_Health(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	spacex := newProvider(ctrl, "spacex")
+	blueOrigin := newProvider(ctrl, "blue-origin")
+	clock := clockwork.NewFakeClockAt(date)
+	svc := schedule.NewComposite(clock, spacex, blueOrigin)
+
+	assert.Equal(t, []schedule.ProviderHealth{
+		{Name: "spacex", Healthy: true},
+		{Name: "blue-origin", Healthy: true},
+	}, svc.Health())
+
+	spacex.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return(nil, nil)
+	blueOrigin.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return(nil, errors.New("boom"))
+	_, err := svc.GetLaunchesForDate(context.Background(), launchPadID, date)
+	require.Error(t, err)
+
+	assert.Equal(t, []schedule.ProviderHealth{
+		{Name: "spacex", Healthy: true, LastSuccessAt: &date},
+		{Name: "blue-origin", Healthy: false, LastError: "boom", LastFailureAt: &date},
+	}, svc.Health())
+}
@@ -0,0 +1,37 @@
+package schedule
+
+import (
+	"context"
+	"time"
+)
+
+// LaunchPad is a launch pad known by a launch schedule provider
+type LaunchPad struct {
+	ID       string
+	Name     string
+	Status   string
+	Provider string
+}
+
+// Launch is a launch scheduled by a provider
+type Launch struct {
+	ID          string
+	Name        string
+	DateUTC     time.Time
+	LaunchPadID string
+	Provider    string
+}
+
+//go:generate mockgen -package=mocks -destination=../mocks/schedule.go github.com/zsoltggs/tabeo-interview/services/bookings/internal/schedule LaunchScheduleProvider
+type LaunchScheduleProvider interface {
+	// Name identifies the provider in logs, errors and health status
+	Name() string
+	// GetLaunchPad returns models.ErrNotFoundLaunchpad if the provider does not know the launch pad
+	GetLaunchPad(ctx context.Context, launchPadID string) (*LaunchPad, error)
+	// GetLaunchPads returns every launch pad known by the provider
+	GetLaunchPads(ctx context.Context) ([]LaunchPad, error)
+	// GetLaunchesForDate returns the launches scheduled from the launch pad on the day of date
+	GetLaunchesForDate(ctx context.Context, launchPadID string, date time.Time) ([]Launch, error)
+	// GetLaunchesForRange returns the launches scheduled from the launch pad from (inclusive) to (exclusive)
+	GetLaunchesForRange(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]Launch, error)
+}
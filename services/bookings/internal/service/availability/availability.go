@@ -2,42 +2,560 @@ package availability
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
-	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/schedule"
 )
 
+// Policy decides what happens when the launch schedule cannot be verified
+type Policy string
+
+const (
+	// PolicyFailClosed rejects the booking if the launch schedule cannot be reached
+	PolicyFailClosed Policy = "fail-closed"
+	// PolicyAcceptProvisionally accepts the booking as provisional if the launch schedule cannot be reached
+	PolicyAcceptProvisionally Policy = "accept-provisionally"
+)
+
+func ParsePolicy(value string) (Policy, error) {
+	switch Policy(value) {
+	case PolicyFailClosed, PolicyAcceptProvisionally:
+		return Policy(value), nil
+	default:
+		return "", fmt.Errorf("unknown availability policy %q", value)
+	}
+}
+
+// Config configures the availability checks
+type Config struct {
+	Policy Policy
+	// FlightCapacity is the number of seats of our flight from a launch pad on a day, zero means unlimited
+	FlightCapacity int
+	// MaxCalendarDays bounds the number of days of a calendar, zero means unbounded
+	MaxCalendarDays int
+	// SuggestionCount is the number of alternative dates suggested when a date is unavailable
+	SuggestionCount int
+	// SuggestionWindowDays bounds the search for alternative dates to this many days before and after the date
+	SuggestionWindowDays int
+	// BatchConcurrency bounds the checks of a batch run in parallel
+	BatchConcurrency int
+	// TurnaroundDays blocks the launch pad this many days before and after a launch of a provider, zero disables it
+	TurnaroundDays int
+}
+
+// suggestionConcurrency bounds the launch pads checked in parallel for suggestions
+const suggestionConcurrency = 4
+
 //go:generate mockgen -package=mocks -destination=../../mocks/availability.go github.com/zsoltggs/tabeo-interview/services/bookings/internal/service/availability  Availability
 type Availability interface {
-	// IsDateAvailable checks if date is available for the given launchPadID and Date
-	IsDateAvailable(ctx context.Context, launchPadID string, date time.Time) (bool, error)
+	// IsDateAvailable checks if a new booking to the destination can be made for the launch pad and date
+	IsDateAvailable(ctx context.Context, launchPadID string, destinationID string, date time.Time) (models.AvailabilityResult, error)
+	// CheckLaunchSchedule asks the launch schedule providers about the launch pad and date, so a booking can do it
+	// before taking the lock of the launch slot and pass the answer to IsDateAvailableIn
+	CheckLaunchSchedule(ctx context.Context, launchPadID string, date time.Time) LaunchScheduleCheck
+	// IsDateAvailableIn is IsDateAvailable with the answer of the launch schedule providers already known, the closures,
+	// blackouts and bookings are read from store, e.g. the transaction holding the lock of the launch slot
+	IsDateAvailableIn(ctx context.Context, store Store, launchSchedule LaunchScheduleCheck, launchPadID string, destinationID string, date time.Time) (models.AvailabilityResult, error)
+	// CheckBatch runs IsDateAvailable for every check concurrently, the results are in the order of the checks.
+	// A failed check does not fail the batch, its error is set on its result.
+	CheckBatch(ctx context.Context, checks []models.AvailabilityCheck) []models.AvailabilityCheckResult
+	// VerifyBooking checks an existing booking again, the seat of the booking itself is not counted against it
+	VerifyBooking(ctx context.Context, booking models.Booking) (models.AvailabilityResult, error)
+	// Calendar returns the availability of the launch pad for every day from from to to, both included.
+	// The destination is optional, flights going to other destinations are only reported if it is set.
+	// It returns models.ErrInvalidRange if to is before from or the range is longer than allowed.
+	Calendar(ctx context.Context, launchPadID string, destinationID string, from time.Time, to time.Time) ([]models.DayAvailability, error)
+	// Suggest returns the nearest available dates of the launch pad within the suggestion window,
+	// and the other active launch pads that can fly to the destination on the date
+	Suggest(ctx context.Context, launchPadID string, destinationID string, date time.Time) (models.Suggestions, error)
+}
+
+// Store returns the closures imported from partner calendars, the blackouts defined by operators and our own bookings,
+// implemented by database.Database
+type Store interface {
+	ListClosures(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]models.Closure, error)
+	ListBlackouts(ctx context.Context, filters models.BlackoutFilters) ([]models.Blackout, error)
+	CountBookingsByLaunchDate(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]models.LaunchDateBookings, error)
+}
+
+// LaunchScheduleCheck is the answer of the launch schedule providers for a launch pad and date,
+// returned by CheckLaunchSchedule
+type LaunchScheduleCheck struct {
+	reasons []models.UnavailabilityReason
+	err     error
 }
 
 type service struct {
-	spacexSvc spacex.SpaceXService
+	provider schedule.LaunchScheduleProvider
+	store    Store
+	clock    clockwork.Clock
+	config   Config
 }
 
-func New(spacexSvc spacex.SpaceXService) Availability {
+func New(provider schedule.LaunchScheduleProvider, store Store, clock clockwork.Clock, config Config) Availability {
 	return &service{
-		spacexSvc: spacexSvc,
+		provider: provider,
+		store:    store,
+		clock:    clock,
+		config:   config,
+	}
+}
+
+func (s service) IsDateAvailable(ctx context.Context, launchPadID string, destinationID string, date time.Time) (models.AvailabilityResult, error) {
+	return s.check(ctx, launchPadID, destinationID, date, nil)
+}
+
+func (s service) CheckLaunchSchedule(ctx context.Context, launchPadID string, date time.Time) LaunchScheduleCheck {
+	reasons, err := s.launchScheduleReasons(ctx, launchPadID, date)
+	return LaunchScheduleCheck{reasons: reasons, err: err}
+}
+
+func (s service) IsDateAvailableIn(ctx context.Context, store Store, launchSchedule LaunchScheduleCheck, launchPadID string, destinationID string, date time.Time) (models.AvailabilityResult, error) {
+	return s.checkIn(ctx, store, launchSchedule, launchPadID, destinationID, date, nil)
+}
+
+func (s service) CheckBatch(ctx context.Context, checks []models.AvailabilityCheck) []models.AvailabilityCheckResult {
+	results := make([]models.AvailabilityCheckResult, len(checks))
+	var g errgroup.Group
+	g.SetLimit(max(s.config.BatchConcurrency, 1))
+	for i, check := range checks {
+		g.Go(func() error {
+			result, err := s.IsDateAvailable(ctx, check.LaunchPadID, check.DestinationID, check.Date)
+			results[i] = models.AvailabilityCheckResult{Result: result, Err: err}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return results
+}
+
+func (s service) VerifyBooking(ctx context.Context, booking models.Booking) (models.AvailabilityResult, error) {
+	return s.check(ctx, booking.LaunchPadID, booking.DestinationID, booking.LaunchDate, &booking)
+}
+
+// check runs the availability checks, the seat of exclude is not counted if it is set
+func (s service) check(ctx context.Context, launchPadID string, destinationID string, date time.Time, exclude *models.Booking) (models.AvailabilityResult, error) {
+	launchSchedule := s.CheckLaunchSchedule(ctx, launchPadID, date)
+	return s.checkIn(ctx, s.store, launchSchedule, launchPadID, destinationID, date, exclude)
+}
+
+// checkIn runs the checks of the local data read from store and applies the policy to the answer of the launch schedule
+func (s service) checkIn(ctx context.Context, store Store, launchSchedule LaunchScheduleCheck, launchPadID string, destinationID string, date time.Time, exclude *models.Booking) (models.AvailabilityResult, error) {
+	// Closures, blackouts and bookings are stored locally, so they are honoured even when the launch schedule is unavailable
+	reasons, err := s.closureReasons(ctx, store, launchPadID, date)
+	if err != nil {
+		return models.AvailabilityResult{}, err
+	}
+	blackoutReasons, err := s.blackoutReasons(ctx, store, launchPadID, date)
+	if err != nil {
+		return models.AvailabilityResult{}, err
+	}
+	reasons = append(reasons, blackoutReasons...)
+	flightReasons, err := s.bookingReasons(ctx, store, launchPadID, destinationID, date, exclude)
+	if err != nil {
+		return models.AvailabilityResult{}, err
+	}
+	reasons = append(reasons, flightReasons...)
+
+	err = launchSchedule.err
+	switch {
+	case err != nil && len(reasons) != 0:
+		// The date is unavailable whatever the launch schedule says
+		log.WithError(err).WithFields(log.Fields{
+			"launch_pad_id": launchPadID,
+			"date":          date.Format("2006-01-02"),
+		}).Warn("launch schedule is unavailable, date is unavailable for other reasons")
+		return models.AvailabilityResult{Reasons: reasons}, nil
+	case err != nil && s.config.Policy == PolicyAcceptProvisionally && errors.Is(err, models.ErrUpstreamUnavailable):
+		log.WithError(err).WithFields(log.Fields{
+			"launch_pad_id": launchPadID,
+			"date":          date.Format("2006-01-02"),
+		}).Warn("launch schedule is unavailable, accepting provisionally")
+		return models.AvailabilityResult{Available: true, Provisional: true}, nil
+	case err != nil:
+		return models.AvailabilityResult{}, err
+	}
+	reasons = append(reasons, launchSchedule.reasons...)
+	return models.AvailabilityResult{Available: len(reasons) == 0, Reasons: reasons}, nil
+}
+
+// launchScheduleReasons checks the launch pad and its launches of the day with the launch schedule providers,
+// and the launches of the days around it when a turnaround is configured
+func (s service) launchScheduleReasons(ctx context.Context, launchPadID string, date time.Time) ([]models.UnavailabilityReason, error) {
+	pad, err := s.provider.GetLaunchPad(ctx, launchPadID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get launch pad for ID: %w", err)
+	}
+	var reasons []models.UnavailabilityReason
+	if reason, inactive := launchPadReason(*pad); inactive {
+		reasons = append(reasons, reason)
+	}
+	if s.config.TurnaroundDays <= 0 {
+		launches, err := s.provider.GetLaunchesForDate(ctx, launchPadID, date)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get launches: %w", err)
+		}
+		for _, launch := range launches {
+			reasons = append(reasons, launchReason(launch))
+		}
+		return reasons, nil
+	}
+
+	day := toDay(date)
+	launches, err := s.provider.GetLaunchesForRange(ctx, launchPadID,
+		day.AddDate(0, 0, -s.config.TurnaroundDays), day.AddDate(0, 0, s.config.TurnaroundDays+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get launches: %w", err)
+	}
+	for _, launch := range launches {
+		if toDay(launch.DateUTC).Equal(day) {
+			reasons = append(reasons, launchReason(launch))
+		} else {
+			reasons = append(reasons, turnaroundReason(launch, day))
+		}
+	}
+	return reasons, nil
+}
+
+func (s service) closureReasons(ctx context.Context, store Store, launchPadID string, date time.Time) ([]models.UnavailabilityReason, error) {
+	from := toDay(date)
+	closures, err := store.ListClosures(ctx, launchPadID, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list closures: %w", err)
+	}
+	var reasons []models.UnavailabilityReason
+	for _, closure := range closures {
+		reasons = append(reasons, closureReason(closure))
+	}
+	return reasons, nil
+}
+
+func (s service) blackoutReasons(ctx context.Context, store Store, launchPadID string, date time.Time) ([]models.UnavailabilityReason, error) {
+	from := toDay(date)
+	to := from.AddDate(0, 0, 1)
+	blackouts, err := store.ListBlackouts(ctx, models.BlackoutFilters{LaunchPadID: &launchPadID, From: &from, To: &to})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list blackouts: %w", err)
+	}
+	var reasons []models.UnavailabilityReason
+	for _, blackout := range blackouts {
+		reasons = append(reasons, blackoutReason(blackout))
+	}
+	return reasons, nil
+}
+
+// bookingReasons checks that our flight of the day has a free seat and goes to the destination
+func (s service) bookingReasons(ctx context.Context, store Store, launchPadID string, destinationID string, date time.Time, exclude *models.Booking) ([]models.UnavailabilityReason, error) {
+	if s.config.FlightCapacity <= 0 && destinationID == "" {
+		return nil, nil
+	}
+	from := toDay(date)
+	counts, err := store.CountBookingsByLaunchDate(ctx, launchPadID, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to count bookings: %w", err)
+	}
+	if exclude != nil {
+		counts = withoutBooking(counts, *exclude)
+	}
+	return s.flightReasons(counts, destinationID), nil
+}
+
+// flightReasons returns why our flight of a day cannot take one more passenger to the destination,
+// counts are the bookings of that single day
+func (s service) flightReasons(counts []models.LaunchDateBookings, destinationID string) []models.UnavailabilityReason {
+	var reasons []models.UnavailabilityReason
+	bookings := 0
+	for _, count := range counts {
+		bookings += count.Bookings
+		// A flight goes to a single destination, set by its first booking
+		if destinationID != "" && count.Bookings > 0 && count.DestinationID != destinationID {
+			reasons = append(reasons, models.UnavailabilityReason{
+				Type:          models.ReasonDestinationConflict,
+				Description:   fmt.Sprintf("flight goes to %s", count.DestinationID),
+				DestinationID: count.DestinationID,
+			})
+		}
+	}
+	if s.config.FlightCapacity > 0 && bookings >= s.config.FlightCapacity {
+		reasons = append(reasons, models.UnavailabilityReason{
+			Type:        models.ReasonFlightFull,
+			Description: fmt.Sprintf("%d of %d seats booked", bookings, s.config.FlightCapacity),
+			Seats:       &models.Seats{Booked: bookings, Capacity: s.config.FlightCapacity},
+		})
+	}
+	return reasons
+}
+
+func closureReason(closure models.Closure) models.UnavailabilityReason {
+	return models.UnavailabilityReason{
+		Type:        models.ReasonClosure,
+		Description: closure.Summary,
+		Window: &models.ClosedWindow{
+			StartsAt: closure.StartsAt,
+			EndsAt:   closure.EndsAt,
+			Source:   closure.Source,
+		},
+	}
+}
+
+func blackoutReason(blackout models.Blackout) models.UnavailabilityReason {
+	return models.UnavailabilityReason{
+		Type:        models.ReasonBlackout,
+		Description: blackout.Reason,
+		Window: &models.ClosedWindow{
+			StartsAt: blackout.StartsAt,
+			EndsAt:   blackout.EndsAt,
+		},
+	}
+}
+
+func launchReason(launch schedule.Launch) models.UnavailabilityReason {
+	return models.UnavailabilityReason{
+		Type:        models.ReasonLaunch,
+		Description: launch.Name,
+		Launch: &models.ConflictingLaunch{
+			ID:       launch.ID,
+			Name:     launch.Name,
+			DateUTC:  launch.DateUTC,
+			Provider: launch.Provider,
+		},
+	}
+}
+
+// turnaroundReason blocks day, a day before or after the launch within the turnaround
+func turnaroundReason(launch schedule.Launch, day time.Time) models.UnavailabilityReason {
+	description := fmt.Sprintf("turnaround after %s", launch.Name)
+	if day.Before(launch.DateUTC) {
+		description = fmt.Sprintf("turnaround before %s", launch.Name)
+	}
+	reason := launchReason(launch)
+	reason.Type = models.ReasonTurnaround
+	reason.Description = description
+	return reason
+}
+
+// launchPadReason returns a reason if the launch pad is not in use
+func launchPadReason(pad schedule.LaunchPad) (models.UnavailabilityReason, bool) {
+	if pad.Status == models.LaunchPadStatusActive {
+		return models.UnavailabilityReason{}, false
 	}
+	return models.UnavailabilityReason{
+		Type:            models.ReasonLaunchPadInactive,
+		Description:     fmt.Sprintf("launch pad is %s", pad.Status),
+		LaunchPadStatus: pad.Status,
+	}, true
 }
 
-func (s service) IsDateAvailable(ctx context.Context, launchPadID string, date time.Time) (bool, error) {
-	// Validate that the launch pad is valid
-	_, err := s.spacexSvc.GetLaunchPadForID(ctx, launchPadID)
+// withoutBooking removes the seat of the booking from the counts
+func withoutBooking(counts []models.LaunchDateBookings, booking models.Booking) []models.LaunchDateBookings {
+	result := make([]models.LaunchDateBookings, 0, len(counts))
+	for _, count := range counts {
+		if count.DestinationID == booking.DestinationID && toDay(count.LaunchDate).Equal(toDay(booking.LaunchDate)) {
+			count.Bookings--
+		}
+		result = append(result, count)
+	}
+	return result
+}
+
+func (s service) Calendar(ctx context.Context, launchPadID string, destinationID string, from time.Time, to time.Time) ([]models.DayAvailability, error) {
+	from, to = toDay(from), toDay(to)
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to is before from", models.ErrInvalidRange)
+	}
+	days := int(to.Sub(from).Hours()/24) + 1
+	if s.config.MaxCalendarDays > 0 && days > s.config.MaxCalendarDays {
+		return nil, fmt.Errorf("%w: more than %d days", models.ErrInvalidRange, s.config.MaxCalendarDays)
+	}
+	return s.calendar(ctx, launchPadID, destinationID, from, to)
+}
+
+// calendar builds the calendar of the days from from to to, both included and already truncated to days
+func (s service) calendar(ctx context.Context, launchPadID string, destinationID string, from time.Time, to time.Time) ([]models.DayAvailability, error) {
+	days := int(to.Sub(from).Hours()/24) + 1
+	end := to.AddDate(0, 0, 1)
+
+	calendar := make([]models.DayAvailability, days)
+	for i := range calendar {
+		calendar[i] = models.DayAvailability{Date: from.AddDate(0, 0, i)}
+	}
+	index := func(at time.Time) (int, bool) {
+		i := int(toDay(at).Sub(from).Hours() / 24)
+		return i, i >= 0 && i < days
+	}
+	block := func(at time.Time, reasons ...models.UnavailabilityReason) {
+		if i, ok := index(at); ok {
+			calendar[i].Reasons = append(calendar[i].Reasons, reasons...)
+		}
+	}
+
+	pad, err := s.provider.GetLaunchPad(ctx, launchPadID)
 	if err != nil {
-		return false, fmt.Errorf("unable to get launch pad for ID: %w", err)
+		return nil, fmt.Errorf("unable to get launch pad for ID: %w", err)
+	}
+	if reason, inactive := launchPadReason(*pad); inactive {
+		for _, day := range calendar {
+			block(day.Date, reason)
+		}
+	}
+	closures, err := s.store.ListClosures(ctx, launchPadID, from, end)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list closures: %w", err)
+	}
+	for _, closure := range closures {
+		// Closures end exclusively and can span several days
+		for day := toDay(closure.StartsAt); day.Before(closure.EndsAt); day = day.AddDate(0, 0, 1) {
+			block(day, closureReason(closure))
+		}
 	}
-	// Get all launches for the date
-	launches, err := s.spacexSvc.GetLaunchesForDate(ctx, launchPadID, date)
+	blackouts, err := s.store.ListBlackouts(ctx, models.BlackoutFilters{LaunchPadID: &launchPadID, From: &from, To: &end})
 	if err != nil {
-		return false, fmt.Errorf("unable to get launches: %w", err)
+		return nil, fmt.Errorf("unable to list blackouts: %w", err)
 	}
-	if len(launches) != 0 {
-		return false, nil
+	for _, blackout := range blackouts {
+		for day := toDay(blackout.StartsAt); day.Before(blackout.EndsAt); day = day.AddDate(0, 0, 1) {
+			block(day, blackoutReason(blackout))
+		}
 	}
-	// If no launches are for the date it means that the date is available
-	return true, nil
+	if s.config.FlightCapacity > 0 || destinationID != "" {
+		counts, err := s.store.CountBookingsByLaunchDate(ctx, launchPadID, from, end)
+		if err != nil {
+			return nil, fmt.Errorf("unable to count bookings: %w", err)
+		}
+		countsByDay := make([][]models.LaunchDateBookings, days)
+		for _, count := range counts {
+			if i, ok := index(count.LaunchDate); ok {
+				countsByDay[i] = append(countsByDay[i], count)
+			}
+		}
+		for i, dayCounts := range countsByDay {
+			block(calendar[i].Date, s.flightReasons(dayCounts, destinationID)...)
+		}
+	}
+	// Launches just outside the calendar still block the days of their turnaround inside it
+	turnaround := max(s.config.TurnaroundDays, 0)
+	launches, err := s.provider.GetLaunchesForRange(ctx, launchPadID,
+		from.AddDate(0, 0, -turnaround), end.AddDate(0, 0, turnaround))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get launches: %w", err)
+	}
+	for _, launch := range launches {
+		launchDay := toDay(launch.DateUTC)
+		block(launchDay, launchReason(launch))
+		for offset := 1; offset <= turnaround; offset++ {
+			before, after := launchDay.AddDate(0, 0, -offset), launchDay.AddDate(0, 0, offset)
+			block(before, turnaroundReason(launch, before))
+			block(after, turnaroundReason(launch, after))
+		}
+	}
+
+	for i := range calendar {
+		calendar[i].Available = len(calendar[i].Reasons) == 0
+	}
+	return calendar, nil
+}
+
+func (s service) Suggest(ctx context.Context, launchPadID string, destinationID string, date time.Time) (models.Suggestions, error) {
+	date = toDay(date)
+	dates, err := s.suggestDates(ctx, launchPadID, destinationID, date)
+	if err != nil {
+		return models.Suggestions{}, err
+	}
+	launchPadIDs, err := s.suggestLaunchPads(ctx, launchPadID, destinationID, date)
+	if err != nil {
+		return models.Suggestions{}, err
+	}
+	return models.Suggestions{
+		Dates:        dates,
+		LaunchPadIDs: launchPadIDs,
+	}, nil
+}
+
+// suggestDates returns the nearest available days to date, nearest first and the earlier first on a tie.
+// Days in the past are not suggested.
+func (s service) suggestDates(ctx context.Context, launchPadID string, destinationID string, date time.Time) ([]time.Time, error) {
+	if s.config.SuggestionCount <= 0 {
+		return nil, nil
+	}
+	from := date.AddDate(0, 0, -s.config.SuggestionWindowDays)
+	if today := toDay(s.clock.Now()); from.Before(today) {
+		from = today
+	}
+	to := date.AddDate(0, 0, s.config.SuggestionWindowDays)
+	if to.Before(from) {
+		return nil, nil
+	}
+	calendar, err := s.calendar(ctx, launchPadID, destinationID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []time.Time
+	for _, day := range calendar {
+		if day.Available && !day.Date.Equal(date) {
+			candidates = append(candidates, day.Date)
+		}
+	}
+	distance := func(day time.Time) time.Duration {
+		if d := day.Sub(date); d >= 0 {
+			return d
+		}
+		return date.Sub(day)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return distance(candidates[i]) < distance(candidates[j])
+	})
+	if len(candidates) > s.config.SuggestionCount {
+		candidates = candidates[:s.config.SuggestionCount]
+	}
+	return candidates, nil
+}
+
+// suggestLaunchPads returns the other active launch pads available for the destination on date.
+// A launch pad that cannot be checked is left out.
+func (s service) suggestLaunchPads(ctx context.Context, launchPadID string, destinationID string, date time.Time) ([]string, error) {
+	pads, err := s.provider.GetLaunchPads(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get launch pads: %w", err)
+	}
+	available := make([]bool, len(pads))
+	var g errgroup.Group
+	g.SetLimit(suggestionConcurrency)
+	for i, pad := range pads {
+		if pad.ID == launchPadID || pad.Status != models.LaunchPadStatusActive {
+			continue
+		}
+		g.Go(func() error {
+			calendar, err := s.calendar(ctx, pad.ID, destinationID, date, date)
+			if err != nil {
+				log.WithError(err).WithField("launch_pad_id", pad.ID).Warn("unable to check launch pad for suggestions")
+				return nil
+			}
+			available[i] = calendar[0].Available
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	var result []string
+	for i, pad := range pads {
+		if available[i] {
+			result = append(result, pad.ID)
+		}
+	}
+	return result, nil
+}
+
+// toDay returns the start of the UTC day of t
+func toDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 }
@@ -1,4 +1,4 @@
-package availability
+package availability_test
 
 import (
 	"context"
@@ -6,10 +6,13 @@ import (
 	"testing"
 	"time"
 
-	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/schedule"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/assert"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/service/availability"
 	"go.uber.org/mock/gomock"
 )
 
@@ -17,22 +20,26 @@ func TestIsDateAvailable(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockSpaceXService := mocks.NewMockSpaceXService(ctrl)
-	svc := New(mockSpaceXService)
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockDB.EXPECT().ListBlackouts(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockDB.EXPECT().ListClosures(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyFailClosed})
 
 	const launchPadID = "5e9e4501f509094ba4566f84"
-	launch := smodels.Launch{
-		Name:      "Starlink 4-21 (v1.5)",
-		DateUTC:   time.Date(2022, 07, 07, 13, 11, 00, 0, time.UTC),
-		Launchpad: launchPadID,
-		Success:   true,
+	launch := schedule.Launch{
+		ID:          "62a9f89a20413d2695d8871a",
+		Name:        "Starlink 4-21 (v1.5)",
+		DateUTC:     time.Date(2022, 07, 07, 13, 11, 00, 0, time.UTC),
+		LaunchPadID: launchPadID,
+		Provider:    "spacex",
 	}
 	tests := []struct {
 		name          string
 		launchPadID   string
 		date          time.Time
 		mockSetup     func()
-		expected      bool
+		expected      models.AvailabilityResult
 		expectedError error
 	}{
 		{
@@ -40,14 +47,14 @@ func TestIsDateAvailable(t *testing.T) {
 			launchPadID: launchPadID,
 			date:        time.Date(2022, 7, 7, 0, 0, 0, 0, time.UTC),
 			mockSetup: func() {
-				mockSpaceXService.EXPECT().
-					GetLaunchPadForID(gomock.Any(), launchPadID).
-					Return(&smodels.Launchpad{}, nil)
-				mockSpaceXService.EXPECT().
+				mockProvider.EXPECT().
+					GetLaunchPad(gomock.Any(), launchPadID).
+					Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+				mockProvider.EXPECT().
 					GetLaunchesForDate(gomock.Any(), launchPadID, time.Date(2022, 7, 7, 0, 0, 0, 0, time.UTC)).
 					Return(nil, nil)
 			},
-			expected:      true,
+			expected:      models.AvailabilityResult{Available: true},
 			expectedError: nil,
 		},
 		{
@@ -55,14 +62,23 @@ func TestIsDateAvailable(t *testing.T) {
 			launchPadID: launchPadID,
 			date:        time.Date(2022, 7, 7, 0, 0, 0, 0, time.UTC),
 			mockSetup: func() {
-				mockSpaceXService.EXPECT().
-					GetLaunchPadForID(gomock.Any(), launchPadID).
-					Return(&smodels.Launchpad{}, nil)
-				mockSpaceXService.EXPECT().
+				mockProvider.EXPECT().
+					GetLaunchPad(gomock.Any(), launchPadID).
+					Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+				mockProvider.EXPECT().
 					GetLaunchesForDate(gomock.Any(), launchPadID, time.Date(2022, 7, 7, 0, 0, 0, 0, time.UTC)).
-					Return([]smodels.Launch{launch}, nil)
+					Return([]schedule.Launch{launch}, nil)
 			},
-			expected:      false,
+			expected: models.AvailabilityResult{Reasons: []models.UnavailabilityReason{{
+				Type:        models.ReasonLaunch,
+				Description: "Starlink 4-21 (v1.5)",
+				Launch: &models.ConflictingLaunch{
+					ID:       "62a9f89a20413d2695d8871a",
+					Name:     "Starlink 4-21 (v1.5)",
+					DateUTC:  time.Date(2022, 07, 07, 13, 11, 00, 0, time.UTC),
+					Provider: "spacex",
+				},
+			}}},
 			expectedError: nil,
 		},
 		{
@@ -70,11 +86,11 @@ func TestIsDateAvailable(t *testing.T) {
 			launchPadID: "invalid_launchpad_id",
 			date:        time.Date(2022, 7, 7, 0, 0, 0, 0, time.UTC),
 			mockSetup: func() {
-				mockSpaceXService.EXPECT().
-					GetLaunchPadForID(gomock.Any(), "invalid_launchpad_id").
+				mockProvider.EXPECT().
+					GetLaunchPad(gomock.Any(), "invalid_launchpad_id").
 					Return(nil, errors.New("not found"))
 			},
-			expected:      false,
+			expected:      models.AvailabilityResult{},
 			expectedError: errors.New("unable to get launch pad for ID: not found"),
 		},
 		{
@@ -82,14 +98,14 @@ func TestIsDateAvailable(t *testing.T) {
 			launchPadID: launchPadID,
 			date:        time.Date(2022, 7, 7, 0, 0, 0, 0, time.UTC),
 			mockSetup: func() {
-				mockSpaceXService.EXPECT().
-					GetLaunchPadForID(gomock.Any(), launchPadID).
-					Return(&smodels.Launchpad{}, nil)
-				mockSpaceXService.EXPECT().
+				mockProvider.EXPECT().
+					GetLaunchPad(gomock.Any(), launchPadID).
+					Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+				mockProvider.EXPECT().
 					GetLaunchesForDate(gomock.Any(), launchPadID, time.Date(2022, 7, 7, 0, 0, 0, 0, time.UTC)).
 					Return(nil, errors.New("internal server error"))
 			},
-			expected:      false,
+			expected:      models.AvailabilityResult{},
 			expectedError: errors.New("unable to get launches: internal server error"),
 		},
 	}
@@ -97,7 +113,7 @@ func TestIsDateAvailable(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			available, err := svc.IsDateAvailable(context.Background(), tt.launchPadID, tt.date)
+			available, err := svc.IsDateAvailable(context.Background(), tt.launchPadID, "", tt.date)
 
 			assert.Equal(t, tt.expected, available)
 			if tt.expectedError != nil {
@@ -108,3 +124,609 @@ func TestIsDateAvailable(t *testing.T) {
 		})
 	}
 }
+
+func TestIsDateAvailable_AcceptProvisionally(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockDB.EXPECT().ListBlackouts(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockDB.EXPECT().ListClosures(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	const launchPadID = "5e9e4501f509094ba4566f84"
+	date := time.Date(2022, 7, 7, 0, 0, 0, 0, time.UTC)
+	upstreamErr := &models.UpstreamUnavailableError{Err: errors.New("circuit breaker is open")}
+
+	t.Run("Provisional: launch schedule unavailable", func(t *testing.T) {
+		svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyAcceptProvisionally})
+		mockProvider.EXPECT().
+			GetLaunchPad(gomock.Any(), launchPadID).
+			Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+		mockProvider.EXPECT().
+			GetLaunchesForDate(gomock.Any(), launchPadID, date).
+			Return(nil, upstreamErr)
+
+		res, err := svc.IsDateAvailable(context.Background(), launchPadID, "", date)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AvailabilityResult{Available: true, Provisional: true}, res)
+	})
+
+	t.Run("Fail closed: launch schedule unavailable", func(t *testing.T) {
+		svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyFailClosed})
+		mockProvider.EXPECT().
+			GetLaunchPad(gomock.Any(), launchPadID).
+			Return(nil, upstreamErr)
+
+		res, err := svc.IsDateAvailable(context.Background(), launchPadID, "", date)
+		assert.ErrorIs(t, err, models.ErrUpstreamUnavailable)
+		assert.Equal(t, models.AvailabilityResult{}, res)
+	})
+
+	t.Run("Other errors are not accepted", func(t *testing.T) {
+		svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyAcceptProvisionally})
+		mockProvider.EXPECT().
+			GetLaunchPad(gomock.Any(), launchPadID).
+			Return(nil, models.ErrNotFoundLaunchpad)
+
+		res, err := svc.IsDateAvailable(context.Background(), launchPadID, "", date)
+		assert.ErrorIs(t, err, models.ErrNotFoundLaunchpad)
+		assert.Equal(t, models.AvailabilityResult{}, res)
+	})
+}
+
+func TestIsDateAvailableIn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockTx := mocks.NewMockDatabase(ctrl)
+	mockTx.EXPECT().ListBlackouts(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockTx.EXPECT().ListClosures(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	const launchPadID = "5e9e4501f509094ba4566f84"
+	date := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+
+	t.Run("local data is read from the given store and the providers are not asked again", func(t *testing.T) {
+		svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyFailClosed, FlightCapacity: 2})
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+		mockProvider.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return(nil, nil)
+		launchSchedule := svc.CheckLaunchSchedule(context.Background(), launchPadID, date)
+
+		mockTx.EXPECT().
+			CountBookingsByLaunchDate(gomock.Any(), launchPadID, date, date.AddDate(0, 0, 1)).
+			Return([]models.LaunchDateBookings{{LaunchDate: date, Bookings: 2}}, nil)
+		result, err := svc.IsDateAvailableIn(context.Background(), mockTx, launchSchedule, launchPadID, "", date)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AvailabilityResult{Reasons: []models.UnavailabilityReason{{
+			Type:        models.ReasonFlightFull,
+			Description: "2 of 2 seats booked",
+			Seats:       &models.Seats{Booked: 2, Capacity: 2},
+		}}}, result)
+	})
+
+	t.Run("the policy applies to the launch schedule checked before", func(t *testing.T) {
+		svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyAcceptProvisionally})
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(nil, &models.UpstreamUnavailableError{})
+		launchSchedule := svc.CheckLaunchSchedule(context.Background(), launchPadID, date)
+
+		result, err := svc.IsDateAvailableIn(context.Background(), mockTx, launchSchedule, launchPadID, "", date)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AvailabilityResult{Available: true, Provisional: true}, result)
+	})
+}
+
+func TestIsDateAvailable_Closures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockDB.EXPECT().ListBlackouts(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	const launchPadID = "5e9e4501f509094ba4566f84"
+	date := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+
+	t.Run("closed launch pad is not available even if the launch schedule is down", func(t *testing.T) {
+		svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyAcceptProvisionally})
+		mockDB.EXPECT().
+			ListClosures(gomock.Any(), launchPadID, date, date.AddDate(0, 0, 1)).
+			Return([]models.Closure{{LaunchPadID: launchPadID, StartsAt: date, EndsAt: date.Add(time.Hour), Summary: "Range maintenance", Source: "partner.ics"}}, nil)
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(nil, &models.UpstreamUnavailableError{})
+
+		result, err := svc.IsDateAvailable(context.Background(), launchPadID, "", date)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AvailabilityResult{Reasons: []models.UnavailabilityReason{{
+			Type:        models.ReasonClosure,
+			Description: "Range maintenance",
+			Window:      &models.ClosedWindow{StartsAt: date, EndsAt: date.Add(time.Hour), Source: "partner.ics"},
+		}}}, result)
+	})
+
+	t.Run("unable to list closures", func(t *testing.T) {
+		svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyFailClosed})
+		mockDB.EXPECT().
+			ListClosures(gomock.Any(), launchPadID, date, date.AddDate(0, 0, 1)).
+			Return(nil, errors.New("boom"))
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+		mockProvider.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return(nil, nil)
+
+		_, err := svc.IsDateAvailable(context.Background(), launchPadID, "", date)
+		assert.EqualError(t, err, "unable to list closures: boom")
+	})
+}
+
+func TestIsDateAvailable_FlightCapacity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockDB.EXPECT().ListBlackouts(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockDB.EXPECT().ListClosures(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyFailClosed, FlightCapacity: 2})
+	const launchPadID = "5e9e4501f509094ba4566f84"
+	date := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+
+	t.Run("seats left", func(t *testing.T) {
+		mockDB.EXPECT().
+			CountBookingsByLaunchDate(gomock.Any(), launchPadID, date, date.AddDate(0, 0, 1)).
+			Return([]models.LaunchDateBookings{{LaunchDate: date, Bookings: 1}}, nil)
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+		mockProvider.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return(nil, nil)
+
+		result, err := svc.IsDateAvailable(context.Background(), launchPadID, "", date)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AvailabilityResult{Available: true}, result)
+	})
+
+	t.Run("flight is full", func(t *testing.T) {
+		mockDB.EXPECT().
+			CountBookingsByLaunchDate(gomock.Any(), launchPadID, date, date.AddDate(0, 0, 1)).
+			Return([]models.LaunchDateBookings{{LaunchDate: date, Bookings: 2}}, nil)
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+		mockProvider.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return(nil, nil)
+
+		result, err := svc.IsDateAvailable(context.Background(), launchPadID, "", date)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AvailabilityResult{Reasons: []models.UnavailabilityReason{{
+			Type:        models.ReasonFlightFull,
+			Description: "2 of 2 seats booked",
+			Seats:       &models.Seats{Booked: 2, Capacity: 2},
+		}}}, result)
+	})
+
+	t.Run("unable to count bookings", func(t *testing.T) {
+		mockDB.EXPECT().
+			CountBookingsByLaunchDate(gomock.Any(), launchPadID, date, date.AddDate(0, 0, 1)).
+			Return(nil, errors.New("boom"))
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+		mockProvider.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return(nil, nil)
+
+		_, err := svc.IsDateAvailable(context.Background(), launchPadID, "", date)
+		assert.EqualError(t, err, "unable to count bookings: boom")
+	})
+
+	t.Run("a verified booking does not count against itself", func(t *testing.T) {
+		booking := models.Booking{LaunchPadID: launchPadID, DestinationID: "mars", LaunchDate: date}
+		mockDB.EXPECT().
+			CountBookingsByLaunchDate(gomock.Any(), launchPadID, date, date.AddDate(0, 0, 1)).
+			Return([]models.LaunchDateBookings{{LaunchDate: date, DestinationID: "mars", Bookings: 2}}, nil)
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+		mockProvider.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return(nil, nil)
+
+		result, err := svc.VerifyBooking(context.Background(), booking)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AvailabilityResult{Available: true}, result)
+	})
+}
+
+func TestIsDateAvailable_Destination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockDB.EXPECT().ListBlackouts(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockDB.EXPECT().ListClosures(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyFailClosed})
+	const launchPadID = "5e9e4501f509094ba4566f84"
+	date := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+
+	t.Run("flight goes to the destination", func(t *testing.T) {
+		mockDB.EXPECT().
+			CountBookingsByLaunchDate(gomock.Any(), launchPadID, date, date.AddDate(0, 0, 1)).
+			Return([]models.LaunchDateBookings{{LaunchDate: date, DestinationID: "mars", Bookings: 3}}, nil)
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+		mockProvider.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return(nil, nil)
+
+		result, err := svc.IsDateAvailable(context.Background(), launchPadID, "mars", date)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AvailabilityResult{Available: true}, result)
+	})
+
+	t.Run("flight goes to another destination", func(t *testing.T) {
+		mockDB.EXPECT().
+			CountBookingsByLaunchDate(gomock.Any(), launchPadID, date, date.AddDate(0, 0, 1)).
+			Return([]models.LaunchDateBookings{{LaunchDate: date, DestinationID: "moon", Bookings: 1}}, nil)
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+		mockProvider.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).Return(nil, nil)
+
+		result, err := svc.IsDateAvailable(context.Background(), launchPadID, "mars", date)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AvailabilityResult{Reasons: []models.UnavailabilityReason{{
+			Type:          models.ReasonDestinationConflict,
+			Description:   "flight goes to moon",
+			DestinationID: "moon",
+		}}}, result)
+	})
+}
+
+func TestIsDateAvailable_Reasons(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockDB.EXPECT().ListBlackouts(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyFailClosed, FlightCapacity: 1})
+	const launchPadID = "5e9e4501f509094ba4566f84"
+	date := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+
+	mockDB.EXPECT().ListClosures(gomock.Any(), launchPadID, date, date.AddDate(0, 0, 1)).
+		Return([]models.Closure{{LaunchPadID: launchPadID, StartsAt: date, EndsAt: date.AddDate(0, 0, 1), Summary: "Range maintenance"}}, nil)
+	mockDB.EXPECT().CountBookingsByLaunchDate(gomock.Any(), launchPadID, date, date.AddDate(0, 0, 1)).
+		Return([]models.LaunchDateBookings{{LaunchDate: date, DestinationID: "moon", Bookings: 1}}, nil)
+	mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: "retired"}, nil)
+	mockProvider.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).
+		Return([]schedule.Launch{{ID: "launch-1", Name: "Starlink", DateUTC: date.Add(time.Hour), Provider: "spacex"}}, nil)
+
+	result, err := svc.IsDateAvailable(context.Background(), launchPadID, "mars", date)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AvailabilityResult{Reasons: []models.UnavailabilityReason{
+		{
+			Type:        models.ReasonClosure,
+			Description: "Range maintenance",
+			Window:      &models.ClosedWindow{StartsAt: date, EndsAt: date.AddDate(0, 0, 1)},
+		},
+		{Type: models.ReasonDestinationConflict, Description: "flight goes to moon", DestinationID: "moon"},
+		{Type: models.ReasonFlightFull, Description: "1 of 1 seats booked", Seats: &models.Seats{Booked: 1, Capacity: 1}},
+		{Type: models.ReasonLaunchPadInactive, Description: "launch pad is retired", LaunchPadStatus: "retired"},
+		{
+			Type:        models.ReasonLaunch,
+			Description: "Starlink",
+			Launch:      &models.ConflictingLaunch{ID: "launch-1", Name: "Starlink", DateUTC: date.Add(time.Hour), Provider: "spacex"},
+		},
+	}}, result)
+}
+
+func TestIsDateAvailable_Blackouts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockDB.EXPECT().ListClosures(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyAcceptProvisionally})
+	launchPadID := "5e9e4501f509094ba4566f84"
+	date := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+	end := date.AddDate(0, 0, 1)
+
+	// The blackout is honoured even when the launch schedule is unavailable
+	mockDB.EXPECT().ListBlackouts(gomock.Any(), models.BlackoutFilters{LaunchPadID: &launchPadID, From: &date, To: &end}).
+		Return([]models.Blackout{{StartsAt: date.Add(-time.Hour), EndsAt: date.Add(time.Hour), Reason: "Pad refurbishment"}}, nil)
+	mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(nil, models.ErrUpstreamUnavailable)
+
+	result, err := svc.IsDateAvailable(context.Background(), launchPadID, "", date)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AvailabilityResult{Reasons: []models.UnavailabilityReason{{
+		Type:        models.ReasonBlackout,
+		Description: "Pad refurbishment",
+		Window:      &models.ClosedWindow{StartsAt: date.Add(-time.Hour), EndsAt: date.Add(time.Hour)},
+	}}}, result)
+}
+
+func TestIsDateAvailable_Turnaround(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockDB.EXPECT().ListBlackouts(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockDB.EXPECT().ListClosures(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockProvider.EXPECT().GetLaunchPad(gomock.Any(), gomock.Any()).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil).AnyTimes()
+	svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyFailClosed, TurnaroundDays: 2})
+	const launchPadID = "5e9e4501f509094ba4566f84"
+	date := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+	starlink := schedule.Launch{ID: "launch-1", Name: "Starlink", DateUTC: date.AddDate(0, 0, -2).Add(13 * time.Hour), Provider: "spacex"}
+	crew := schedule.Launch{ID: "launch-2", Name: "Crew-9", DateUTC: date.Add(time.Hour), Provider: "spacex"}
+
+	t.Run("launches around the date", func(t *testing.T) {
+		mockProvider.EXPECT().GetLaunchesForRange(gomock.Any(), launchPadID, date.AddDate(0, 0, -2), date.AddDate(0, 0, 3)).
+			Return([]schedule.Launch{starlink, crew}, nil)
+
+		result, err := svc.IsDateAvailable(context.Background(), launchPadID, "", date)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AvailabilityResult{Reasons: []models.UnavailabilityReason{
+			{
+				Type:        models.ReasonTurnaround,
+				Description: "turnaround after Starlink",
+				Launch:      &models.ConflictingLaunch{ID: "launch-1", Name: "Starlink", DateUTC: starlink.DateUTC, Provider: "spacex"},
+			},
+			{
+				Type:        models.ReasonLaunch,
+				Description: "Crew-9",
+				Launch:      &models.ConflictingLaunch{ID: "launch-2", Name: "Crew-9", DateUTC: crew.DateUTC, Provider: "spacex"},
+			},
+		}}, result)
+	})
+
+	t.Run("no launch around the date", func(t *testing.T) {
+		mockProvider.EXPECT().GetLaunchesForRange(gomock.Any(), launchPadID, date.AddDate(0, 0, 1), date.AddDate(0, 0, 6)).
+			Return(nil, nil)
+
+		result, err := svc.IsDateAvailable(context.Background(), launchPadID, "", date.AddDate(0, 0, 3))
+		assert.NoError(t, err)
+		assert.Equal(t, models.AvailabilityResult{Available: true}, result)
+	})
+}
+
+func TestCheckBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockDB.EXPECT().ListBlackouts(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockDB.EXPECT().ListClosures(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyFailClosed, BatchConcurrency: 2})
+	date := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+
+	mockProvider.EXPECT().GetLaunchPad(gomock.Any(), "pad-1").Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil).Times(2)
+	mockProvider.EXPECT().GetLaunchPad(gomock.Any(), "unknown").Return(nil, models.ErrNotFoundLaunchpad)
+	mockProvider.EXPECT().GetLaunchesForDate(gomock.Any(), "pad-1", date).
+		Return([]schedule.Launch{{Name: "Starlink", DateUTC: date}}, nil)
+	mockProvider.EXPECT().GetLaunchesForDate(gomock.Any(), "pad-1", date.AddDate(0, 0, 1)).Return(nil, nil)
+
+	results := svc.CheckBatch(context.Background(), []models.AvailabilityCheck{
+		{LaunchPadID: "pad-1", Date: date},
+		{LaunchPadID: "unknown", Date: date},
+		{LaunchPadID: "pad-1", Date: date.AddDate(0, 0, 1)},
+	})
+	assert.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.False(t, results[0].Result.Available)
+	assert.ErrorIs(t, results[1].Err, models.ErrNotFoundLaunchpad, "a failed check does not fail the batch")
+	assert.NoError(t, results[2].Err)
+	assert.True(t, results[2].Result.Available)
+}
+
+func TestCalendar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockDB.EXPECT().ListBlackouts(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyFailClosed, FlightCapacity: 2, MaxCalendarDays: 31})
+	const launchPadID = "5e9e4501f509094ba4566f84"
+	from := time.Date(2049, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2049, 7, 5, 0, 0, 0, 0, time.UTC)
+	end := to.AddDate(0, 0, 1)
+	day := func(d int) time.Time {
+		return from.AddDate(0, 0, d)
+	}
+
+	t.Run("reasons per day", func(t *testing.T) {
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+		mockDB.EXPECT().
+			ListClosures(gomock.Any(), launchPadID, from, end).
+			Return([]models.Closure{
+				// Overlaps the start of the range and ends exclusively on day 1
+				{LaunchPadID: launchPadID, StartsAt: day(-1), EndsAt: day(1), Summary: "Range maintenance"},
+			}, nil)
+		mockDB.EXPECT().
+			CountBookingsByLaunchDate(gomock.Any(), launchPadID, from, end).
+			Return([]models.LaunchDateBookings{
+				{LaunchDate: day(2), Bookings: 2},
+				{LaunchDate: day(3), Bookings: 1},
+			}, nil)
+		mockProvider.EXPECT().
+			GetLaunchesForRange(gomock.Any(), launchPadID, from, end).
+			Return([]schedule.Launch{
+				{Name: "Starlink", DateUTC: day(2).Add(13 * time.Hour), LaunchPadID: launchPadID},
+				{Name: "Crew-9", DateUTC: day(4).Add(time.Hour), LaunchPadID: launchPadID},
+			}, nil)
+
+		calendar, err := svc.Calendar(context.Background(), launchPadID, "", from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.DayAvailability{
+			{Date: day(0), Reasons: []models.UnavailabilityReason{{
+				Type:        models.ReasonClosure,
+				Description: "Range maintenance",
+				Window:      &models.ClosedWindow{StartsAt: day(-1), EndsAt: day(1)},
+			}}},
+			{Date: day(1), Available: true},
+			{Date: day(2), Reasons: []models.UnavailabilityReason{
+				{Type: models.ReasonFlightFull, Description: "2 of 2 seats booked", Seats: &models.Seats{Booked: 2, Capacity: 2}},
+				{
+					Type:        models.ReasonLaunch,
+					Description: "Starlink",
+					Launch:      &models.ConflictingLaunch{Name: "Starlink", DateUTC: day(2).Add(13 * time.Hour)},
+				},
+			}},
+			{Date: day(3), Available: true},
+			{Date: day(4), Reasons: []models.UnavailabilityReason{{
+				Type:        models.ReasonLaunch,
+				Description: "Crew-9",
+				Launch:      &models.ConflictingLaunch{Name: "Crew-9", DateUTC: day(4).Add(time.Hour)},
+			}}},
+		}, calendar)
+	})
+
+	t.Run("flights to other destinations", func(t *testing.T) {
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+		mockDB.EXPECT().ListClosures(gomock.Any(), launchPadID, from, day(1)).Return(nil, nil)
+		mockDB.EXPECT().
+			CountBookingsByLaunchDate(gomock.Any(), launchPadID, from, day(1)).
+			Return([]models.LaunchDateBookings{
+				{LaunchDate: day(0), DestinationID: "mars", Bookings: 1},
+				{LaunchDate: day(0), DestinationID: "moon", Bookings: 1},
+			}, nil)
+		mockProvider.EXPECT().GetLaunchesForRange(gomock.Any(), launchPadID, from, day(1)).Return(nil, nil)
+
+		calendar, err := svc.Calendar(context.Background(), launchPadID, "mars", from, from)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.DayAvailability{
+			{Date: day(0), Reasons: []models.UnavailabilityReason{
+				{Type: models.ReasonDestinationConflict, Description: "flight goes to moon", DestinationID: "moon"},
+				{Type: models.ReasonFlightFull, Description: "2 of 2 seats booked", Seats: &models.Seats{Booked: 2, Capacity: 2}},
+			}},
+		}, calendar)
+	})
+
+	t.Run("inactive launch pad", func(t *testing.T) {
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: "retired"}, nil)
+		mockDB.EXPECT().ListClosures(gomock.Any(), launchPadID, from, day(1)).Return(nil, nil)
+		mockDB.EXPECT().CountBookingsByLaunchDate(gomock.Any(), launchPadID, from, day(1)).Return(nil, nil)
+		mockProvider.EXPECT().GetLaunchesForRange(gomock.Any(), launchPadID, from, day(1)).Return(nil, nil)
+
+		calendar, err := svc.Calendar(context.Background(), launchPadID, "", from, from)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.DayAvailability{
+			{Date: day(0), Reasons: []models.UnavailabilityReason{
+				{Type: models.ReasonLaunchPadInactive, Description: "launch pad is retired", LaunchPadStatus: "retired"},
+			}},
+		}, calendar)
+	})
+
+	t.Run("to before from", func(t *testing.T) {
+		_, err := svc.Calendar(context.Background(), launchPadID, "", to, from)
+		assert.ErrorIs(t, err, models.ErrInvalidRange)
+	})
+
+	t.Run("range too long", func(t *testing.T) {
+		_, err := svc.Calendar(context.Background(), launchPadID, "", from, from.AddDate(0, 0, 31))
+		assert.ErrorIs(t, err, models.ErrInvalidRange)
+	})
+
+	t.Run("unknown launch pad", func(t *testing.T) {
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(nil, models.ErrNotFoundLaunchpad)
+
+		_, err := svc.Calendar(context.Background(), launchPadID, "", from, to)
+		assert.ErrorIs(t, err, models.ErrNotFoundLaunchpad)
+	})
+
+	t.Run("launch schedule unavailable", func(t *testing.T) {
+		mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+		mockDB.EXPECT().ListClosures(gomock.Any(), launchPadID, from, end).Return(nil, nil)
+		mockDB.EXPECT().CountBookingsByLaunchDate(gomock.Any(), launchPadID, from, end).Return(nil, nil)
+		mockProvider.EXPECT().
+			GetLaunchesForRange(gomock.Any(), launchPadID, from, end).
+			Return(nil, &models.UpstreamUnavailableError{})
+
+		_, err := svc.Calendar(context.Background(), launchPadID, "", from, to)
+		assert.ErrorIs(t, err, models.ErrUpstreamUnavailable)
+	})
+}
+
+func TestCalendar_BlackoutsAndTurnaround(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClock(), availability.Config{Policy: availability.PolicyFailClosed, TurnaroundDays: 1})
+	launchPadID := "5e9e4501f509094ba4566f84"
+	from := time.Date(2049, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2049, 7, 5, 0, 0, 0, 0, time.UTC)
+	end := to.AddDate(0, 0, 1)
+	day := func(d int) time.Time {
+		return from.AddDate(0, 0, d)
+	}
+	blackout := models.Blackout{StartsAt: day(3), EndsAt: day(3).Add(time.Hour), Reason: "Pad refurbishment"}
+	// Launched the day before the calendar, its turnaround blocks the first day
+	before := schedule.Launch{Name: "Starlink", DateUTC: day(-1).Add(13 * time.Hour)}
+	crew := schedule.Launch{Name: "Crew-9", DateUTC: day(4).Add(time.Hour)}
+
+	mockProvider.EXPECT().GetLaunchPad(gomock.Any(), launchPadID).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil)
+	mockDB.EXPECT().ListClosures(gomock.Any(), launchPadID, from, end).Return(nil, nil)
+	mockDB.EXPECT().ListBlackouts(gomock.Any(), models.BlackoutFilters{LaunchPadID: &launchPadID, From: &from, To: &end}).
+		Return([]models.Blackout{blackout}, nil)
+	mockProvider.EXPECT().GetLaunchesForRange(gomock.Any(), launchPadID, day(-1), end.AddDate(0, 0, 1)).
+		Return([]schedule.Launch{before, crew}, nil)
+
+	calendar, err := svc.Calendar(context.Background(), launchPadID, "", from, to)
+	assert.NoError(t, err)
+	turnaround := func(launch schedule.Launch, description string) models.UnavailabilityReason {
+		return models.UnavailabilityReason{
+			Type:        models.ReasonTurnaround,
+			Description: description,
+			Launch:      &models.ConflictingLaunch{Name: launch.Name, DateUTC: launch.DateUTC},
+		}
+	}
+	assert.Equal(t, []models.DayAvailability{
+		{Date: day(0), Reasons: []models.UnavailabilityReason{turnaround(before, "turnaround after Starlink")}},
+		{Date: day(1), Available: true},
+		{Date: day(2), Available: true},
+		{Date: day(3), Reasons: []models.UnavailabilityReason{
+			{
+				Type:        models.ReasonBlackout,
+				Description: "Pad refurbishment",
+				Window:      &models.ClosedWindow{StartsAt: day(3), EndsAt: day(3).Add(time.Hour)},
+			},
+			turnaround(crew, "turnaround before Crew-9"),
+		}},
+		{Date: day(4), Reasons: []models.UnavailabilityReason{{
+			Type:        models.ReasonLaunch,
+			Description: "Crew-9",
+			Launch:      &models.ConflictingLaunch{Name: "Crew-9", DateUTC: crew.DateUTC},
+		}}},
+	}, calendar)
+}
+
+func TestSuggest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := mocks.NewMockLaunchScheduleProvider(ctrl)
+	mockDB := mocks.NewMockDatabase(ctrl)
+	mockDB.EXPECT().ListBlackouts(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockDB.EXPECT().CountBookingsByLaunchDate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	const launchPadID = "5e9e4501f509094ba4566f84"
+	date := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time {
+		return date.AddDate(0, 0, d)
+	}
+	// Today is two days before the date, so the window of three days only looks back that far
+	svc := availability.New(mockProvider, mockDB, clockwork.NewFakeClockAt(day(-2).Add(10*time.Hour)), availability.Config{
+		Policy:               availability.PolicyFailClosed,
+		SuggestionCount:      3,
+		SuggestionWindowDays: 3,
+	})
+
+	mockProvider.EXPECT().GetLaunchPad(gomock.Any(), gomock.Any()).Return(&schedule.LaunchPad{Status: models.LaunchPadStatusActive}, nil).AnyTimes()
+	mockDB.EXPECT().ListClosures(gomock.Any(), launchPadID, day(-2), day(4)).
+		Return([]models.Closure{{LaunchPadID: launchPadID, StartsAt: day(1), EndsAt: day(2)}}, nil)
+	mockProvider.EXPECT().GetLaunchesForRange(gomock.Any(), launchPadID, day(-2), day(4)).
+		Return([]schedule.Launch{{Name: "Starlink", DateUTC: day(-1).Add(time.Hour)}}, nil)
+
+	mockProvider.EXPECT().GetLaunchPads(gomock.Any()).Return([]schedule.LaunchPad{
+		{ID: launchPadID, Status: models.LaunchPadStatusActive},
+		{ID: "free", Status: models.LaunchPadStatusActive},
+		{ID: "retired", Status: "retired"},
+		{ID: "launching", Status: models.LaunchPadStatusActive},
+		{ID: "failing", Status: models.LaunchPadStatusActive},
+	}, nil)
+	mockDB.EXPECT().ListClosures(gomock.Any(), gomock.Any(), date, day(1)).Return(nil, nil).Times(3)
+	mockProvider.EXPECT().GetLaunchesForRange(gomock.Any(), "free", date, day(1)).Return(nil, nil)
+	mockProvider.EXPECT().GetLaunchesForRange(gomock.Any(), "launching", date, day(1)).
+		Return([]schedule.Launch{{Name: "Crew-9", DateUTC: date.Add(time.Hour)}}, nil)
+	mockProvider.EXPECT().GetLaunchesForRange(gomock.Any(), "failing", date, day(1)).
+		Return(nil, &models.UpstreamUnavailableError{})
+
+	suggestions, err := svc.Suggest(context.Background(), launchPadID, "mars", date.Add(9*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, models.Suggestions{
+		Dates:        []time.Time{day(-2), day(2), day(3)},
+		LaunchPadIDs: []string{"free"},
+	}, suggestions)
+}
@@ -2,13 +2,17 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/service/availability"
 
 	"github.com/jonboulle/clockwork"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/auth"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
 )
@@ -16,8 +20,27 @@ import (
 //go:generate mockgen -package=mocks -destination=../mocks/service.go github.com/zsoltggs/tabeo-interview/services/bookings/internal/service Service
 type Service interface {
 	CreateBooking(ctx context.Context, createBooking models.CreateBooking) (*models.Booking, error)
+	GetBooking(ctx context.Context, bookingID uuid.UUID) (*models.Booking, error)
 	ListBookings(ctx context.Context, filters models.Filters, pagination models.Pagination) ([]models.Booking, error)
-	DeleteBooking(ctx context.Context, bookingID uuid.UUID) error
+	// UpdateBooking changes the details of the passenger if the booking is still at version,
+	// it returns database.ErrVersionMismatch otherwise
+	UpdateBooking(ctx context.Context, bookingID uuid.UUID, version int, update models.UpdateBooking) (*models.Booking, error)
+	// DeleteBooking deletes the booking if it is still at version, it returns database.ErrVersionMismatch otherwise
+	DeleteBooking(ctx context.Context, bookingID uuid.UUID, version int) error
+	// GetBookingHistory returns the changes of the booking, oldest first, including the ones of a deleted booking.
+	// It returns database.ErrNotFound for a booking without history.
+	GetBookingHistory(ctx context.Context, bookingID uuid.UUID) ([]models.AuditEntry, error)
+	// VerifyProvisionalBookings checks provisional bookings against the launch schedule again,
+	// confirming or rejecting them once it is reachable
+	VerifyProvisionalBookings(ctx context.Context) error
+}
+
+const verifyBatchSize = 100
+
+// Config configures the bookings service
+type Config struct {
+	// LockTimeout bounds the wait for the concurrent bookings of the same launch pad and date, zero waits forever
+	LockTimeout time.Duration
 }
 
 type service struct {
@@ -25,31 +48,75 @@ type service struct {
 	availabilitySvc availability.Availability
 	clock           clockwork.Clock
 	uuidGenerator   func() uuid.UUID
+	config          Config
 }
 
 func New(db database.Database,
 	availabilitySvc availability.Availability,
 	clock clockwork.Clock,
-	uuidGenerator func() uuid.UUID) Service {
+	uuidGenerator func() uuid.UUID,
+	config Config) Service {
 	return &service{
 		db:              db,
 		availabilitySvc: availabilitySvc,
 		clock:           clock,
 		uuidGenerator:   uuidGenerator,
+		config:          config,
 	}
 }
 
+// CreateBooking checks the availability and creates the booking while holding the lock of the launch pad and date,
+// so concurrent bookings of the same flight, from any replica, cannot both take its last seat
 func (s *service) CreateBooking(ctx context.Context, create models.CreateBooking) (*models.Booking, error) {
-	isAvailable, err := s.availabilitySvc.IsDateAvailable(ctx, create.LaunchPadID, create.LaunchDate)
+	// The launch schedule providers are asked before taking the lock, so it is not held while waiting for them
+	launchSchedule := s.availabilitySvc.CheckLaunchSchedule(ctx, create.LaunchPadID, create.LaunchDate)
+	var availability models.AvailabilityResult
+	var result *models.Booking
+	err := s.db.WithTx(ctx, func(tx database.Database) error {
+		err := tx.LockLaunchSlot(ctx, create.LaunchPadID, create.LaunchDate, s.config.LockTimeout)
+		if errors.Is(err, database.ErrLockTimeout) {
+			return fmt.Errorf("%w: %w", models.ErrSlotBusy, err)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot lock launch slot: %w", err)
+		}
+		// Bookings committed by the previous holder of the lock are counted, as they are read after acquiring it.
+		// They are read through the transaction, which holds the connection of the lock, rather than another connection.
+		availability, err = s.availabilitySvc.IsDateAvailableIn(ctx, tx, launchSchedule,
+			create.LaunchPadID, create.DestinationID, create.LaunchDate)
+		if err != nil {
+			return fmt.Errorf("cannot determine availability: %w", err)
+		}
+		if !availability.Available {
+			return nil
+		}
+		result, err = s.create(ctx, tx, create, availability)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("cannot determine availability: %w", err)
+		return nil, err
 	}
-	if !isAvailable {
-		return nil, models.ErrNotAvailable
+	if !availability.Available {
+		log.WithFields(log.Fields{
+			"launch_pad_id":  create.LaunchPadID,
+			"destination_id": create.DestinationID,
+			"date":           create.LaunchDate.Format("2006-01-02"),
+			"reasons":        availability.Reasons,
+		}).Info("date is unavailable")
+		return nil, s.notAvailable(ctx, create, availability.Reasons)
+	}
+	return result, nil
+}
+
+func (s *service) create(ctx context.Context, tx database.Database, create models.CreateBooking, availability models.AvailabilityResult) (*models.Booking, error) {
+	status := models.BookingStatusConfirmed
+	if availability.Provisional {
+		status = models.BookingStatusProvisional
 	}
 	now := s.clock.Now()
 	result := models.Booking{
 		ID:            s.uuidGenerator(),
+		Status:        status,
 		FirstName:     create.FirstName,
 		LastName:      create.LastName,
 		Gender:        create.Gender,
@@ -59,14 +126,57 @@ func (s *service) CreateBooking(ctx context.Context, create models.CreateBooking
 		LaunchDate:    create.LaunchDate,
 		CreatedAt:     now,
 		UpdatedAt:     now,
+		Version:       1,
 	}
-	err = s.db.Create(ctx, result)
+	err := tx.Create(ctx, result)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create booking: %w", err)
 	}
+	err = s.audit(ctx, tx, models.AuditActionCreated, result.ID, nil, &result)
+	if err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
+// audit appends a change of the booking to its history, tx must be the transaction of the change
+// so the history has the changes that were committed only
+func (s *service) audit(ctx context.Context, tx database.Database, action string, bookingID uuid.UUID, before *models.Booking, after *models.Booking) error {
+	err := tx.AppendAudit(ctx, models.AuditEntry{
+		BookingID:  bookingID,
+		Action:     action,
+		Actor:      auth.ActorFromContext(ctx),
+		OccurredAt: s.clock.Now(),
+		Before:     before,
+		After:      after,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot audit booking: %w", err)
+	}
+	return nil
+}
+
+// notAvailable returns a models.NotAvailableError with the reasons and alternatives, suggesting them is best effort
+func (s *service) notAvailable(ctx context.Context, create models.CreateBooking, reasons []models.UnavailabilityReason) error {
+	suggestions, err := s.availabilitySvc.Suggest(ctx, create.LaunchPadID, create.DestinationID, create.LaunchDate)
+	if err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"launch_pad_id": create.LaunchPadID,
+			"date":          create.LaunchDate.Format("2006-01-02"),
+		}).Warn("unable to suggest alternatives")
+		return &models.NotAvailableError{Reasons: reasons}
+	}
+	return &models.NotAvailableError{Reasons: reasons, Suggestions: &suggestions}
+}
+
+func (s *service) GetBooking(ctx context.Context, bookingID uuid.UUID) (*models.Booking, error) {
+	booking, err := s.db.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get booking: %w", err)
+	}
+	return booking, nil
+}
+
 func (s *service) ListBookings(ctx context.Context, filters models.Filters, pagination models.Pagination) ([]models.Booking, error) {
 	results, err := s.db.List(ctx, pagination, filters)
 	if err != nil {
@@ -75,10 +185,147 @@ func (s *service) ListBookings(ctx context.Context, filters models.Filters, pagi
 	return results, nil
 }
 
-func (s *service) DeleteBooking(ctx context.Context, bookingID uuid.UUID) error {
-	err := s.db.Delete(ctx, bookingID)
+func (s *service) UpdateBooking(ctx context.Context, bookingID uuid.UUID, version int, update models.UpdateBooking) (*models.Booking, error) {
+	var result *models.Booking
+	err := s.db.WithTx(ctx, func(tx database.Database) error {
+		before, err := tx.GetByID(ctx, bookingID)
+		if err != nil {
+			return fmt.Errorf("unable to get booking: %w", err)
+		}
+		if before.Version != version {
+			return fmt.Errorf("unable to update booking: %w", database.ErrVersionMismatch)
+		}
+		booking := *before
+		if update.FirstName != nil {
+			booking.FirstName = *update.FirstName
+		}
+		if update.LastName != nil {
+			booking.LastName = *update.LastName
+		}
+		if update.Gender != nil {
+			booking.Gender = *update.Gender
+		}
+		if update.Birthday != nil {
+			booking.Birthday = *update.Birthday
+		}
+		booking.UpdatedAt = s.clock.Now()
+		// The version is checked again by the update, the booking may have changed since it was read
+		err = tx.Update(ctx, booking)
+		if err != nil {
+			return fmt.Errorf("unable to update booking: %w", err)
+		}
+		booking.Version++
+		result = &booking
+		return s.audit(ctx, tx, models.AuditActionUpdated, bookingID, before, &booking)
+	})
 	if err != nil {
-		return fmt.Errorf("unable to delete booking: %w", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) DeleteBooking(ctx context.Context, bookingID uuid.UUID, version int) error {
+	return s.db.WithTx(ctx, func(tx database.Database) error {
+		before, err := tx.GetByID(ctx, bookingID)
+		if err != nil {
+			return fmt.Errorf("unable to get booking: %w", err)
+		}
+		err = tx.Delete(ctx, bookingID, version)
+		if err != nil {
+			return fmt.Errorf("unable to delete booking: %w", err)
+		}
+		// The delete only matched the booking if it was still at version, so before is what was deleted
+		return s.audit(ctx, tx, models.AuditActionDeleted, bookingID, before, nil)
+	})
+}
+
+func (s *service) GetBookingHistory(ctx context.Context, bookingID uuid.UUID) ([]models.AuditEntry, error) {
+	entries, err := s.db.ListAudit(ctx, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get booking history: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("unable to get booking history: %w", database.ErrNotFound)
+	}
+	return entries, nil
+}
+
+func (s *service) VerifyProvisionalBookings(ctx context.Context) error {
+	provisional := models.BookingStatusProvisional
+	for {
+		// Verified bookings drop out of the filter, so the first page is always the next batch
+		bookings, err := s.db.List(ctx, models.Pagination{Limit: verifyBatchSize}, models.Filters{
+			Status: &provisional,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to list provisional bookings: %w", err)
+		}
+		for _, booking := range bookings {
+			status, err := s.verifyBooking(ctx, booking)
+			if err != nil {
+				return err
+			}
+			if status == models.BookingStatusProvisional {
+				// The launch schedule is still unavailable, try again on the next run
+				return nil
+			}
+			err = s.updateStatus(ctx, booking, status)
+			if err != nil {
+				return err
+			}
+			log.WithFields(log.Fields{
+				"booking_id": booking.ID,
+				"status":     status,
+			}).Info("provisional booking verified")
+		}
+		if len(bookings) < verifyBatchSize {
+			return nil
+		}
+	}
+}
+
+// updateStatus sets the status of the provisional booking as the system, a booking deleted in the meantime is skipped
+func (s *service) updateStatus(ctx context.Context, booking models.Booking, status string) error {
+	ctx = auth.WithActor(ctx, auth.SystemActor)
+	err := s.db.WithTx(ctx, func(tx database.Database) error {
+		before, err := tx.GetByID(ctx, booking.ID)
+		if err != nil {
+			return fmt.Errorf("unable to get booking: %w", err)
+		}
+		after := *before
+		after.Status = status
+		after.UpdatedAt = s.clock.Now()
+		after.Version++
+		err = tx.UpdateStatus(ctx, booking.ID, status, after.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("unable to update booking status: %w", err)
+		}
+		return s.audit(ctx, tx, models.AuditActionUpdated, booking.ID, before, &after)
+	})
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return err
 	}
 	return nil
 }
+
+func (s *service) verifyBooking(ctx context.Context, booking models.Booking) (string, error) {
+	availability, err := s.availabilitySvc.VerifyBooking(ctx, booking)
+	switch {
+	case errors.Is(err, models.ErrUpstreamUnavailable):
+		return models.BookingStatusProvisional, nil
+	case errors.Is(err, models.ErrNotFoundLaunchpad):
+		return models.BookingStatusRejected, nil
+	case err != nil:
+		return "", fmt.Errorf("cannot determine availability: %w", err)
+	case availability.Provisional:
+		return models.BookingStatusProvisional, nil
+	case !availability.Available:
+		log.WithFields(log.Fields{
+			"booking_id": booking.ID,
+			"reasons":    availability.Reasons,
+		}).Info("provisional booking is unavailable")
+		return models.BookingStatusRejected, nil
+	default:
+		return models.BookingStatusConfirmed, nil
+	}
+}
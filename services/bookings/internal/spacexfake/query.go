@@ -0,0 +1,338 @@
+package spacexfake
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Document is a launch or a launchpad as it is returned by the SpaceX API
+type Document map[string]interface{}
+
+// QueryRequest is the body of the /query endpoints
+type QueryRequest struct {
+	Query   map[string]interface{} `json:"query"`
+	Options QueryOptions           `json:"options"`
+}
+
+// QueryOptions supports the subset of the mongoose-paginate options used by the SpaceX API clients
+type QueryOptions struct {
+	Limit      *int                   `json:"limit"`
+	Page       *int                   `json:"page"`
+	Offset     *int                   `json:"offset"`
+	Sort       map[string]interface{} `json:"sort"`
+	Pagination *bool                  `json:"pagination"`
+}
+
+// QueryResponse mirrors the paginated response of the SpaceX API
+type QueryResponse struct {
+	Docs          []Document `json:"docs"`
+	TotalDocs     int        `json:"totalDocs"`
+	Offset        int        `json:"offset"`
+	Limit         int        `json:"limit"`
+	TotalPages    int        `json:"totalPages"`
+	Page          int        `json:"page"`
+	PagingCounter int        `json:"pagingCounter"`
+	HasPrevPage   bool       `json:"hasPrevPage"`
+	HasNextPage   bool       `json:"hasNextPage"`
+	PrevPage      *int       `json:"prevPage"`
+	NextPage      *int       `json:"nextPage"`
+}
+
+const defaultLimit = 10
+
+// Run filters, sorts and paginates the documents
+func Run(docs []Document, req QueryRequest) (QueryResponse, error) {
+	var matched []Document
+	for _, doc := range docs {
+		ok, err := matches(doc, req.Query)
+		if err != nil {
+			return QueryResponse{}, err
+		}
+		if ok {
+			matched = append(matched, doc)
+		}
+	}
+	sortDocuments(matched, req.Options.Sort)
+
+	total := len(matched)
+	if req.Options.Pagination != nil && !*req.Options.Pagination {
+		return QueryResponse{
+			Docs:          nonNil(matched),
+			TotalDocs:     total,
+			Limit:         total,
+			TotalPages:    1,
+			Page:          1,
+			PagingCounter: 1,
+		}, nil
+	}
+
+	limit := defaultLimit
+	if req.Options.Limit != nil && *req.Options.Limit > 0 {
+		limit = *req.Options.Limit
+	}
+	page := 1
+	if req.Options.Page != nil && *req.Options.Page > 0 {
+		page = *req.Options.Page
+	}
+	offset := (page - 1) * limit
+	if req.Options.Offset != nil && *req.Options.Offset >= 0 {
+		offset = *req.Options.Offset
+		page = offset/limit + 1
+	}
+
+	totalPages := (total + limit - 1) / limit
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	start := offset
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	resp := QueryResponse{
+		Docs:          nonNil(matched[start:end]),
+		TotalDocs:     total,
+		Offset:        offset,
+		Limit:         limit,
+		TotalPages:    totalPages,
+		Page:          page,
+		PagingCounter: offset + 1,
+		HasPrevPage:   page > 1,
+		HasNextPage:   page < totalPages,
+	}
+	if resp.HasPrevPage {
+		prev := page - 1
+		resp.PrevPage = &prev
+	}
+	if resp.HasNextPage {
+		next := page + 1
+		resp.NextPage = &next
+	}
+	return resp, nil
+}
+
+func matches(doc Document, query map[string]interface{}) (bool, error) {
+	for key, condition := range query {
+		var ok bool
+		var err error
+		switch key {
+		case "$and", "$or":
+			ok, err = matchesLogical(doc, key, condition)
+		default:
+			ok, err = matchesField(lookup(doc, key), condition)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchesLogical(doc Document, operator string, condition interface{}) (bool, error) {
+	clauses, ok := condition.([]interface{})
+	if !ok {
+		return false, fmt.Errorf("%s expects an array", operator)
+	}
+	for _, clause := range clauses {
+		query, ok := clause.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s expects an array of objects", operator)
+		}
+		ok, err := matches(doc, query)
+		if err != nil {
+			return false, err
+		}
+		if operator == "$or" && ok {
+			return true, nil
+		}
+		if operator == "$and" && !ok {
+			return false, nil
+		}
+	}
+	return operator == "$and", nil
+}
+
+func matchesField(value interface{}, condition interface{}) (bool, error) {
+	operators, ok := condition.(map[string]interface{})
+	if !ok || !isOperatorObject(operators) {
+		return equal(value, condition), nil
+	}
+	for operator, operand := range operators {
+		var ok bool
+		switch operator {
+		case "$eq":
+			ok = equal(value, operand)
+		case "$ne":
+			ok = !equal(value, operand)
+		case "$gt", "$gte", "$lt", "$lte":
+			ok = compareWith(value, operand, operator)
+		case "$in", "$nin":
+			values, isArray := operand.([]interface{})
+			if !isArray {
+				return false, fmt.Errorf("%s expects an array", operator)
+			}
+			found := false
+			for _, v := range values {
+				if equal(value, v) {
+					found = true
+					break
+				}
+			}
+			ok = found == (operator == "$in")
+		case "$exists":
+			exists, _ := operand.(bool)
+			ok = (value != nil) == exists
+		default:
+			return false, fmt.Errorf("unsupported operator %s", operator)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func isOperatorObject(m map[string]interface{}) bool {
+	if len(m) == 0 {
+		return false
+	}
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup supports dotted paths, e.g. cores.core
+func lookup(doc Document, path string) interface{} {
+	var current interface{} = map[string]interface{}(doc)
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
+
+func equal(a, b interface{}) bool {
+	// An array field matches if any of its elements match
+	if values, ok := a.([]interface{}); ok {
+		for _, v := range values {
+			if equal(v, b) {
+				return true
+			}
+		}
+		return false
+	}
+	c, ok := compare(a, b)
+	return ok && c == 0
+}
+
+func compareWith(value, operand interface{}, operator string) bool {
+	c, ok := compare(value, operand)
+	if !ok {
+		return false
+	}
+	switch operator {
+	case "$gt":
+		return c > 0
+	case "$gte":
+		return c >= 0
+	case "$lt":
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+// compare orders two JSON values, dates are compared as points in time
+func compare(a, b interface{}) (int, bool) {
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		at, aErr := time.Parse(time.RFC3339, av)
+		bt, bErr := time.Parse(time.RFC3339, bv)
+		if aErr == nil && bErr == nil {
+			return at.Compare(bt), true
+		}
+		return strings.Compare(av, bv), true
+	case float64:
+		bv, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av < bv:
+			return -1, true
+		case av > bv:
+			return 1, true
+		default:
+			return 0, true
+		}
+	case bool:
+		bv, ok := b.(bool)
+		if !ok || av != bv {
+			return 0, false
+		}
+		return 0, true
+	case nil:
+		return 0, b == nil
+	default:
+		return 0, false
+	}
+}
+
+func sortDocuments(docs []Document, sortBy map[string]interface{}) {
+	if len(sortBy) == 0 {
+		return
+	}
+	fields := make([]string, 0, len(sortBy))
+	for field := range sortBy {
+		fields = append(fields, field)
+	}
+	// JSON objects are unordered, sort by field name to be deterministic
+	sort.Strings(fields)
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, field := range fields {
+			c, ok := compare(lookup(docs[i], field), lookup(docs[j], field))
+			if !ok || c == 0 {
+				continue
+			}
+			if isDescending(sortBy[field]) {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+func isDescending(direction interface{}) bool {
+	switch d := direction.(type) {
+	case string:
+		return d == "desc" || d == "descending" || d == "-1"
+	case float64:
+		return d < 0
+	default:
+		return false
+	}
+}
+
+func nonNil(docs []Document) []Document {
+	if docs == nil {
+		return []Document{}
+	}
+	return docs
+}
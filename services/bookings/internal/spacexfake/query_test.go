@@ -0,0 +1,111 @@
+package spacexfake
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDocuments(t *testing.T) []Document {
+	var docs []Document
+	err := json.Unmarshal([]byte(`[
+	{"id":"1","name":"Launch 1","date_utc":"2022-07-07T13:11:00.000Z","launchpad":"pad-1","success":true,"flight_number":1},
+	{"id":"2","name":"Launch 2","date_utc":"2022-07-07T23:59:59.999Z","launchpad":"pad-1","success":false,"flight_number":2},
+	{"id":"3","name":"Launch 3","date_utc":"2022-07-08T01:00:00.000Z","launchpad":"pad-1","success":true,"flight_number":3},
+	{"id":"4","name":"Launch 4","date_utc":"2022-07-07T10:00:00.000Z","launchpad":"pad-2","success":true,"flight_number":4,"cores":{"core":"core-1"}}
+]`), &docs)
+	require.NoError(t, err)
+	return docs
+}
+
+func ids(docs []Document) []string {
+	var result []string
+	for _, doc := range docs {
+		result = append(result, doc["id"].(string))
+	}
+	return result
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		request     string
+		expectedIDs []string
+		expectedErr string
+	}{
+		{
+			name:        "empty query returns everything",
+			request:     `{}`,
+			expectedIDs: []string{"1", "2", "3", "4"},
+		},
+		{
+			name: "date range as sent by the spacex client",
+			request: `{"query":{"launchpad":"pad-1","date_utc":{"$gte":"2022-07-07T00:00:00.000Z","$lt":"2022-07-07T23:59:59.999Z"}},
+				"options":{"limit":5}}`,
+			expectedIDs: []string{"1"},
+		},
+		{
+			name:        "inclusive upper bound",
+			request:     `{"query":{"launchpad":"pad-1","date_utc":{"$gte":"2022-07-07T00:00:00.000Z","$lte":"2022-07-07T23:59:59.999Z"}}}`,
+			expectedIDs: []string{"1", "2"},
+		},
+		{
+			name:        "in, ne and nested fields",
+			request:     `{"query":{"launchpad":{"$in":["pad-1","pad-2"]},"success":{"$ne":false},"cores.core":"core-1"}}`,
+			expectedIDs: []string{"4"},
+		},
+		{
+			name:        "or",
+			request:     `{"query":{"$or":[{"flight_number":{"$gt":3}},{"name":"Launch 1"}]}}`,
+			expectedIDs: []string{"1", "4"},
+		},
+		{
+			name:        "sort descending",
+			request:     `{"query":{"launchpad":"pad-1"},"options":{"sort":{"date_utc":"desc"}}}`,
+			expectedIDs: []string{"3", "2", "1"},
+		},
+		{
+			name:        "pagination by page",
+			request:     `{"options":{"limit":3,"page":2}}`,
+			expectedIDs: []string{"4"},
+		},
+		{
+			name:        "unsupported operator",
+			request:     `{"query":{"name":{"$regex":"Launch"}}}`,
+			expectedErr: "unsupported operator $regex",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req QueryRequest
+			require.NoError(t, json.Unmarshal([]byte(tt.request), &req))
+
+			resp, err := Run(testDocuments(t), req)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIDs, ids(resp.Docs))
+		})
+	}
+}
+
+func TestRun_PaginationMetadata(t *testing.T) {
+	var req QueryRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"options":{"limit":2,"page":1}}`), &req))
+
+	resp, err := Run(testDocuments(t), req)
+	require.NoError(t, err)
+	assert.Equal(t, 4, resp.TotalDocs)
+	assert.Equal(t, 2, resp.TotalPages)
+	assert.Equal(t, 1, resp.Page)
+	assert.False(t, resp.HasPrevPage)
+	assert.True(t, resp.HasNextPage)
+	assert.Nil(t, resp.PrevPage)
+	require.NotNil(t, resp.NextPage)
+	assert.Equal(t, 2, *resp.NextPage)
+}
@@ -0,0 +1,265 @@
+package spacexfake
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// Fixtures is the content of the fixture file served by the fake
+type Fixtures struct {
+	Launchpads []Document `json:"launchpads"`
+	Launches   []Document `json:"launches"`
+}
+
+// Faults are injected into the SpaceX endpoints, the admin endpoints are never affected
+type Faults struct {
+	// LatencyMS delays every response
+	LatencyMS int `json:"latency_ms"`
+	// StatusCode is returned instead of the real response when set
+	StatusCode int `json:"status_code"`
+	// RetryAfter is sent as the Retry-After header together with StatusCode
+	RetryAfter int `json:"retry_after,omitempty"`
+	// Count limits how many requests fail with StatusCode, zero means until the faults are cleared
+	Count int `json:"count"`
+}
+
+type Server struct {
+	fixtures Fixtures
+
+	mu         sync.Mutex
+	launchpads []Document
+	launches   []Document
+	faults     Faults
+}
+
+func LoadFixtures(path string) (Fixtures, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Fixtures{}, fmt.Errorf("unable to read fixtures: %w", err)
+	}
+	var fixtures Fixtures
+	err = json.Unmarshal(content, &fixtures)
+	if err != nil {
+		return Fixtures{}, fmt.Errorf("unable to parse fixtures: %w", err)
+	}
+	return fixtures, nil
+}
+
+func New(fixtures Fixtures) *Server {
+	s := &Server{fixtures: fixtures}
+	s.reset()
+	return s
+}
+
+// Handler serves the SpaceX API under prefix (e.g. /v4) and the admin endpoints under /admin
+func (s *Server) Handler(prefix string) http.Handler {
+	router := mux.NewRouter()
+
+	api := router.PathPrefix(prefix).Subrouter()
+	api.Use(s.injectFaults)
+	api.HandleFunc("/launchpads", s.listHandler(func() []Document { return s.launchpads })).
+		Methods("GET")
+	api.HandleFunc("/launchpads/query", s.queryHandler(func() []Document { return s.launchpads })).
+		Methods("POST")
+	api.HandleFunc("/launchpads/{id}", s.getHandler(func() []Document { return s.launchpads })).
+		Methods("GET")
+	api.HandleFunc("/launches", s.listHandler(func() []Document { return s.launches })).
+		Methods("GET")
+	api.HandleFunc("/launches/query", s.queryHandler(func() []Document { return s.launches })).
+		Methods("POST")
+	api.HandleFunc("/launches/{id}", s.getHandler(func() []Document { return s.launches })).
+		Methods("GET")
+
+	router.HandleFunc("/admin/launchpads", s.addHandler(func(docs []Document) { s.launchpads = append(s.launchpads, docs...) })).
+		Methods("POST")
+	router.HandleFunc("/admin/launches", s.addHandler(func(docs []Document) { s.launches = append(s.launches, docs...) })).
+		Methods("POST")
+	router.HandleFunc("/admin/faults", s.getFaults).
+		Methods("GET")
+	router.HandleFunc("/admin/faults", s.setFaults).
+		Methods("PUT")
+	router.HandleFunc("/admin/faults", s.clearFaults).
+		Methods("DELETE")
+	router.HandleFunc("/admin/reset", s.resetHandler).
+		Methods("POST")
+	return router
+}
+
+func (s *Server) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.launchpads = append([]Document{}, s.fixtures.Launchpads...)
+	s.launches = append([]Document{}, s.fixtures.Launches...)
+	s.faults = Faults{}
+}
+
+func (s *Server) injectFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		s.mu.Lock()
+		faults := s.faults
+		fail := faults.StatusCode != 0
+		if fail && s.faults.Count > 0 {
+			s.faults.Count--
+			if s.faults.Count == 0 {
+				s.faults.StatusCode = 0
+				s.faults.RetryAfter = 0
+			}
+		}
+		s.mu.Unlock()
+
+		if faults.LatencyMS > 0 {
+			select {
+			case <-request.Context().Done():
+				return
+			case <-time.After(time.Duration(faults.LatencyMS) * time.Millisecond):
+			}
+		}
+		if fail {
+			if faults.RetryAfter > 0 {
+				response.Header().Set("Retry-After", strconv.Itoa(faults.RetryAfter))
+			}
+			response.WriteHeader(faults.StatusCode)
+			return
+		}
+		next.ServeHTTP(response, request)
+	})
+}
+
+func (s *Server) listHandler(docs func() []Document) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		s.mu.Lock()
+		result := append([]Document{}, docs()...)
+		s.mu.Unlock()
+		writeJSON(response, http.StatusOK, result)
+	}
+}
+
+func (s *Server) getHandler(docs func() []Document) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		id := mux.Vars(request)["id"]
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, doc := range docs() {
+			if doc["id"] == id {
+				writeJSON(response, http.StatusOK, doc)
+				return
+			}
+		}
+		response.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *Server) queryHandler(docs func() []Document) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		var req QueryRequest
+		err := json.NewDecoder(request.Body).Decode(&req)
+		if err != nil && err != io.EOF {
+			writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid query"})
+			return
+		}
+		s.mu.Lock()
+		resp, err := Run(docs(), req)
+		s.mu.Unlock()
+		if err != nil {
+			writeJSON(response, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(response, http.StatusOK, resp)
+	}
+}
+
+// addHandler accepts a single document or an array of documents, documents without an ID get a random one
+func (s *Server) addHandler(add func(docs []Document)) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			response.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var docs []Document
+		if err := json.Unmarshal(body, &docs); err != nil {
+			var doc Document
+			if err := json.Unmarshal(body, &doc); err != nil {
+				writeJSON(response, http.StatusBadRequest, map[string]string{"error": "expected a document or an array of documents"})
+				return
+			}
+			docs = []Document{doc}
+		}
+		for _, doc := range docs {
+			if _, ok := doc["id"]; !ok {
+				doc["id"] = newID()
+			}
+		}
+		s.mu.Lock()
+		add(docs)
+		s.mu.Unlock()
+		log.WithField("count", len(docs)).Info("documents added")
+		writeJSON(response, http.StatusCreated, docs)
+	}
+}
+
+func (s *Server) getFaults(response http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	faults := s.faults
+	s.mu.Unlock()
+	writeJSON(response, http.StatusOK, faults)
+}
+
+func (s *Server) setFaults(response http.ResponseWriter, request *http.Request) {
+	var faults Faults
+	err := json.NewDecoder(request.Body).Decode(&faults)
+	if err != nil {
+		writeJSON(response, http.StatusBadRequest, map[string]string{"error": "invalid faults"})
+		return
+	}
+	s.mu.Lock()
+	s.faults = faults
+	s.mu.Unlock()
+	log.WithField("faults", faults).Info("faults injected")
+	writeJSON(response, http.StatusOK, faults)
+}
+
+func (s *Server) clearFaults(response http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	s.faults = Faults{}
+	s.mu.Unlock()
+	response.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) resetHandler(response http.ResponseWriter, _ *http.Request) {
+	s.reset()
+	response.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(response http.ResponseWriter, status int, body interface{}) {
+	respJSON, err := json.Marshal(body)
+	if err != nil {
+		log.WithError(err).Error("unable to marshal response")
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	_, err = response.Write(respJSON)
+	if err != nil {
+		log.WithError(err).Error("unable to write response")
+	}
+}
+
+// newID generates an ID in the same format as the SpaceX API (mongo object id)
+func newID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,104 @@
+package spacexfake
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex"
+)
+
+const launchPadID = "5e9e4501f509094ba4566f84"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	fixtures, err := LoadFixtures("../../resources/spacexfake/fixtures.json")
+	require.NoError(t, err)
+	server := httptest.NewServer(New(fixtures).Handler("/v4"))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func post(t *testing.T, url string, body string) *http.Response {
+	resp, err := http.Post(url, "application/json", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp
+}
+
+func TestServer_SpaceXClient(t *testing.T) {
+	server := newTestServer(t)
+	svc := spacex.New(server.URL+"/v4", server.Client(), clockwork.NewRealClock())
+	ctx := context.Background()
+
+	pad, err := svc.GetLaunchPadForID(ctx, launchPadID)
+	require.NoError(t, err)
+	assert.Equal(t, "CCSFS SLC 40", pad.Name)
+
+	launches, err := svc.GetLaunchesForDate(ctx, launchPadID, time.Date(2022, 7, 7, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, launches, 1)
+	assert.Equal(t, "Starlink 4-21 (v1.5)", launches[0].Name)
+
+	_, err = svc.GetLaunchPadForID(ctx, "unknown")
+	assert.ErrorIs(t, err, models.ErrNotFoundLaunchpad)
+}
+
+func TestServer_AddLaunch(t *testing.T) {
+	server := newTestServer(t)
+	svc := spacex.New(server.URL+"/v4", server.Client(), clockwork.NewRealClock())
+	ctx := context.Background()
+	date := time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	launches, err := svc.GetLaunchesForDate(ctx, launchPadID, date)
+	require.NoError(t, err)
+	assert.Empty(t, launches)
+
+	resp := post(t, server.URL+"/admin/launches",
+		`{"name":"Test launch","date_utc":"2040-01-01T10:00:00.000Z","launchpad":"`+launchPadID+`"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	launches, err = svc.GetLaunchesForDate(ctx, launchPadID, date)
+	require.NoError(t, err)
+	require.Len(t, launches, 1)
+	assert.Equal(t, "Test launch", launches[0].Name)
+
+	resp = post(t, server.URL+"/admin/reset", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	launches, err = svc.GetLaunchesForDate(ctx, launchPadID, date)
+	require.NoError(t, err)
+	assert.Empty(t, launches)
+}
+
+func TestServer_Faults(t *testing.T) {
+	server := newTestServer(t)
+	svc := spacex.New(server.URL+"/v4", server.Client(), clockwork.NewRealClock())
+	ctx := context.Background()
+
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/admin/faults",
+		bytes.NewBufferString(`{"status_code":503,"retry_after":7,"count":1}`))
+	require.NoError(t, err)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = svc.GetLaunchPadForID(ctx, launchPadID)
+	assert.Equal(t, &spacex.StatusError{
+		Resource:   "launchpads",
+		StatusCode: http.StatusServiceUnavailable,
+		RetryAfter: 7 * time.Second,
+	}, err)
+
+	// The fault only applied to a single request
+	_, err = svc.GetLaunchPadForID(ctx, launchPadID)
+	assert.NoError(t, err)
+}
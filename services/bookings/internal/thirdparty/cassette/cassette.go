@@ -0,0 +1,266 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Mode decides if the HTTP interactions are recorded, replayed or passed through
+type Mode string
+
+const (
+	ModeOff    Mode = "off"
+	ModeRecord Mode = "record"
+	ModeReplay Mode = "replay"
+)
+
+const redacted = "REDACTED"
+
+var ErrInteractionNotFound = errors.New("no recorded interaction for request")
+
+// Secrets are never written to a cassette
+var (
+	secretHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	secretParams  = []string{"token", "access_token", "api_key", "apikey", "key", "secret"}
+)
+
+func ParseMode(value string) (Mode, error) {
+	switch Mode(value) {
+	case ModeOff, ModeRecord, ModeReplay:
+		return Mode(value), nil
+	default:
+		return "", fmt.Errorf("unknown cassette mode %q", value)
+	}
+}
+
+// Cassette is the file format of the recorded interactions
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// NewTransport wraps next according to mode, next is used as is when the mode is off
+func NewTransport(mode Mode, path string, next http.RoundTripper) (http.RoundTripper, error) {
+	switch mode {
+	case ModeRecord:
+		return NewRecorder(path, next), nil
+	case ModeReplay:
+		return NewReplayer(path)
+	default:
+		return next, nil
+	}
+}
+
+func Load(path string) (*Cassette, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read cassette: %w", err)
+	}
+	var c Cassette
+	err = json.Unmarshal(content, &c)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse cassette: %w", err)
+	}
+	return &c, nil
+}
+
+func (c *Cassette) Save(path string) error {
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal cassette: %w", err)
+	}
+	err = os.WriteFile(path, content, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to write cassette: %w", err)
+	}
+	return nil
+}
+
+type recorder struct {
+	path string
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder passes the requests to next and writes every interaction to path with secrets scrubbed.
+// The cassette is rewritten after each interaction so nothing is lost when the process is killed.
+func NewRecorder(path string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &recorder{
+		path: path,
+		next: next,
+	}
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read request body: %w", err)
+	}
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response body: %w", err)
+	}
+
+	interaction := Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     scrubURL(req.URL),
+			Headers: scrubHeaders(req.Header),
+			Body:    reqBody,
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    scrubHeaders(resp.Header),
+			Body:       respBody,
+		},
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	err = r.cassette.Save(r.path)
+	if err != nil {
+		log.WithError(err).WithField("path", r.path).Error("unable to record interaction")
+	}
+	return resp, nil
+}
+
+type replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer serves the interactions of the cassette at path without making any call.
+// Requests are matched on method, URL and body; identical requests are served in the recorded order
+// and the last recording is repeated once they are used up.
+func NewReplayer(path string) (http.RoundTripper, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &replayer{
+		interactions: c.Interactions,
+		used:         make([]bool, len(c.Interactions)),
+	}, nil
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read request body: %w", err)
+	}
+	reqURL := scrubURL(req.URL)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	found := -1
+	for i, interaction := range r.interactions {
+		if interaction.Request.Method != req.Method || interaction.Request.URL != reqURL ||
+			interaction.Request.Body != body {
+			continue
+		}
+		found = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if found == -1 {
+		return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, req.Method, reqURL)
+	}
+	r.used[found] = true
+
+	recorded := r.interactions[found].Response
+	return &http.Response{
+		StatusCode:    recorded.StatusCode,
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Headers.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// readBody reads the body and replaces it, so it can be read again
+func readBody(body *io.ReadCloser) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+	content, err := io.ReadAll(*body)
+	if err != nil {
+		return "", err
+	}
+	err = (*body).Close()
+	if err != nil {
+		return "", err
+	}
+	*body = io.NopCloser(bytes.NewReader(content))
+	return string(content), nil
+}
+
+func scrubHeaders(headers http.Header) http.Header {
+	scrubbed := headers.Clone()
+	for _, name := range secretHeaders {
+		if scrubbed.Get(name) != "" {
+			scrubbed.Set(name, redacted)
+		}
+	}
+	return scrubbed
+}
+
+func scrubURL(u *url.URL) string {
+	scrubbed := *u
+	scrubbed.User = nil
+	query := scrubbed.Query()
+	changed := false
+	for name := range query {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				query.Set(name, redacted)
+				changed = true
+			}
+		}
+	}
+	if changed {
+		scrubbed.RawQuery = query.Encode()
+	}
+	return scrubbed.String()
+}
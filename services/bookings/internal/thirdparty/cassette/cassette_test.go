@@ -0,0 +1,104 @@
+package cassette
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func doRequest(t *testing.T, client *http.Client, method, url, body string) (int, string, error) {
+	req, err := http.NewRequestWithContext(context.Background(), method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(respBody), nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret-session"})
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(r.Method + " " + string(body) + " " + r.URL.Query().Get("page")))
+	}))
+	defer ts.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recordClient := &http.Client{Transport: NewRecorder(path, ts.Client().Transport)}
+	status, body, err := doRequest(t, recordClient, http.MethodPost, ts.URL+"/query?page=1&api_key=secret-key", `{"a":1}`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `POST {"a":1} 1`, body)
+	_, _, err = doRequest(t, recordClient, http.MethodGet, ts.URL+"/list", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	c, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, c.Interactions, 2)
+	recorded := c.Interactions[0]
+	assert.Equal(t, ts.URL+"/query?api_key=REDACTED&page=1", recorded.Request.URL)
+	assert.Equal(t, "REDACTED", recorded.Request.Headers.Get("Authorization"))
+	assert.Equal(t, "REDACTED", recorded.Response.Headers.Get("Set-Cookie"))
+	assert.Equal(t, `{"a":1}`, recorded.Request.Body)
+
+	transport, err := NewReplayer(path)
+	require.NoError(t, err)
+	replayClient := &http.Client{Transport: transport}
+	status, body, err = doRequest(t, replayClient, http.MethodPost, ts.URL+"/query?page=1&api_key=other-key", `{"a":1}`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `POST {"a":1} 1`, body)
+	assert.Equal(t, 2, calls, "replay must not call the server")
+
+	_, _, err = doRequest(t, replayClient, http.MethodPost, ts.URL+"/query?page=1", `{"a":2}`)
+	assert.ErrorIs(t, err, ErrInteractionNotFound)
+}
+
+func TestReplay_RepeatedRequests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	c := Cassette{Interactions: []Interaction{
+		{
+			Request:  Request{Method: http.MethodGet, URL: "https://example.com/status"},
+			Response: Response{StatusCode: http.StatusServiceUnavailable},
+		},
+		{
+			Request:  Request{Method: http.MethodGet, URL: "https://example.com/status"},
+			Response: Response{StatusCode: http.StatusOK, Body: "ok"},
+		},
+	}}
+	require.NoError(t, c.Save(path))
+
+	transport, err := NewReplayer(path)
+	require.NoError(t, err)
+	client := &http.Client{Transport: transport}
+	for _, expected := range []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusOK} {
+		status, _, err := doRequest(t, client, http.MethodGet, "https://example.com/status", "")
+		require.NoError(t, err)
+		assert.Equal(t, expected, status)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode("replay")
+	require.NoError(t, err)
+	assert.Equal(t, ModeReplay, mode)
+
+	_, err = ParseMode("rewind")
+	assert.EqualError(t, err, `unknown cassette mode "rewind"`)
+}
@@ -2,63 +2,466 @@ package spacex
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/cachestore"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
 
 	"github.com/jonboulle/clockwork"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// flightTimeout bounds SpaceX calls that are detached from the context of the callers
+	flightTimeout = 30 * time.Second
+	// prewarmConcurrency bounds the launch pads whose launches are fetched at once while prewarming
+	prewarmConcurrency = 4
+
+	launchpadsKey   = "launchpads"
+	launchpadPrefix = "launchpad_"
+	launchesPrefix  = "launches_"
 )
 
+// CacheConfig configures how long launch pads and launches are served from the cache
+type CacheConfig struct {
+	// LaunchpadTTL is how long launch pads are cached, zero caches them until they are evicted
+	LaunchpadTTL time.Duration
+	// PastLaunchTTL is how long launches of the days before today (UTC) are cached, zero does not cache them
+	PastLaunchTTL time.Duration
+	// FreshFor is how long cached future launches are served without asking SpaceX
+	FreshFor time.Duration
+	// StaleBudget is how long future launches are served after they stopped being fresh,
+	// while they are revalidated in the background
+	StaleBudget time.Duration
+	// MaxEntries bounds the number of launch pads and of launch dates cached, zero means unbounded.
+	// It is ignored when Backend is set.
+	MaxEntries int
+	// Backend stores the entries, defaults to an in-memory backend
+	Backend cachestore.Backend
+}
+
+// CacheStats describes the entries of a cache, hits and misses are counted since the replica started
+type CacheStats struct {
+	Hits        int64
+	Misses      int64
+	Launchpads  int
+	LaunchDates int
+	// OldestEntryAge and NewestEntryAge are zero when the cache is empty
+	OldestEntryAge time.Duration
+	NewestEntryAge time.Duration
+}
+
+// Cache is a SpaceXService whose entries can be inspected, invalidated and prewarmed by operators
+type Cache interface {
+	SpaceXService
+	Stats(ctx context.Context) (CacheStats, error)
+	// Invalidate drops the cached launches of launchPadID between from and to, both days included.
+	// An empty launchPadID matches every launch pad and a zero from or to leaves the range open.
+	// Launch pads are dropped too when no date is given. It returns the number of entries dropped.
+	Invalidate(ctx context.Context, launchPadID string, from, to time.Time) (int, error)
+	// Prewarm caches the launch pads and their launches from today for the given number of days
+	Prewarm(ctx context.Context, days int) error
+}
+
 type cache struct {
-	svc   SpaceXService
-	clock clockwork.Clock
+	svc    SpaceXService
+	clock  clockwork.Clock
+	config CacheConfig
 
-	padCache    map[string]*smodels.Launchpad
-	launchCache map[string][]smodels.Launch
+	backend cachestore.Backend
+	hits    atomic.Int64
+	misses  atomic.Int64
+	// inFlight coalesces concurrent cache misses for the same key into a single SpaceX call
+	inFlight singleflight.Group
+
+	mu           sync.Mutex
+	revalidating map[string]bool
 }
 
-func NewCache(svc SpaceXService, clock clockwork.Clock) SpaceXService {
+func NewCache(svc SpaceXService, clock clockwork.Clock, config CacheConfig) Cache {
+	backend := config.Backend
+	if backend == nil {
+		backend = cachestore.NewMemory(clock, config.MaxEntries)
+	}
 	return &cache{
-		svc:         svc,
-		clock:       clock,
-		padCache:    make(map[string]*smodels.Launchpad),
-		launchCache: make(map[string][]smodels.Launch),
+		svc:          svc,
+		clock:        clock,
+		config:       config,
+		backend:      backend,
+		revalidating: make(map[string]bool),
 	}
 }
 
-func (c cache) GetLaunchPadForID(ctx context.Context, launchPadID string) (*smodels.Launchpad, error) {
-	val, ok := c.padCache[launchPadID]
-	if ok {
-		return val, nil
+func (c *cache) GetLaunchPads(ctx context.Context) ([]smodels.Launchpad, error) {
+	var cached []smodels.Launchpad
+	if _, ok := c.get(ctx, launchpadsKey, &cached); ok {
+		c.hits.Add(1)
+		return cached, nil
 	}
-	res, err := c.svc.GetLaunchPadForID(ctx, launchPadID)
+	c.misses.Add(1)
+	res, err := c.do(ctx, launchpadsKey, func(ctx context.Context) (interface{}, error) {
+		res, err := c.svc.GetLaunchPads(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.set(ctx, launchpadsKey, res, c.config.LaunchpadTTL)
+		for _, pad := range res {
+			c.set(ctx, toLaunchpadKey(pad.ID), pad, c.config.LaunchpadTTL)
+		}
+		return res, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get launch pads: %w", err)
+	}
+	return res.([]smodels.Launchpad), nil
+}
+
+func (c *cache) GetLaunchPadForID(ctx context.Context, launchPadID string) (*smodels.Launchpad, error) {
+	key := toLaunchpadKey(launchPadID)
+	var cached smodels.Launchpad
+	if _, ok := c.get(ctx, key, &cached); ok {
+		c.hits.Add(1)
+		return &cached, nil
+	}
+	c.misses.Add(1)
+	res, err := c.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		res, err := c.svc.GetLaunchPadForID(ctx, launchPadID)
+		if err != nil {
+			return nil, err
+		}
+		c.set(ctx, key, res, c.config.LaunchpadTTL)
+		return res, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("unable to get launch pad: %w", err)
 	}
-	c.padCache[launchPadID] = res
-	return res, nil
+	return res.(*smodels.Launchpad), nil
 }
 
-func (c cache) GetLaunchesForDate(ctx context.Context, launchPadID string, date time.Time) ([]smodels.Launch, error) {
-	// Assumption future launches might change therefore we only cache launches in the past
-	// We could also apply an expiration here but it is fine for now IMO
+func (c *cache) GetLaunchesForDate(ctx context.Context, launchPadID string, date time.Time) ([]smodels.Launch, error) {
+	// Future launches might change, they are served fresh for a while and then stale within the staleness budget
+	key := toLaunchesKey(launchPadID, date)
 	now := c.clock.Now()
-	if date.Before(now) {
-		// Cache
-		res, ok := c.launchCache[toLaunchKey(launchPadID, date)]
-		if ok {
-			return res, nil
+	var launches []smodels.Launch
+	if storedAt, ok := c.get(ctx, key, &launches); ok {
+		c.hits.Add(1)
+		if isPastDay(date, now) {
+			return launches, nil
 		}
+		age := now.Sub(storedAt)
+		if age < c.config.FreshFor {
+			return launches, nil
+		}
+		c.revalidate(launchPadID, date)
+		return launches, nil
+	}
+	c.misses.Add(1)
+	res, err := c.do(ctx, key, func(ctx context.Context) (interface{}, error) {
 		launches, err := c.svc.GetLaunchesForDate(ctx, launchPadID, date)
 		if err != nil {
-			return nil, fmt.Errorf("unable to get launches: %w", err)
+			return nil, err
 		}
-		c.launchCache[toLaunchKey(launchPadID, date)] = launches
+		c.store(ctx, key, launches, date, now)
 		return launches, nil
-	} else {
-		return c.svc.GetLaunchesForDate(ctx, launchPadID, date)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get launches: %w", err)
+	}
+	return res.([]smodels.Launch), nil
+}
+
+// GetLaunchesForRange serves the cached days and asks SpaceX once for the span of days that are not cached,
+// every day of that span is cached on its own so single day lookups reuse it
+func (c *cache) GetLaunchesForRange(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]smodels.Launch, error) {
+	now := c.clock.Now()
+	var launches []smodels.Launch
+	var missing []time.Time
+	for day := truncateToDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		var cached []smodels.Launch
+		storedAt, ok := c.get(ctx, toLaunchesKey(launchPadID, day), &cached)
+		if !ok {
+			c.misses.Add(1)
+			missing = append(missing, day)
+			continue
+		}
+		c.hits.Add(1)
+		if !isPastDay(day, now) && now.Sub(storedAt) >= c.config.FreshFor {
+			c.revalidate(launchPadID, day)
+		}
+		launches = append(launches, cached...)
+	}
+
+	if len(missing) != 0 {
+		first, last := missing[0], missing[len(missing)-1].AddDate(0, 0, 1)
+		key := fmt.Sprintf("launches_range_%s_%s_%s", launchPadID, first.Format("2006-01-02"), last.Format("2006-01-02"))
+		res, err := c.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+			fetched, err := c.svc.GetLaunchesForRange(ctx, launchPadID, first, last)
+			if err != nil {
+				return nil, err
+			}
+			byDay := make(map[time.Time][]smodels.Launch)
+			for _, launch := range fetched {
+				day := truncateToDay(launch.DateUTC)
+				byDay[day] = append(byDay[day], launch)
+			}
+			// Days without launches are cached too, they are the ones bookings are made for
+			for day := first; day.Before(last); day = day.AddDate(0, 0, 1) {
+				c.store(ctx, toLaunchesKey(launchPadID, day), byDay[day], day, now)
+			}
+			return fetched, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get launches: %w", err)
+		}
+		// The span might include cached days, their launches were already added
+		isMissing := make(map[time.Time]bool, len(missing))
+		for _, day := range missing {
+			isMissing[day] = true
+		}
+		for _, launch := range res.([]smodels.Launch) {
+			if isMissing[truncateToDay(launch.DateUTC)] {
+				launches = append(launches, launch)
+			}
+		}
+	}
+
+	var result []smodels.Launch
+	for _, launch := range launches {
+		if !launch.DateUTC.Before(from) && launch.DateUTC.Before(to) {
+			result = append(result, launch)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].DateUTC.Before(result[j].DateUTC)
+	})
+	return result, nil
+}
+
+func (c *cache) Stats(ctx context.Context) (CacheStats, error) {
+	keys, err := c.backend.List(ctx, "")
+	if err != nil {
+		return CacheStats{}, fmt.Errorf("unable to list cache entries: %w", err)
+	}
+	stats := CacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+	now := c.clock.Now()
+	for _, key := range keys {
+		switch {
+		case strings.HasPrefix(key.Key, launchpadPrefix):
+			stats.Launchpads++
+		case strings.HasPrefix(key.Key, launchesPrefix):
+			stats.LaunchDates++
+		default:
+			// The list of launch pads is not counted, every launch pad in it is cached on its own
+			continue
+		}
+		age := now.Sub(key.StoredAt)
+		if age > stats.OldestEntryAge {
+			stats.OldestEntryAge = age
+		}
+		if stats.NewestEntryAge == 0 || age < stats.NewestEntryAge {
+			stats.NewestEntryAge = age
+		}
+	}
+	return stats, nil
+}
+
+func (c *cache) Invalidate(ctx context.Context, launchPadID string, from, to time.Time) (int, error) {
+	keys, err := c.backend.List(ctx, "")
+	if err != nil {
+		return 0, fmt.Errorf("unable to list cache entries: %w", err)
+	}
+	noDates := from.IsZero() && to.IsZero()
+	from = truncateToDay(from)
+	to = truncateToDay(to)
+	invalidated := 0
+	for _, key := range keys {
+		if !matchesInvalidation(key.Key, launchPadID, from, to, noDates) {
+			continue
+		}
+		err := c.backend.Delete(ctx, key.Key)
+		if err != nil {
+			return invalidated, fmt.Errorf("unable to delete cache entry: %w", err)
+		}
+		invalidated++
+	}
+	return invalidated, nil
+}
+
+func matchesInvalidation(key string, launchPadID string, from, to time.Time, noDates bool) bool {
+	switch {
+	case key == launchpadsKey:
+		return noDates
+	case strings.HasPrefix(key, launchpadPrefix):
+		return noDates && (launchPadID == "" || key == toLaunchpadKey(launchPadID))
+	case strings.HasPrefix(key, launchesPrefix):
+		padID, date, ok := parseLaunchesKey(key)
+		if !ok {
+			return false
+		}
+		if launchPadID != "" && padID != launchPadID {
+			return false
+		}
+		return (from.IsZero() || !date.Before(from)) && (to.IsZero() || !date.After(to))
+	default:
+		return false
+	}
+}
+
+func (c *cache) Prewarm(ctx context.Context, days int) error {
+	pads, err := c.GetLaunchPads(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to prewarm launch pads: %w", err)
+	}
+	today := truncateToDay(c.clock.Now())
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(prewarmConcurrency)
+	for _, pad := range pads {
+		group.Go(func() error {
+			_, err := c.GetLaunchesForRange(ctx, pad.ID, today, today.AddDate(0, 0, days))
+			return err
+		})
+	}
+	err = group.Wait()
+	if err != nil {
+		return fmt.Errorf("unable to prewarm launches: %w", err)
+	}
+	return nil
+}
+
+// do runs fn once for all the concurrent callers of key. fn is not cancelled with the context of the caller
+// that started it, so a cancelled caller only gives up waiting and the other callers still get the result.
+func (c *cache) do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	result := c.inFlight.DoChan(key, func() (interface{}, error) {
+		flightCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), flightTimeout)
+		defer cancel()
+		return fn(flightCtx)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		return res.Val, res.Err
+	}
+}
+
+// revalidate refreshes the launches in the background, at most once at a time for a given key
+func (c *cache) revalidate(launchPadID string, date time.Time) {
+	key := toLaunchesKey(launchPadID, date)
+	c.mu.Lock()
+	if c.revalidating[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.revalidating[key] = true
+	c.mu.Unlock()
+
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.revalidating, key)
+			c.mu.Unlock()
+		}()
+		_, err := c.do(context.Background(), key, func(ctx context.Context) (interface{}, error) {
+			launches, err := c.svc.GetLaunchesForDate(ctx, launchPadID, date)
+			if err != nil {
+				return nil, err
+			}
+			c.store(ctx, key, launches, date, c.clock.Now())
+			return launches, nil
+		})
+		if err != nil {
+			log.WithError(err).WithField("key", key).Warn("unable to revalidate launches, serving stale data")
+		}
+	}()
+}
+
+func (c *cache) store(ctx context.Context, key string, launches []smodels.Launch, date time.Time, now time.Time) {
+	// Future launches expire once they cannot be served stale anymore
+	ttl := c.config.FreshFor + c.config.StaleBudget
+	if isPastDay(date, now) {
+		ttl = c.config.PastLaunchTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+	c.set(ctx, key, launches, ttl)
+}
+
+// isPastDay tells if the day of date is over, the launches of today can still change
+func isPastDay(date time.Time, now time.Time) bool {
+	return truncateToDay(date).Before(truncateToDay(now))
+}
+
+// get decodes the cached value of key into value. Backend errors are treated as misses, SpaceX is asked instead.
+func (c *cache) get(ctx context.Context, key string, value interface{}) (time.Time, bool) {
+	entry, ok, err := c.backend.Get(ctx, key)
+	if err != nil {
+		log.WithError(err).WithField("key", key).Warn("unable to get cache entry")
+		return time.Time{}, false
+	}
+	if !ok {
+		return time.Time{}, false
+	}
+	err = json.Unmarshal(entry.Value, value)
+	if err != nil {
+		log.WithError(err).WithField("key", key).Warn("unable to decode cache entry")
+		return time.Time{}, false
+	}
+	return entry.StoredAt, true
+}
+
+func (c *cache) set(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		log.WithError(err).WithField("key", key).Warn("unable to encode cache entry")
+		return
+	}
+	err = c.backend.Set(ctx, key, encoded, ttl)
+	if err != nil {
+		log.WithError(err).WithField("key", key).Warn("unable to set cache entry")
+	}
+}
+
+func toLaunchpadKey(launchPadID string) string {
+	return launchpadPrefix + launchPadID
+}
+
+func toLaunchesKey(launchPadID string, date time.Time) string {
+	return launchesPrefix + toLaunchKey(launchPadID, date)
+}
+
+// parseLaunchesKey returns the launch pad and the date of a key built by toLaunchesKey
+func parseLaunchesKey(key string) (string, time.Time, bool) {
+	launchKey := strings.TrimPrefix(key, launchesPrefix)
+	separator := strings.LastIndex(launchKey, "_")
+	if separator < 0 {
+		return "", time.Time{}, false
+	}
+	date, err := time.Parse("2006-01-02", launchKey[separator+1:])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return launchKey[:separator], date, true
+}
+
+func truncateToDay(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
 	}
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 }
 
 func toLaunchKey(launchPadID string, date time.Time) string {
@@ -2,13 +2,17 @@ package spacex
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/cachestore"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
 
 	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
 	"go.uber.org/mock/gomock"
 )
@@ -18,14 +22,14 @@ func Test_Cache_GetLaunchPadForID(t *testing.T) {
 
 	mockService := mocks.NewMockSpaceXService(ctrl)
 	clock := clockwork.NewFakeClock()
-	cachedService := NewCache(mockService, clock)
+	cachedService := NewCache(mockService, clock, CacheConfig{})
 
 	launchPadID := "pad-1"
 	expectedLaunchPad := &smodels.Launchpad{ID: launchPadID, Name: "Launch Pad 1"}
 
 	// First call should hit the underlying service
 	mockService.EXPECT().
-		GetLaunchPadForID(context.Background(), launchPadID).
+		GetLaunchPadForID(gomock.Any(), launchPadID).
 		Return(expectedLaunchPad, nil).Times(1)
 
 	// Call the method
@@ -47,7 +51,7 @@ func TestGetLaunchesForDate_CachesResults(t *testing.T) {
 	now := time.Date(2025, 12, 01, 0, 0, 0, 0, time.UTC)
 	clock := clockwork.NewFakeClockAt(now)
 
-	cachedService := NewCache(mockService, clock)
+	cachedService := NewCache(mockService, clock, CacheConfig{PastLaunchTTL: 24 * time.Hour})
 
 	launchPadID := "pad-1"
 	date := now.AddDate(0, 0, -10)
@@ -58,7 +62,7 @@ func TestGetLaunchesForDate_CachesResults(t *testing.T) {
 
 	// First call should hit the underlying service
 	mockService.EXPECT().
-		GetLaunchesForDate(context.Background(), launchPadID, date).
+		GetLaunchesForDate(gomock.Any(), launchPadID, date).
 		Return(expectedLaunches, nil).Times(1)
 
 	// Call the method
@@ -78,14 +82,14 @@ func TestGetLaunchesForDate_NonCachedFutureDate(t *testing.T) {
 
 	mockService := mocks.NewMockSpaceXService(ctrl)
 	clock := clockwork.NewFakeClock()
-	cachedService := NewCache(mockService, clock)
+	cachedService := NewCache(mockService, clock, CacheConfig{})
 
 	launchPadID := "pad-1"
 	futureDate := clock.Now().Add(24 * time.Hour)
 
 	// Future date call should hit the underlying service
 	mockService.EXPECT().
-		GetLaunchesForDate(context.Background(), launchPadID, futureDate).
+		GetLaunchesForDate(gomock.Any(), launchPadID, futureDate).
 		Return([]smodels.Launch{}, nil).Times(1)
 
 	// Call the method with a future date
@@ -94,19 +98,77 @@ func TestGetLaunchesForDate_NonCachedFutureDate(t *testing.T) {
 	assert.Empty(t, launches)
 }
 
+func TestGetLaunchesForDate_PastLaunchesNotCachedWithoutTTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	clock := clockwork.NewFakeClock()
+	cachedService := NewCache(mockService, clock, CacheConfig{})
+
+	launchPadID := "pad-1"
+	pastDate := clock.Now().AddDate(0, 0, -10)
+	mockService.EXPECT().
+		GetLaunchesForDate(gomock.Any(), launchPadID, pastDate).
+		Return([]smodels.Launch{}, nil).Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := cachedService.GetLaunchesForDate(context.Background(), launchPadID, pastDate)
+		assert.NoError(t, err)
+	}
+}
+
+func TestGetLaunchesForDate_TodayIsRevalidated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	// Today has started, its launches can still be moved
+	today := time.Date(2025, 12, 01, 0, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(today.Add(12 * time.Hour))
+	cachedService := NewCache(mockService, clock, CacheConfig{
+		PastLaunchTTL: 30 * 24 * time.Hour,
+		FreshFor:      time.Minute,
+		StaleBudget:   time.Hour,
+	})
+
+	launchPadID := "pad-1"
+	stale := []smodels.Launch{{Name: "Launch 1"}}
+	moved := []smodels.Launch{{Name: "Launch 1", DateUTC: today.Add(20 * time.Hour)}}
+	revalidated := make(chan struct{})
+	gomock.InOrder(
+		mockService.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, today).Return(stale, nil),
+		mockService.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, today).
+			DoAndReturn(func(ctx context.Context, launchPadID string, date time.Time) ([]smodels.Launch, error) {
+				defer close(revalidated)
+				return moved, nil
+			}),
+	)
+
+	_, err := cachedService.GetLaunchesForDate(context.Background(), launchPadID, today)
+	require.NoError(t, err)
+	clock.Advance(2 * time.Minute)
+	launches, err := cachedService.GetLaunchesForDate(context.Background(), launchPadID, today)
+	require.NoError(t, err)
+	assert.Equal(t, stale, launches, "served stale while revalidated")
+	<-revalidated
+	require.Eventually(t, func() bool {
+		launches, err := cachedService.GetLaunchesForDate(context.Background(), launchPadID, today)
+		return err == nil && assert.ObjectsAreEqual(moved, launches)
+	}, time.Second, time.Millisecond)
+}
+
 func TestGetLaunchesForDate_ErrorFromService(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
 	mockService := mocks.NewMockSpaceXService(ctrl)
 	clock := clockwork.NewFakeClock()
-	cachedService := NewCache(mockService, clock)
+	cachedService := NewCache(mockService, clock, CacheConfig{})
 
 	launchPadID := "pad-1"
 	date := time.Date(2024, 12, 01, 0, 0, 0, 0, time.UTC)
 
 	// Simulate an error from the service
 	mockService.EXPECT().
-		GetLaunchesForDate(context.Background(), launchPadID, date).
+		GetLaunchesForDate(gomock.Any(), launchPadID, date).
 		Return(nil, assert.AnError).Times(1)
 
 	// Call the method and expect an error
@@ -115,3 +177,493 @@ func TestGetLaunchesForDate_ErrorFromService(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, launches)
 }
+
+func TestGetLaunchesForDate_StaleWhileRevalidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	now := time.Date(2025, 12, 01, 0, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(now)
+	cachedService := NewCache(mockService, clock, CacheConfig{
+		FreshFor:    time.Minute,
+		StaleBudget: time.Hour,
+	})
+
+	launchPadID := "pad-1"
+	futureDate := now.AddDate(0, 0, 10)
+	oldLaunches := []smodels.Launch{{Name: "Launch 1"}}
+	newLaunches := []smodels.Launch{{Name: "Launch 1"}, {Name: "Launch 2"}}
+
+	mockService.EXPECT().
+		GetLaunchesForDate(gomock.Any(), launchPadID, futureDate).
+		Return(oldLaunches, nil).Times(1)
+	launches, err := cachedService.GetLaunchesForDate(context.Background(), launchPadID, futureDate)
+	assert.NoError(t, err)
+	assert.Equal(t, oldLaunches, launches)
+
+	// Fresh entries are served without calling SpaceX
+	clock.Advance(30 * time.Second)
+	launches, err = cachedService.GetLaunchesForDate(context.Background(), launchPadID, futureDate)
+	assert.NoError(t, err)
+	assert.Equal(t, oldLaunches, launches)
+
+	// Stale entries are served while they are refreshed in the background
+	revalidated := make(chan struct{})
+	mockService.EXPECT().
+		GetLaunchesForDate(gomock.Any(), launchPadID, futureDate).
+		DoAndReturn(func(ctx context.Context, launchPadID string, date time.Time) ([]smodels.Launch, error) {
+			defer close(revalidated)
+			return newLaunches, nil
+		}).Times(1)
+	clock.Advance(time.Minute)
+	launches, err = cachedService.GetLaunchesForDate(context.Background(), launchPadID, futureDate)
+	assert.NoError(t, err)
+	assert.Equal(t, oldLaunches, launches)
+	<-revalidated
+
+	assert.Eventually(t, func() bool {
+		launches, err := cachedService.GetLaunchesForDate(context.Background(), launchPadID, futureDate)
+		return err == nil && len(launches) == len(newLaunches)
+	}, time.Second, time.Millisecond)
+}
+
+func TestGetLaunchesForDate_StaleBudgetExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	now := time.Date(2025, 12, 01, 0, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(now)
+	cachedService := NewCache(mockService, clock, CacheConfig{
+		FreshFor:    time.Minute,
+		StaleBudget: time.Hour,
+	})
+
+	launchPadID := "pad-1"
+	futureDate := now.AddDate(0, 0, 10)
+
+	mockService.EXPECT().
+		GetLaunchesForDate(gomock.Any(), launchPadID, futureDate).
+		Return([]smodels.Launch{{Name: "Launch 1"}}, nil).Times(1)
+	_, err := cachedService.GetLaunchesForDate(context.Background(), launchPadID, futureDate)
+	assert.NoError(t, err)
+
+	// Beyond the staleness budget SpaceX has to answer
+	clock.Advance(2 * time.Hour)
+	mockService.EXPECT().
+		GetLaunchesForDate(gomock.Any(), launchPadID, futureDate).
+		Return(nil, assert.AnError).Times(1)
+	launches, err := cachedService.GetLaunchesForDate(context.Background(), launchPadID, futureDate)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Nil(t, launches)
+}
+
+func Test_Cache_LaunchpadTTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	clock := clockwork.NewFakeClock()
+	cachedService := NewCache(mockService, clock, CacheConfig{LaunchpadTTL: time.Hour})
+
+	launchPadID := "pad-1"
+	mockService.EXPECT().
+		GetLaunchPadForID(gomock.Any(), launchPadID).
+		Return(&smodels.Launchpad{ID: launchPadID, Status: "active"}, nil).Times(1)
+	_, err := cachedService.GetLaunchPadForID(context.Background(), launchPadID)
+	assert.NoError(t, err)
+
+	clock.Advance(59 * time.Minute)
+	_, err = cachedService.GetLaunchPadForID(context.Background(), launchPadID)
+	assert.NoError(t, err)
+
+	// Launch pads can be retired, so they are fetched again once expired
+	clock.Advance(time.Minute)
+	mockService.EXPECT().
+		GetLaunchPadForID(gomock.Any(), launchPadID).
+		Return(&smodels.Launchpad{ID: launchPadID, Status: "retired"}, nil).Times(1)
+	launchPad, err := cachedService.GetLaunchPadForID(context.Background(), launchPadID)
+	assert.NoError(t, err)
+	assert.Equal(t, "retired", launchPad.Status)
+}
+
+func TestGetLaunchesForDate_PastLaunchTTLAndEviction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	now := time.Date(2025, 12, 01, 0, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(now)
+	cachedService := NewCache(mockService, clock, CacheConfig{
+		PastLaunchTTL: 24 * time.Hour,
+		MaxEntries:    1,
+	})
+
+	launchPadID := "pad-1"
+	first := now.AddDate(0, 0, -10)
+	second := now.AddDate(0, 0, -11)
+
+	mockService.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, first).Return(nil, nil).Times(2)
+	mockService.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, second).Return(nil, nil).Times(1)
+
+	_, err := cachedService.GetLaunchesForDate(context.Background(), launchPadID, first)
+	assert.NoError(t, err)
+	_, err = cachedService.GetLaunchesForDate(context.Background(), launchPadID, first)
+	assert.NoError(t, err)
+
+	// Only one date fits in the cache, the first one is evicted
+	_, err = cachedService.GetLaunchesForDate(context.Background(), launchPadID, second)
+	assert.NoError(t, err)
+	_, err = cachedService.GetLaunchesForDate(context.Background(), launchPadID, second)
+	assert.NoError(t, err)
+	_, err = cachedService.GetLaunchesForDate(context.Background(), launchPadID, first)
+	assert.NoError(t, err)
+}
+
+func TestGetLaunchesForDate_Concurrent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	now := time.Date(2025, 12, 01, 0, 0, 0, 0, time.UTC)
+	cachedService := NewCache(mockService, clockwork.NewFakeClockAt(now), CacheConfig{MaxEntries: 5})
+
+	mockService.EXPECT().GetLaunchPadForID(gomock.Any(), gomock.Any()).Return(&smodels.Launchpad{}, nil).AnyTimes()
+	mockService.EXPECT().GetLaunchesForDate(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for day := 0; day < 20; day++ {
+				_, err := cachedService.GetLaunchPadForID(context.Background(), fmt.Sprintf("pad-%d", day%7))
+				assert.NoError(t, err)
+				_, err = cachedService.GetLaunchesForDate(context.Background(), "pad-1", now.AddDate(0, 0, -day))
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestGetLaunchesForDate_CoalescesConcurrentMisses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	now := time.Date(2025, 12, 01, 0, 0, 0, 0, time.UTC)
+	cachedService := NewCache(mockService, clockwork.NewFakeClockAt(now), CacheConfig{})
+
+	launchPadID := "pad-1"
+	futureDate := now.AddDate(0, 0, 10)
+	expectedLaunches := []smodels.Launch{{Name: "Launch 1"}}
+
+	const callers = 20
+	release := make(chan struct{})
+	mockService.EXPECT().
+		GetLaunchesForDate(gomock.Any(), launchPadID, futureDate).
+		DoAndReturn(func(ctx context.Context, launchPadID string, date time.Time) ([]smodels.Launch, error) {
+			<-release
+			return expectedLaunches, nil
+		}).Times(1)
+
+	var started, wg sync.WaitGroup
+	results := make(chan []smodels.Launch, callers)
+	for i := 0; i < callers; i++ {
+		started.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			// Future launches are not cached without a fresh period, only coalescing prevents the extra calls
+			launches, err := cachedService.GetLaunchesForDate(context.Background(), launchPadID, futureDate)
+			assert.NoError(t, err)
+			results <- launches
+		}()
+	}
+	started.Wait()
+	// Give every caller the chance to join the call in flight
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for launches := range results {
+		assert.Equal(t, expectedLaunches, launches)
+	}
+}
+
+func Test_Cache_GetLaunchPadForID_CancelledCallerDoesNotFailOthers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	cachedService := NewCache(mockService, clockwork.NewFakeClock(), CacheConfig{})
+
+	launchPadID := "pad-1"
+	expectedLaunchPad := &smodels.Launchpad{ID: launchPadID}
+	called := make(chan struct{})
+	release := make(chan struct{})
+	mockService.EXPECT().
+		GetLaunchPadForID(gomock.Any(), launchPadID).
+		DoAndReturn(func(ctx context.Context, launchPadID string) (*smodels.Launchpad, error) {
+			close(called)
+			<-release
+			// The call is not cancelled with the caller that started it
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return expectedLaunchPad, nil
+		}).Times(1)
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancelledResult := make(chan error)
+	go func() {
+		_, err := cachedService.GetLaunchPadForID(cancelledCtx, launchPadID)
+		cancelledResult <- err
+	}()
+	<-called
+
+	otherResult := make(chan *smodels.Launchpad)
+	go func() {
+		launchPad, err := cachedService.GetLaunchPadForID(context.Background(), launchPadID)
+		assert.NoError(t, err)
+		otherResult <- launchPad
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-cancelledResult, context.Canceled)
+
+	close(release)
+	assert.Equal(t, expectedLaunchPad, <-otherResult)
+
+	// The result of the call is cached even though the caller that started it gave up
+	launchPad, err := cachedService.GetLaunchPadForID(context.Background(), launchPadID)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedLaunchPad, launchPad)
+}
+
+func TestGetLaunchesForDate_SharedBackend(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	now := time.Date(2025, 12, 01, 0, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(now)
+	backend := cachestore.NewMemory(clock, 0)
+	config := CacheConfig{FreshFor: time.Minute, StaleBudget: time.Hour, Backend: backend}
+	// Two replicas sharing the backend
+	first := NewCache(mockService, clock, config)
+	second := NewCache(mockService, clock, config)
+
+	launchPadID := "pad-1"
+	date := now.AddDate(0, 0, 10)
+	expectedLaunches := []smodels.Launch{{Name: "Launch 1"}}
+
+	mockService.EXPECT().
+		GetLaunchesForDate(gomock.Any(), launchPadID, date).
+		Return(expectedLaunches, nil).Times(1)
+
+	launches, err := first.GetLaunchesForDate(context.Background(), launchPadID, date)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedLaunches, launches)
+
+	launches, err = second.GetLaunchesForDate(context.Background(), launchPadID, date)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedLaunches, launches)
+}
+
+func Test_Cache_BackendErrorIsAMiss(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	mockBackend := mocks.NewMockBackend(ctrl)
+	cachedService := NewCache(mockService, clockwork.NewFakeClock(), CacheConfig{Backend: mockBackend})
+
+	launchPadID := "pad-1"
+	expectedLaunchPad := &smodels.Launchpad{ID: launchPadID, Name: "Launch Pad 1"}
+
+	mockBackend.EXPECT().
+		Get(gomock.Any(), "launchpad_"+launchPadID).
+		Return(cachestore.Entry{}, false, fmt.Errorf("connection refused"))
+	mockService.EXPECT().
+		GetLaunchPadForID(gomock.Any(), launchPadID).
+		Return(expectedLaunchPad, nil)
+	mockBackend.EXPECT().
+		Set(gomock.Any(), "launchpad_"+launchPadID, gomock.Any(), time.Duration(0)).
+		Return(fmt.Errorf("connection refused"))
+
+	launchPad, err := cachedService.GetLaunchPadForID(context.Background(), launchPadID)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedLaunchPad, launchPad)
+}
+
+func Test_Cache_StatsAndInvalidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	now := time.Date(2025, 12, 01, 0, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(now)
+	cachedService := NewCache(mockService, clock, CacheConfig{PastLaunchTTL: 24 * time.Hour})
+	ctx := context.Background()
+
+	mockService.EXPECT().
+		GetLaunchPadForID(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, launchPadID string) (*smodels.Launchpad, error) {
+			return &smodels.Launchpad{ID: launchPadID}, nil
+		}).AnyTimes()
+	mockService.EXPECT().
+		GetLaunchesForDate(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil).AnyTimes()
+
+	_, err := cachedService.GetLaunchPadForID(ctx, "pad_1")
+	assert.NoError(t, err)
+	clock.Advance(time.Minute)
+	for _, launchPadID := range []string{"pad_1", "pad_2"} {
+		for day := -3; day < 0; day++ {
+			_, err := cachedService.GetLaunchesForDate(ctx, launchPadID, now.AddDate(0, 0, day))
+			assert.NoError(t, err)
+		}
+	}
+	_, err = cachedService.GetLaunchPadForID(ctx, "pad_1")
+	assert.NoError(t, err)
+	clock.Advance(time.Minute)
+
+	stats, err := cachedService.Stats(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheStats{
+		Hits:           1,
+		Misses:         7,
+		Launchpads:     1,
+		LaunchDates:    6,
+		OldestEntryAge: 2 * time.Minute,
+		NewestEntryAge: time.Minute,
+	}, stats)
+
+	// The launches of pad_1 from two days ago, launch pads are kept
+	invalidated, err := cachedService.Invalidate(ctx, "pad_1", now.AddDate(0, 0, -2), time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, invalidated)
+
+	// Every launch three days ago
+	invalidated, err = cachedService.Invalidate(ctx, "", now.AddDate(0, 0, -3), now.AddDate(0, 0, -3).Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, invalidated)
+
+	stats, err = cachedService.Stats(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Launchpads)
+	assert.Equal(t, 2, stats.LaunchDates)
+
+	// Everything
+	invalidated, err = cachedService.Invalidate(ctx, "", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, invalidated)
+
+	stats, err = cachedService.Stats(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.Launchpads)
+	assert.Equal(t, 0, stats.LaunchDates)
+	assert.Equal(t, time.Duration(0), stats.OldestEntryAge)
+}
+
+func Test_Cache_Prewarm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	now := time.Date(2025, 12, 01, 12, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(now)
+	cachedService := NewCache(mockService, clock, CacheConfig{FreshFor: time.Hour})
+	ctx := context.Background()
+
+	pads := []smodels.Launchpad{{ID: "pad-1"}, {ID: "pad-2"}}
+	mockService.EXPECT().GetLaunchPads(gomock.Any()).Return(pads, nil).Times(1)
+	for _, pad := range pads {
+		var launches []smodels.Launch
+		for day := 0; day < 3; day++ {
+			launches = append(launches, smodels.Launch{
+				Name:    fmt.Sprintf("%s-%d", pad.ID, day),
+				DateUTC: time.Date(2025, 12, 1+day, 10, 0, 0, 0, time.UTC),
+			})
+		}
+		mockService.EXPECT().
+			GetLaunchesForRange(gomock.Any(), pad.ID, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 4, 0, 0, 0, 0, time.UTC)).
+			Return(launches, nil).Times(1)
+	}
+
+	assert.NoError(t, cachedService.Prewarm(ctx, 3))
+
+	// Served from the cache
+	pad, err := cachedService.GetLaunchPadForID(ctx, "pad-2")
+	assert.NoError(t, err)
+	assert.Equal(t, &pads[1], pad)
+	launches, err := cachedService.GetLaunchesForDate(ctx, "pad-1", time.Date(2025, 12, 2, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, []smodels.Launch{{Name: "pad-1-1", DateUTC: time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)}}, launches)
+}
+
+func Test_Cache_PrewarmError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	cachedService := NewCache(mockService, clockwork.NewFakeClock(), CacheConfig{})
+
+	mockService.EXPECT().GetLaunchPads(gomock.Any()).Return(nil, fmt.Errorf("unavailable"))
+
+	err := cachedService.Prewarm(context.Background(), 3)
+	assert.ErrorContains(t, err, "unable to prewarm launch pads")
+}
+
+func TestGetLaunchesForRange_FetchesMissingDaysOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	now := time.Date(2025, 12, 01, 0, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(now)
+	cachedService := NewCache(mockService, clock, CacheConfig{PastLaunchTTL: 24 * time.Hour})
+	ctx := context.Background()
+
+	day := func(d int) time.Time {
+		return now.AddDate(0, 0, -10+d)
+	}
+	// Day 2 is already cached
+	mockService.EXPECT().
+		GetLaunchesForDate(gomock.Any(), "pad-1", day(2)).
+		Return([]smodels.Launch{{Name: "Cached", DateUTC: day(2).Add(time.Hour)}}, nil)
+	_, err := cachedService.GetLaunchesForDate(ctx, "pad-1", day(2))
+	assert.NoError(t, err)
+
+	// Days 0 to 4 are asked for at once, the launch of day 2 reported again is not duplicated
+	mockService.EXPECT().
+		GetLaunchesForRange(gomock.Any(), "pad-1", day(0), day(5)).
+		Return([]smodels.Launch{
+			{Name: "Day 0", DateUTC: day(0).Add(time.Hour)},
+			{Name: "Cached", DateUTC: day(2).Add(time.Hour)},
+			{Name: "Day 4", DateUTC: day(4).Add(time.Hour)},
+		}, nil).Times(1)
+
+	launches, err := cachedService.GetLaunchesForRange(ctx, "pad-1", day(0), day(5))
+	assert.NoError(t, err)
+	assert.Equal(t, []smodels.Launch{
+		{Name: "Day 0", DateUTC: day(0).Add(time.Hour)},
+		{Name: "Cached", DateUTC: day(2).Add(time.Hour)},
+		{Name: "Day 4", DateUTC: day(4).Add(time.Hour)},
+	}, launches)
+
+	// Every day is cached now, including the days without launches
+	launches, err = cachedService.GetLaunchesForDate(ctx, "pad-1", day(3))
+	assert.NoError(t, err)
+	assert.Empty(t, launches)
+	launches, err = cachedService.GetLaunchesForRange(ctx, "pad-1", day(1), day(5))
+	assert.NoError(t, err)
+	assert.Len(t, launches, 2)
+}
+
+func TestGetLaunchesForRange_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	cachedService := NewCache(mockService, clockwork.NewFakeClock(), CacheConfig{})
+
+	mockService.EXPECT().
+		GetLaunchesForRange(gomock.Any(), "pad-1", gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("unavailable"))
+
+	from := time.Date(2025, 12, 01, 0, 0, 0, 0, time.UTC)
+	_, err := cachedService.GetLaunchesForRange(context.Background(), "pad-1", from, from.AddDate(0, 0, 3))
+	assert.ErrorContains(t, err, "unable to get launches")
+}
@@ -0,0 +1,208 @@
+package spacex
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
+)
+
+var ErrTooManyRequests = errors.New("too many pending spacex calls")
+
+// LimiterConfig configures the outbound rate limiter and bulkhead of NewLimiter
+type LimiterConfig struct {
+	// RatePerSecond is the sustained number of calls per second, zero disables rate limiting
+	RatePerSecond float64
+	// Burst is the number of calls that can be made at once above the sustained rate
+	Burst int
+	// MaxConcurrency is the maximum number of calls in flight, zero disables the bulkhead
+	MaxConcurrency int
+	// MaxQueue is the number of calls that can wait for a token or a free slot, further calls are rejected
+	MaxQueue int
+	// Metrics receives the queued, in_flight, queued_total and rejected_total counters, optional
+	Metrics *expvar.Map
+}
+
+func DefaultLimiterConfig() LimiterConfig {
+	return LimiterConfig{
+		RatePerSecond:  10,
+		Burst:          20,
+		MaxConcurrency: 10,
+		MaxQueue:       100,
+	}
+}
+
+type limiter struct {
+	svc    SpaceXService
+	clock  clockwork.Clock
+	config LimiterConfig
+	slots  chan struct{}
+
+	mu      sync.Mutex
+	tokens  float64
+	last    time.Time
+	pending int
+}
+
+// NewLimiter limits the rate and the concurrency of the calls made to the SpaceX service.
+// Calls that do not fit in the queue fail with a models.UpstreamUnavailableError.
+func NewLimiter(svc SpaceXService, clock clockwork.Clock, config LimiterConfig) SpaceXService {
+	l := &limiter{
+		svc:    svc,
+		clock:  clock,
+		config: config,
+		tokens: float64(config.Burst),
+		last:   clock.Now(),
+	}
+	if config.MaxConcurrency > 0 {
+		l.slots = make(chan struct{}, config.MaxConcurrency)
+	}
+	return l
+}
+
+func (l *limiter) GetLaunchPads(ctx context.Context) ([]smodels.Launchpad, error) {
+	release, err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.svc.GetLaunchPads(ctx)
+}
+
+func (l *limiter) GetLaunchPadForID(ctx context.Context, launchPadID string) (*smodels.Launchpad, error) {
+	release, err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.svc.GetLaunchPadForID(ctx, launchPadID)
+}
+
+func (l *limiter) GetLaunchesForDate(ctx context.Context, launchPadID string, date time.Time) ([]smodels.Launch, error) {
+	release, err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.svc.GetLaunchesForDate(ctx, launchPadID, date)
+}
+
+func (l *limiter) GetLaunchesForRange(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]smodels.Launch, error) {
+	release, err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.svc.GetLaunchesForRange(ctx, launchPadID, from, to)
+}
+
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	wait, ok := l.admit()
+	if !ok {
+		l.count("rejected_total", 1)
+		return nil, &models.UpstreamUnavailableError{
+			RetryAfter: time.Second,
+			Err:        ErrTooManyRequests,
+		}
+	}
+
+	queued := false
+	dequeue := func() {
+		if queued {
+			queued = false
+			l.count("queued", -1)
+		}
+	}
+	defer dequeue()
+
+	if wait > 0 {
+		queued = true
+		l.count("queued", 1)
+		l.count("queued_total", 1)
+		select {
+		case <-ctx.Done():
+			l.leave(true)
+			return nil, ctx.Err()
+		case <-l.clock.After(wait):
+		}
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			if !queued {
+				queued = true
+				l.count("queued", 1)
+				l.count("queued_total", 1)
+			}
+			select {
+			case <-ctx.Done():
+				// The token was spent once the rate wait was over, giving it back would exceed the rate
+				l.leave(false)
+				return nil, ctx.Err()
+			case l.slots <- struct{}{}:
+			}
+		}
+	}
+	dequeue()
+
+	l.count("in_flight", 1)
+	return func() {
+		l.count("in_flight", -1)
+		if l.slots != nil {
+			<-l.slots
+		}
+		l.leave(false)
+	}, nil
+}
+
+// admit reserves a token and a place in the queue, it returns how long the caller has to wait for the token
+func (l *limiter) admit() (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.config.MaxConcurrency > 0 && l.pending >= l.config.MaxConcurrency+l.config.MaxQueue {
+		return 0, false
+	}
+	var wait time.Duration
+	if l.config.RatePerSecond > 0 {
+		now := l.clock.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.config.RatePerSecond
+		if l.tokens > float64(l.config.Burst) {
+			l.tokens = float64(l.config.Burst)
+		}
+		l.last = now
+		if l.tokens < 1 {
+			wait = time.Duration((1 - l.tokens) / l.config.RatePerSecond * float64(time.Second))
+			// Without a bulkhead the queue is bounded by how many tokens can be owed
+			if l.config.MaxConcurrency <= 0 && -l.tokens >= float64(l.config.MaxQueue) {
+				return 0, false
+			}
+		}
+		l.tokens--
+	}
+	l.pending++
+	return wait, true
+}
+
+// leave gives back the place in the queue, and the token if its rate wait was cancelled
+func (l *limiter) leave(refundToken bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending--
+	if refundToken && l.config.RatePerSecond > 0 && l.tokens < float64(l.config.Burst) {
+		l.tokens++
+	}
+}
+
+func (l *limiter) count(key string, delta int64) {
+	if l.config.Metrics != nil {
+		l.config.Metrics.Add(key, delta)
+	}
+}
@@ -0,0 +1,176 @@
+package spacex
+
+import (
+	"context"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
+)
+
+func TestLimiter_RateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	clock := clockwork.NewFakeClock()
+	metrics := new(expvar.Map).Init()
+	svc := NewLimiter(mockService, clock, LimiterConfig{
+		RatePerSecond: 1,
+		Burst:         1,
+		MaxQueue:      5,
+		Metrics:       metrics,
+	})
+
+	mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").
+		Return(&smodels.Launchpad{ID: "pad-1"}, nil).Times(2)
+
+	// The burst allows the first call straight away
+	_, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+		assert.NoError(t, err)
+	}()
+
+	clock.BlockUntil(1)
+	assert.Equal(t, "1", metrics.Get("queued").String())
+	clock.Advance(time.Second)
+	<-done
+	assert.Equal(t, "0", metrics.Get("queued").String())
+	assert.Equal(t, "1", metrics.Get("queued_total").String())
+}
+
+func TestLimiter_Bulkhead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	metrics := new(expvar.Map).Init()
+	svc := NewLimiter(mockService, clockwork.NewFakeClock(), LimiterConfig{
+		MaxConcurrency: 1,
+		MaxQueue:       1,
+		Metrics:        metrics,
+	})
+
+	started := make(chan struct{}, 2)
+	unblock := make(chan struct{})
+	mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").
+		DoAndReturn(func(ctx context.Context, launchPadID string) (*smodels.Launchpad, error) {
+			started <- struct{}{}
+			<-unblock
+			return &smodels.Launchpad{ID: launchPadID}, nil
+		}).Times(2)
+
+	done := make(chan struct{}, 2)
+	call := func() {
+		_, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+		assert.NoError(t, err)
+		done <- struct{}{}
+	}
+
+	// First call takes the only slot, second one waits in the queue
+	go call()
+	<-started
+	go call()
+	require.Eventually(t, func() bool {
+		return metrics.Get("queued") != nil && metrics.Get("queued").String() == "1"
+	}, time.Second, time.Millisecond)
+
+	// Third call does not fit in the queue
+	_, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+	assert.ErrorIs(t, err, models.ErrUpstreamUnavailable)
+	assert.ErrorIs(t, err, ErrTooManyRequests)
+	assert.Equal(t, "1", metrics.Get("rejected_total").String())
+
+	close(unblock)
+	<-done
+	<-done
+	assert.Equal(t, "0", metrics.Get("in_flight").String())
+	assert.Equal(t, "0", metrics.Get("queued").String())
+}
+
+func TestLimiter_CancelledWhileQueued(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	clock := clockwork.NewFakeClock()
+	svc := NewLimiter(mockService, clock, LimiterConfig{
+		RatePerSecond: 1,
+		Burst:         0,
+		MaxQueue:      5,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := svc.GetLaunchesForDate(ctx, "pad-1", clock.Now())
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLimiter_CancelledWaitingForSlot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	clock := clockwork.NewFakeClock()
+	metrics := new(expvar.Map).Init()
+	svc := NewLimiter(mockService, clock, LimiterConfig{
+		RatePerSecond:  1,
+		Burst:          2,
+		MaxConcurrency: 1,
+		MaxQueue:       5,
+		Metrics:        metrics,
+	})
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").
+		DoAndReturn(func(ctx context.Context, launchPadID string) (*smodels.Launchpad, error) {
+			started <- struct{}{}
+			<-unblock
+			return &smodels.Launchpad{ID: launchPadID}, nil
+		}).Times(2)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+		assert.NoError(t, err)
+	}()
+	<-started
+
+	// The second call gets the last token and waits for the slot, the token is not given back when it gives up
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		_, err := svc.GetLaunchPadForID(ctx, "pad-1")
+		cancelled <- err
+	}()
+	require.Eventually(t, func() bool {
+		return metrics.Get("queued") != nil && metrics.Get("queued").String() == "1"
+	}, time.Second, time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-cancelled, context.Canceled)
+	unblock <- struct{}{}
+	<-done
+
+	// So the third call waits for a new token
+	done = make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+		assert.NoError(t, err)
+	}()
+	require.Eventually(t, func() bool {
+		return metrics.Get("queued_total").String() == "2"
+	}, time.Second, time.Millisecond)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	<-started
+	close(unblock)
+	<-done
+}
@@ -0,0 +1,86 @@
+package spacex
+
+import (
+	"context"
+	"time"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/schedule"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
+)
+
+type provider struct {
+	name string
+	svc  SpaceXService
+}
+
+// NewProvider adapts a SpaceX compatible API to a schedule.LaunchScheduleProvider
+func NewProvider(name string, svc SpaceXService) schedule.LaunchScheduleProvider {
+	return &provider{
+		name: name,
+		svc:  svc,
+	}
+}
+
+func (p *provider) Name() string {
+	return p.name
+}
+
+func (p *provider) GetLaunchPad(ctx context.Context, launchPadID string) (*schedule.LaunchPad, error) {
+	pad, err := p.svc.GetLaunchPadForID(ctx, launchPadID)
+	if err != nil {
+		return nil, err
+	}
+	result := p.toLaunchPad(*pad)
+	return &result, nil
+}
+
+func (p *provider) GetLaunchPads(ctx context.Context) ([]schedule.LaunchPad, error) {
+	pads, err := p.svc.GetLaunchPads(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]schedule.LaunchPad, 0, len(pads))
+	for _, pad := range pads {
+		result = append(result, p.toLaunchPad(pad))
+	}
+	return result, nil
+}
+
+func (p *provider) GetLaunchesForDate(ctx context.Context, launchPadID string, date time.Time) ([]schedule.Launch, error) {
+	launches, err := p.svc.GetLaunchesForDate(ctx, launchPadID, date)
+	if err != nil {
+		return nil, err
+	}
+	return p.toLaunches(launches), nil
+}
+
+func (p *provider) GetLaunchesForRange(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]schedule.Launch, error) {
+	launches, err := p.svc.GetLaunchesForRange(ctx, launchPadID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return p.toLaunches(launches), nil
+}
+
+func (p *provider) toLaunchPad(pad smodels.Launchpad) schedule.LaunchPad {
+	return schedule.LaunchPad{
+		ID:       pad.ID,
+		Name:     pad.Name,
+		Status:   pad.Status,
+		Provider: p.name,
+	}
+}
+
+func (p *provider) toLaunches(launches []smodels.Launch) []schedule.Launch {
+	result := make([]schedule.Launch, 0, len(launches))
+	for _, launch := range launches {
+		result = append(result, schedule.Launch{
+			ID:          launch.ID,
+			Name:        launch.Name,
+			DateUTC:     launch.DateUTC,
+			LaunchPadID: launch.Launchpad,
+			Provider:    p.name,
+		})
+	}
+	return result
+}
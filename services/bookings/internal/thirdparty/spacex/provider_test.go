@@ -0,0 +1,54 @@
+package spacex
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/schedule"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
+)
+
+func TestProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockSpaceXService := mocks.NewMockSpaceXService(ctrl)
+	provider := NewProvider("spacex", mockSpaceXService)
+	ctx := context.Background()
+	const launchPadID = "5e9e4501f509094ba4566f84"
+	date := time.Date(2022, 7, 7, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "spacex", provider.Name())
+
+	mockSpaceXService.EXPECT().GetLaunchPadForID(gomock.Any(), launchPadID).
+		Return(&smodels.Launchpad{ID: launchPadID, Name: "CCSFS SLC 40", Status: "active"}, nil)
+	pad, err := provider.GetLaunchPad(ctx, launchPadID)
+	require.NoError(t, err)
+	assert.Equal(t, &schedule.LaunchPad{ID: launchPadID, Name: "CCSFS SLC 40", Status: "active", Provider: "spacex"}, pad)
+
+	mockSpaceXService.EXPECT().GetLaunchPadForID(gomock.Any(), "unknown").Return(nil, models.ErrNotFoundLaunchpad)
+	_, err = provider.GetLaunchPad(ctx, "unknown")
+	assert.ErrorIs(t, err, models.ErrNotFoundLaunchpad)
+
+	mockSpaceXService.EXPECT().GetLaunchPads(gomock.Any()).
+		Return([]smodels.Launchpad{{ID: launchPadID, Name: "CCSFS SLC 40", Status: "active"}}, nil)
+	pads, err := provider.GetLaunchPads(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []schedule.LaunchPad{{ID: launchPadID, Name: "CCSFS SLC 40", Status: "active", Provider: "spacex"}}, pads)
+
+	mockSpaceXService.EXPECT().GetLaunchesForDate(gomock.Any(), launchPadID, date).
+		Return([]smodels.Launch{{Name: "Starlink 4-21 (v1.5)", DateUTC: date.Add(13 * time.Hour), Launchpad: launchPadID}}, nil)
+	launches, err := provider.GetLaunchesForDate(ctx, launchPadID, date)
+	require.NoError(t, err)
+	assert.Equal(t, []schedule.Launch{{
+		Name:        "Starlink 4-21 (v1.5)",
+		DateUTC:     date.Add(13 * time.Hour),
+		LaunchPadID: launchPadID,
+		Provider:    "spacex",
+	}}, launches)
+}
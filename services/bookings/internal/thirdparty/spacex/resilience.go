@@ -0,0 +1,259 @@
+package spacex
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
+)
+
+// ResilienceConfig configures the retries and the circuit breaker of NewResilient
+type ResilienceConfig struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry, it is doubled for every further retry
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff, a Retry-After longer than this is not waited for
+	MaxDelay time.Duration
+	// FailureThreshold is the number of consecutive failed calls that opens the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a trial call is let through
+	OpenTimeout time.Duration
+}
+
+func DefaultResilienceConfig() ResilienceConfig {
+	return ResilienceConfig{
+		MaxRetries:       3,
+		BaseDelay:        200 * time.Millisecond,
+		MaxDelay:         5 * time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type resilient struct {
+	svc    SpaceXService
+	clock  clockwork.Clock
+	config ResilienceConfig
+	// jitter returns the actual wait for a computed backoff
+	jitter func(time.Duration) time.Duration
+
+	mu                  sync.Mutex
+	state               circuitState
+	consecutiveFailures int
+	openedAt            time.Time
+}
+
+// NewResilient wraps the SpaceX service with bounded exponential retries and a circuit breaker.
+// Once retries are exhausted or the circuit is open a models.UpstreamUnavailableError is returned.
+func NewResilient(svc SpaceXService, clock clockwork.Clock, config ResilienceConfig) SpaceXService {
+	return &resilient{
+		svc:    svc,
+		clock:  clock,
+		config: config,
+		jitter: equalJitter,
+	}
+}
+
+func (r *resilient) GetLaunchPads(ctx context.Context) ([]smodels.Launchpad, error) {
+	var result []smodels.Launchpad
+	err := r.call(ctx, func(ctx context.Context) error {
+		res, err := r.svc.GetLaunchPads(ctx)
+		result = res
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *resilient) GetLaunchPadForID(ctx context.Context, launchPadID string) (*smodels.Launchpad, error) {
+	var result *smodels.Launchpad
+	err := r.call(ctx, func(ctx context.Context) error {
+		res, err := r.svc.GetLaunchPadForID(ctx, launchPadID)
+		result = res
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *resilient) GetLaunchesForDate(ctx context.Context, launchPadID string, date time.Time) ([]smodels.Launch, error) {
+	var result []smodels.Launch
+	err := r.call(ctx, func(ctx context.Context) error {
+		res, err := r.svc.GetLaunchesForDate(ctx, launchPadID, date)
+		result = res
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *resilient) GetLaunchesForRange(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]smodels.Launch, error) {
+	var result []smodels.Launch
+	err := r.call(ctx, func(ctx context.Context) error {
+		res, err := r.svc.GetLaunchesForRange(ctx, launchPadID, from, to)
+		result = res
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *resilient) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if retryAfter, ok := r.allow(); !ok {
+		return &models.UpstreamUnavailableError{
+			RetryAfter: retryAfter,
+			Err:        errors.New("circuit breaker is open"),
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := fn(ctx)
+		if err != nil && ctx.Err() != nil {
+			// The caller gave up, this says nothing about the health of SpaceX
+			r.release()
+			return err
+		}
+		if err == nil || !isRetryable(err) {
+			// Errors that are not caused by SpaceX being unhealthy (e.g. launch pad not found)
+			// must not open the circuit
+			r.onResult(true)
+			return err
+		}
+
+		delay := r.backoff(attempt)
+		retryAfter := retryAfterOf(err)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		if attempt >= r.config.MaxRetries || delay > r.config.MaxDelay {
+			r.onResult(false)
+			return &models.UpstreamUnavailableError{
+				RetryAfter: retryAfter,
+				Err:        err,
+			}
+		}
+
+		log.WithError(err).WithField("attempt", attempt+1).Warn("spacex call failed, retrying")
+		select {
+		case <-ctx.Done():
+			r.release()
+			return ctx.Err()
+		case <-r.clock.After(delay):
+		}
+	}
+}
+
+// allow reports whether a call can be made, if not it returns the time left until the circuit is half open
+func (r *resilient) allow() (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch r.state {
+	case circuitOpen:
+		elapsed := r.clock.Since(r.openedAt)
+		if elapsed < r.config.OpenTimeout {
+			return r.config.OpenTimeout - elapsed, false
+		}
+		// Let a single trial call through
+		r.state = circuitHalfOpen
+		return 0, true
+	case circuitHalfOpen:
+		return r.config.OpenTimeout, false
+	default:
+		return 0, true
+	}
+}
+
+func (r *resilient) onResult(success bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if success {
+		if r.state != circuitClosed {
+			log.Info("spacex circuit breaker closed")
+		}
+		r.state = circuitClosed
+		r.consecutiveFailures = 0
+		return
+	}
+	r.consecutiveFailures++
+	if r.state == circuitHalfOpen || r.consecutiveFailures >= r.config.FailureThreshold {
+		if r.state != circuitOpen {
+			log.WithField("failures", r.consecutiveFailures).Warn("spacex circuit breaker opened")
+		}
+		r.state = circuitOpen
+		r.openedAt = r.clock.Now()
+	}
+}
+
+// release gives back a half open trial without recording a result
+func (r *resilient) release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.state == circuitHalfOpen {
+		r.state = circuitOpen
+		r.openedAt = r.clock.Now().Add(-r.config.OpenTimeout)
+	}
+}
+
+func (r *resilient) backoff(attempt int) time.Duration {
+	delay := r.config.BaseDelay
+	for i := 0; i < attempt && delay < r.config.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > r.config.MaxDelay {
+		delay = r.config.MaxDelay
+	}
+	return r.jitter(delay)
+}
+
+// equalJitter keeps at least half of the backoff and randomises the rest
+func equalJitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)))
+}
+
+func isRetryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	// Transport level errors (timeouts, connection resets, DNS) are all wrapped in url.Error
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+func retryAfterOf(err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
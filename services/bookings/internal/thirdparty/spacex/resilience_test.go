@@ -0,0 +1,186 @@
+package spacex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
+)
+
+func newTestResilient(svc SpaceXService, clock clockwork.Clock, config ResilienceConfig) *resilient {
+	r := NewResilient(svc, clock, config).(*resilient)
+	r.jitter = func(d time.Duration) time.Duration { return d }
+	return r
+}
+
+func TestResilient_RetriesTransientErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	clock := clockwork.NewFakeClock()
+	svc := newTestResilient(mockService, clock, ResilienceConfig{
+		MaxRetries:       3,
+		MaxDelay:         time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      time.Minute,
+	})
+	expected := &smodels.Launchpad{ID: "pad-1"}
+
+	gomock.InOrder(
+		mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").
+			Return(nil, &StatusError{Resource: "launchpads", StatusCode: http.StatusBadGateway}),
+		mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").
+			Return(nil, &url.Error{Op: "Get", URL: "http://spacex", Err: errors.New("connection reset")}),
+		mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").
+			Return(expected, nil),
+	)
+
+	res, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+	require.NoError(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestResilient_DoesNotRetryClientErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	svc := newTestResilient(mockService, clockwork.NewFakeClock(), DefaultResilienceConfig())
+
+	mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").
+		Return(nil, models.ErrNotFoundLaunchpad).Times(1)
+
+	_, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+	assert.ErrorIs(t, err, models.ErrNotFoundLaunchpad)
+	assert.NotErrorIs(t, err, models.ErrUpstreamUnavailable)
+}
+
+func TestResilient_ExhaustedRetriesReturnUpstreamUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	svc := newTestResilient(mockService, clockwork.NewFakeClock(), ResilienceConfig{
+		MaxRetries:       2,
+		MaxDelay:         time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      time.Minute,
+	})
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockService.EXPECT().GetLaunchesForDate(gomock.Any(), "pad-1", date).
+		Return(nil, &StatusError{Resource: "launches", StatusCode: http.StatusServiceUnavailable}).Times(3)
+
+	_, err := svc.GetLaunchesForDate(context.Background(), "pad-1", date)
+	assert.ErrorIs(t, err, models.ErrUpstreamUnavailable)
+}
+
+func TestResilient_HonoursRetryAfter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	clock := clockwork.NewFakeClock()
+	svc := newTestResilient(mockService, clock, ResilienceConfig{
+		MaxRetries:       1,
+		BaseDelay:        time.Millisecond,
+		MaxDelay:         10 * time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      time.Minute,
+	})
+	expected := &smodels.Launchpad{ID: "pad-1"}
+
+	gomock.InOrder(
+		mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").
+			Return(nil, &StatusError{Resource: "launchpads", StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second}),
+		mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").
+			Return(expected, nil),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		res, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+	}()
+
+	clock.BlockUntil(1)
+	// The base delay alone must not release the retry
+	clock.Advance(time.Second)
+	select {
+	case <-done:
+		t.Fatal("retried before Retry-After elapsed")
+	default:
+	}
+	clock.Advance(2 * time.Second)
+	<-done
+}
+
+func TestResilient_RetryAfterAboveMaxDelayFailsFast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	svc := newTestResilient(mockService, clockwork.NewFakeClock(), ResilienceConfig{
+		MaxRetries:       3,
+		MaxDelay:         time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      time.Minute,
+	})
+
+	mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").
+		Return(nil, &StatusError{Resource: "launchpads", StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}).Times(1)
+
+	_, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+	var upstreamErr *models.UpstreamUnavailableError
+	require.ErrorAs(t, err, &upstreamErr)
+	assert.Equal(t, time.Minute, upstreamErr.RetryAfter)
+}
+
+func TestResilient_CircuitBreaker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	clock := clockwork.NewFakeClock()
+	svc := newTestResilient(mockService, clock, ResilienceConfig{
+		MaxRetries:       0,
+		MaxDelay:         time.Second,
+		FailureThreshold: 2,
+		OpenTimeout:      30 * time.Second,
+	})
+	upstreamErr := &StatusError{Resource: "launchpads", StatusCode: http.StatusInternalServerError}
+	expected := &smodels.Launchpad{ID: "pad-1"}
+
+	// Two consecutive failures open the circuit
+	mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").Return(nil, upstreamErr).Times(2)
+	for i := 0; i < 2; i++ {
+		_, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+		assert.ErrorIs(t, err, models.ErrUpstreamUnavailable)
+	}
+
+	// While open no calls reach SpaceX
+	clock.Advance(10 * time.Second)
+	_, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+	var openErr *models.UpstreamUnavailableError
+	require.ErrorAs(t, err, &openErr)
+	assert.Equal(t, 20*time.Second, openErr.RetryAfter)
+
+	// A failed trial call opens it again
+	clock.Advance(20 * time.Second)
+	mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").Return(nil, upstreamErr).Times(1)
+	_, err = svc.GetLaunchPadForID(context.Background(), "pad-1")
+	assert.ErrorIs(t, err, models.ErrUpstreamUnavailable)
+	_, err = svc.GetLaunchPadForID(context.Background(), "pad-1")
+	assert.ErrorIs(t, err, models.ErrUpstreamUnavailable)
+
+	// A successful trial call closes it
+	clock.Advance(30 * time.Second)
+	mockService.EXPECT().GetLaunchPadForID(gomock.Any(), "pad-1").Return(expected, nil).Times(2)
+	for i := 0; i < 2; i++ {
+		res, err := svc.GetLaunchPadForID(context.Background(), "pad-1")
+		require.NoError(t, err)
+		assert.Equal(t, expected, res)
+	}
+}
@@ -4,6 +4,7 @@ import "time"
 
 // Launch represents the structure of a launch from the SpaceX API
 type Launch struct {
+	ID        string    `json:"id"`
 	Name      string    `json:"name"`
 	DateUTC   time.Time `json:"date_utc"`
 	Launchpad string    `json:"launchpad"`
@@ -16,9 +17,11 @@ type LaunchQueryRequest struct {
 	Options LaunchQueryOptions `json:"options"`
 }
 
-// LaunchQueryOptions holds the limit option fot the /launches/query endpoint
+// LaunchQueryOptions holds the pagination options for the /launches/query endpoint
 type LaunchQueryOptions struct {
 	Limit int `json:"limit"`
+	// Page starts at 1, the first page is returned when it is not set
+	Page int `json:"page,omitempty"`
 }
 
 // LaunchQuery holds the launchpad and date query for the /launches/query endpoint
@@ -7,32 +7,75 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/jonboulle/clockwork"
+
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
 
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
 )
 
+const (
+	// rangePageSize is the number of launches asked per page of a range query
+	rangePageSize = 100
+	// maxRangePages stops following the pages of a range query that keeps growing
+	maxRangePages = 50
+)
+
 //go:generate mockgen -package=mocks -destination=../../mocks/spacex.go github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex SpaceXService
 type SpaceXService interface {
+	GetLaunchPads(ctx context.Context) ([]smodels.Launchpad, error)
 	GetLaunchPadForID(ctx context.Context, launchPadID string) (*smodels.Launchpad, error)
 	GetLaunchesForDate(ctx context.Context, launchPadID string, date time.Time) ([]smodels.Launch, error)
+	// GetLaunchesForRange returns the launches of the launch pad from (inclusive) to (exclusive)
+	GetLaunchesForRange(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]smodels.Launch, error)
+}
+
+// StatusError is returned when SpaceX responds with a non 200 status code
+type StatusError struct {
+	Resource   string
+	StatusCode int
+	// RetryAfter is parsed from the Retry-After header, zero if it was not set
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failed to fetch %s: status code %d", e.Resource, e.StatusCode)
 }
 
 type service struct {
 	baseURL string
 	client  *http.Client
+	// clock is the time the Retry-After dates are relative to
+	clock clockwork.Clock
 }
 
-func New(baseURL string, client *http.Client) SpaceXService {
+func New(baseURL string, client *http.Client, clock clockwork.Clock) SpaceXService {
 	return &service{
 		baseURL: baseURL,
 		client:  client,
+		clock:   clock,
 	}
 }
 
 func (s *service) GetLaunchPadForID(ctx context.Context, launchPadID string) (*smodels.Launchpad, error) {
+	launchpads, err := s.GetLaunchPads(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pad := range launchpads {
+		if pad.ID == launchPadID {
+			return &pad, nil
+		}
+	}
+
+	return nil, models.ErrNotFoundLaunchpad
+}
+
+func (s *service) GetLaunchPads(ctx context.Context) ([]smodels.Launchpad, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/launchpads", s.baseURL), nil)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create get request: %w", err)
@@ -44,7 +87,7 @@ func (s *service) GetLaunchPadForID(ctx context.Context, launchPadID string) (*s
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch launchpads: status code %d", resp.StatusCode)
+		return nil, newStatusError("launchpads", resp, s.clock.Now())
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -57,14 +100,7 @@ func (s *service) GetLaunchPadForID(ctx context.Context, launchPadID string) (*s
 	if err != nil {
 		return nil, err
 	}
-
-	for _, pad := range launchpads {
-		if pad.ID == launchPadID {
-			return &pad, nil
-		}
-	}
-
-	return nil, models.ErrNotFoundLaunchpad
+	return launchpads, nil
 }
 
 func (s *service) GetLaunchesForDate(ctx context.Context, launchPadID string, date time.Time) ([]smodels.Launch, error) {
@@ -84,6 +120,51 @@ func (s *service) GetLaunchesForDate(ctx context.Context, launchPadID string, da
 		},
 	}
 
+	page, err := s.queryLaunches(ctx, queryRequest)
+	if err != nil {
+		return nil, err
+	}
+	return page.Docs, nil
+}
+
+func (s *service) GetLaunchesForRange(ctx context.Context, launchPadID string, from time.Time, to time.Time) ([]smodels.Launch, error) {
+	queryRequest := smodels.LaunchQueryRequest{
+		Query: smodels.LaunchQuery{
+			Launchpad: launchPadID,
+			DateUTC: smodels.DateRange{
+				Gte: from.UTC().Format(time.RFC3339),
+				Lt:  to.UTC().Format(time.RFC3339),
+			},
+		},
+		Options: smodels.LaunchQueryOptions{
+			Limit: rangePageSize,
+			Page:  1,
+		},
+	}
+
+	var launches []smodels.Launch
+	for pages := 0; pages < maxRangePages; pages++ {
+		page, err := s.queryLaunches(ctx, queryRequest)
+		if err != nil {
+			return nil, err
+		}
+		launches = append(launches, page.Docs...)
+		if !page.HasNextPage || page.NextPage == nil {
+			return launches, nil
+		}
+		queryRequest.Options.Page = *page.NextPage
+	}
+	return nil, fmt.Errorf("more than %d pages of launches", maxRangePages)
+}
+
+// launchesPage is a page of the /launches/query endpoint
+type launchesPage struct {
+	Docs        []smodels.Launch `json:"docs"`
+	HasNextPage bool             `json:"hasNextPage"`
+	NextPage    *int             `json:"nextPage"`
+}
+
+func (s *service) queryLaunches(ctx context.Context, queryRequest smodels.LaunchQueryRequest) (*launchesPage, error) {
 	reqBody, err := json.Marshal(queryRequest)
 	if err != nil {
 		return nil, fmt.Errorf("unable to marshal request: %w", err)
@@ -101,7 +182,7 @@ func (s *service) GetLaunchesForDate(ctx context.Context, launchPadID string, da
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch launches: status code %d", resp.StatusCode)
+		return nil, newStatusError("launches", resp, s.clock.Now())
 	}
 
 	respBody, err := io.ReadAll(resp.Body)
@@ -109,13 +190,37 @@ func (s *service) GetLaunchesForDate(ctx context.Context, launchPadID string, da
 		return nil, err
 	}
 
-	var launchesResponse struct {
-		Docs []smodels.Launch `json:"docs"`
-	}
-	err = json.Unmarshal(respBody, &launchesResponse)
+	var page launchesPage
+	err = json.Unmarshal(respBody, &page)
 	if err != nil {
 		return nil, err
 	}
+	return &page, nil
+}
 
-	return launchesResponse.Docs, nil
+func newStatusError(resource string, resp *http.Response, now time.Time) *StatusError {
+	return &StatusError{
+		Resource:   resource,
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), now),
+	}
+}
+
+// parseRetryAfter supports both formats of the header: delay in seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := date.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
 }
@@ -2,7 +2,7 @@ package spacex
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,9 +10,13 @@ import (
 
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/spacexfake"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/cassette"
 )
 
 func TestGetLaunchPadForID(t *testing.T) {
@@ -50,7 +54,7 @@ func TestGetLaunchPadForID(t *testing.T) {
 			mockResponseCode: http.StatusInternalServerError,
 			mockResponseBody: ``,
 			expectedResult:   nil,
-			expectedError:    errors.New("failed to fetch launchpads: status code 500"),
+			expectedError:    &StatusError{Resource: "launchpads", StatusCode: http.StatusInternalServerError},
 		},
 	}
 
@@ -64,7 +68,7 @@ func TestGetLaunchPadForID(t *testing.T) {
 			defer ts.Close()
 
 			client := ts.Client()
-			svc := New(ts.URL, client)
+			svc := New(ts.URL, client, clockwork.NewRealClock())
 
 			result, err := svc.GetLaunchPadForID(context.Background(), tt.launchPadID)
 			if tt.expectedResult != nil {
@@ -123,7 +127,7 @@ func TestGetLaunchesForDate(t *testing.T) {
 			mockResponseCode: http.StatusInternalServerError,
 			mockResponseBody: ``,
 			expectedResult:   nil,
-			expectedError:    errors.New("failed to fetch launches: status code 500"),
+			expectedError:    &StatusError{Resource: "launches", StatusCode: http.StatusInternalServerError},
 		},
 	}
 
@@ -137,7 +141,7 @@ func TestGetLaunchesForDate(t *testing.T) {
 			defer ts.Close()
 
 			client := ts.Client()
-			svc := New(ts.URL, client)
+			svc := New(ts.URL, client, clockwork.NewRealClock())
 
 			result, err := svc.GetLaunchesForDate(context.Background(), tt.launchPadID, tt.date)
 			if tt.expectedResult != nil {
@@ -150,3 +154,93 @@ func TestGetLaunchesForDate(t *testing.T) {
 		})
 	}
 }
+
+func TestGetLaunchPadForID_RetryAfter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	svc := New(ts.URL, ts.Client(), clockwork.NewRealClock())
+
+	_, err := svc.GetLaunchPadForID(context.Background(), "1")
+	assert.Equal(t, &StatusError{
+		Resource:   "launchpads",
+		StatusCode: http.StatusTooManyRequests,
+		RetryAfter: 7 * time.Second,
+	}, err)
+}
+
+func TestGetLaunchPadForID_RetryAfterDate(t *testing.T) {
+	now := time.Date(2049, 7, 7, 12, 0, 0, 0, time.UTC)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", now.Add(30*time.Second).Format(http.TimeFormat))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	// The date is relative to the clock of the client, not to the wall clock
+	svc := New(ts.URL, ts.Client(), clockwork.NewFakeClockAt(now))
+
+	_, err := svc.GetLaunchPadForID(context.Background(), "1")
+	assert.Equal(t, &StatusError{
+		Resource:   "launchpads",
+		StatusCode: http.StatusServiceUnavailable,
+		RetryAfter: 30 * time.Second,
+	}, err)
+}
+
+// TestReplay runs the client against SpaceX payloads recorded with --spacex-cassette-mode=record
+func TestReplay(t *testing.T) {
+	transport, err := cassette.NewReplayer("testdata/spacex.json")
+	require.NoError(t, err)
+	svc := New("https://api.spacexdata.com/v4", &http.Client{Transport: transport}, clockwork.NewRealClock())
+	ctx := context.Background()
+
+	pad, err := svc.GetLaunchPadForID(ctx, "5e9e4501f509094ba4566f84")
+	require.NoError(t, err)
+	assert.Equal(t, "CCSFS SLC 40", pad.Name)
+
+	launches, err := svc.GetLaunchesForDate(ctx, "5e9e4501f509094ba4566f84", time.Date(2022, 7, 7, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []smodels.Launch{{
+		ID:        "62a9f89a20413d2695d8871a",
+		Name:      "Starlink 4-21 (v1.5)",
+		DateUTC:   time.Date(2022, 7, 7, 13, 11, 0, 0, time.UTC),
+		Launchpad: "5e9e4501f509094ba4566f84",
+		Success:   true,
+	}}, launches)
+
+	launches, err = svc.GetLaunchesForDate(ctx, "5e9e4501f509094ba4566f84", time.Date(2022, 7, 8, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Empty(t, launches)
+}
+
+func TestGetLaunchesForRange(t *testing.T) {
+	// More launches than fit on a page, so every page has to be followed
+	var launches []spacexfake.Document
+	for i := 0; i < 150; i++ {
+		launches = append(launches, spacexfake.Document{
+			"name":      fmt.Sprintf("Launch %d", i),
+			"date_utc":  time.Date(2049, 7, 1, 0, i, 0, 0, time.UTC).Format(time.RFC3339),
+			"launchpad": "pad-1",
+		})
+	}
+	launches = append(launches,
+		spacexfake.Document{"name": "Other pad", "date_utc": "2049-07-01T12:00:00Z", "launchpad": "pad-2"},
+		spacexfake.Document{"name": "Too late", "date_utc": "2049-07-02T00:00:00Z", "launchpad": "pad-1"},
+	)
+	ts := httptest.NewServer(spacexfake.New(spacexfake.Fixtures{Launches: launches}).Handler(""))
+	defer ts.Close()
+
+	svc := New(ts.URL, ts.Client(), clockwork.NewRealClock())
+	res, err := svc.GetLaunchesForRange(context.Background(), "pad-1",
+		time.Date(2049, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2049, 7, 2, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Len(t, res, 150)
+	for _, launch := range res {
+		assert.Equal(t, "pad-1", launch.Launchpad)
+		assert.Equal(t, 1, launch.DateUTC.Day())
+	}
+}
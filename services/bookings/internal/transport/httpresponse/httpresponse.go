@@ -0,0 +1,44 @@
+// Package httpresponse writes the JSON responses shared by the HTTP handlers
+package httpresponse
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	bookingsv1 "github.com/zsoltggs/tabeo-interview/services/bookings/pkg/bookings/v1"
+)
+
+// Write writes resp as JSON with status
+func Write(response http.ResponseWriter, status int, resp interface{}) {
+	respJSON, err := json.Marshal(resp)
+	if err != nil {
+		log.WithError(err).Error("unable to marshal response")
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	_, err = response.Write(respJSON)
+	if err != nil {
+		log.WithError(err).Error("unable to write response")
+	}
+}
+
+// WriteError writes an ErrorResponse with reason and status
+func WriteError(response http.ResponseWriter, status int, reason string) {
+	Write(response, status, bookingsv1.ErrorResponse{
+		Error: reason,
+	})
+}
+
+// RetryAfterSeconds rounds the retry hint up to at least one second, as sent in the Retry-After header
+func RetryAfterSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
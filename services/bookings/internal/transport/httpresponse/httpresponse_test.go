@@ -0,0 +1,41 @@
+package httpresponse_test
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/httpresponse"
+)
+
+func TestWrite(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	httpresponse.Write(recorder, http.StatusCreated, map[string]int{"imported": 3})
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"imported": 3}`, recorder.Body.String())
+}
+
+func TestWrite_Unmarshalable(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	httpresponse.Write(recorder, http.StatusOK, math.NaN())
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestWriteError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	httpresponse.WriteError(recorder, http.StatusBadRequest, "bad request")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.JSONEq(t, `{"error": "bad request"}`, recorder.Body.String())
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, 1, httpresponse.RetryAfterSeconds(0))
+	assert.Equal(t, 1, httpresponse.RetryAfterSeconds(200*time.Millisecond))
+	assert.Equal(t, 30, httpresponse.RetryAfterSeconds(30*time.Second))
+	assert.Equal(t, 31, httpresponse.RetryAfterSeconds(30*time.Second+time.Millisecond))
+}
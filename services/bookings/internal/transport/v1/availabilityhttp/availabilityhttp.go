@@ -0,0 +1,116 @@
+package availabilityhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/service/availability"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/httpresponse"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/reasons"
+	bookingsv1 "github.com/zsoltggs/tabeo-interview/services/bookings/pkg/bookings/v1"
+)
+
+const dateLayout = "2006-01-02"
+
+type AvailabilityHTTP interface {
+	CheckAvailability(response http.ResponseWriter, request *http.Request)
+}
+
+type availabilityHTTP struct {
+	availabilitySvc availability.Availability
+	// maxBatchSize bounds the number of checks of a request, zero means unbounded
+	maxBatchSize int
+}
+
+func New(availabilitySvc availability.Availability, maxBatchSize int) AvailabilityHTTP {
+	return &availabilityHTTP{
+		availabilitySvc: availabilitySvc,
+		maxBatchSize:    maxBatchSize,
+	}
+}
+
+// CheckAvailability checks a batch of launch pads and dates. Every check gets its own result,
+// a check that is invalid or fails is reported in its result without failing the others.
+func (h availabilityHTTP) CheckAvailability(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req bookingsv1.CheckAvailabilityRequest
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		httpresponse.WriteError(response, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.Checks) == 0 {
+		httpresponse.WriteError(response, http.StatusBadRequest, "checks are required")
+		return
+	}
+	if h.maxBatchSize > 0 && len(req.Checks) > h.maxBatchSize {
+		httpresponse.WriteError(response, http.StatusBadRequest, fmt.Sprintf("at most %d checks are allowed", h.maxBatchSize))
+		return
+	}
+
+	results := make([]bookingsv1.AvailabilityCheckResult, len(req.Checks))
+	var checks []models.AvailabilityCheck
+	// indexes are the positions in the request of the valid checks
+	var indexes []int
+	for i, check := range req.Checks {
+		results[i] = bookingsv1.AvailabilityCheckResult{
+			LaunchPadID:   check.LaunchPadID,
+			DestinationID: check.DestinationID,
+			Date:          check.Date,
+		}
+		if check.LaunchPadID == "" {
+			results[i].Error = "launch pad id is required"
+			continue
+		}
+		date, err := time.Parse(dateLayout, check.Date)
+		if err != nil {
+			results[i].Error = "date is required, accepted format: 2006-01-02"
+			continue
+		}
+		checks = append(checks, models.AvailabilityCheck{
+			LaunchPadID:   check.LaunchPadID,
+			DestinationID: check.DestinationID,
+			Date:          date,
+		})
+		indexes = append(indexes, i)
+	}
+
+	if len(checks) > 0 {
+		for i, result := range h.availabilitySvc.CheckBatch(request.Context(), checks) {
+			setResult(&results[indexes[i]], result)
+		}
+	}
+	httpresponse.Write(response, http.StatusOK, bookingsv1.CheckAvailabilityResponse{
+		Results: results,
+	})
+}
+
+func setResult(dst *bookingsv1.AvailabilityCheckResult, result models.AvailabilityCheckResult) {
+	var upstreamErr *models.UpstreamUnavailableError
+	switch {
+	case errors.Is(result.Err, models.ErrNotFoundLaunchpad):
+		dst.Error = "launch pad with ID not found"
+	case errors.As(result.Err, &upstreamErr):
+		dst.Error = "launch schedule is temporarily unavailable"
+		dst.RetryAfterSeconds = httpresponse.RetryAfterSeconds(upstreamErr.RetryAfter)
+	case result.Err != nil:
+		log.WithError(result.Err).WithFields(log.Fields{
+			"launch_pad_id": dst.LaunchPadID,
+			"date":          dst.Date,
+		}).Error("unable to check availability")
+		dst.Error = "internal server error"
+	default:
+		dst.Available = result.Result.Available
+		dst.Provisional = result.Result.Provisional
+		dst.Reasons = reasons.FromDomain(result.Result.Reasons)
+	}
+}
@@ -0,0 +1,122 @@
+package availabilityhttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+func TestCheckAvailability(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAvailability := mocks.NewMockAvailability(ctrl)
+	handler := New(mockAvailability, 3)
+	date := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Invalid body",
+			body:           `{"checks":`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid request body"}`,
+		},
+		{
+			name:           "No check",
+			body:           `{"checks":[]}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"checks are required"}`,
+		},
+		{
+			name: "Too many checks",
+			body: `{"checks":[{"launch_pad_id":"pad-1","date":"2049-07-07"},{"launch_pad_id":"pad-1","date":"2049-07-08"},
+				{"launch_pad_id":"pad-1","date":"2049-07-09"},{"launch_pad_id":"pad-1","date":"2049-07-10"}]}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"at most 3 checks are allowed"}`,
+		},
+		{
+			name: "Results per check",
+			body: `{"checks":[
+				{"launch_pad_id":"pad-1","destination_id":"mars","date":"2049-07-07"},
+				{"launch_pad_id":"pad-2","date":"07/07/2049"},
+				{"launch_pad_id":"pad-3","date":"2049-07-07"}]}`,
+			mockSetup: func() {
+				mockAvailability.EXPECT().CheckBatch(gomock.Any(), []models.AvailabilityCheck{
+					{LaunchPadID: "pad-1", DestinationID: "mars", Date: date},
+					{LaunchPadID: "pad-3", Date: date},
+				}).Return([]models.AvailabilityCheckResult{
+					{Result: models.AvailabilityResult{Reasons: []models.UnavailabilityReason{
+						{Type: models.ReasonBlackout, Description: "Pad refurbishment"},
+					}}},
+					{Err: &models.UpstreamUnavailableError{RetryAfter: 1500 * time.Millisecond}},
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"results":[
+				{"launch_pad_id":"pad-1","destination_id":"mars","date":"2049-07-07","available":false,
+					"reasons":[{"type":"blackout","description":"Pad refurbishment"}]},
+				{"launch_pad_id":"pad-2","date":"07/07/2049","available":false,
+					"error":"date is required, accepted format: 2006-01-02"},
+				{"launch_pad_id":"pad-3","date":"2049-07-07","available":false,
+					"error":"launch schedule is temporarily unavailable","retry_after_seconds":2}]}`,
+		},
+		{
+			name: "Failed checks",
+			body: `{"checks":[{"launch_pad_id":"unknown","date":"2049-07-07"},{"launch_pad_id":"pad-1","date":"2049-07-07"},
+				{"date":"2049-07-07"}]}`,
+			mockSetup: func() {
+				mockAvailability.EXPECT().CheckBatch(gomock.Any(), gomock.Len(2)).
+					Return([]models.AvailabilityCheckResult{
+						{Err: models.ErrNotFoundLaunchpad},
+						{Result: models.AvailabilityResult{Available: true, Provisional: true}, Err: nil},
+					})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"results":[
+				{"launch_pad_id":"unknown","date":"2049-07-07","available":false,"error":"launch pad with ID not found"},
+				{"launch_pad_id":"pad-1","date":"2049-07-07","available":true,"provisional":true},
+				{"launch_pad_id":"","date":"2049-07-07","available":false,"error":"launch pad id is required"}]}`,
+		},
+		{
+			name: "Internal error of a check",
+			body: `{"checks":[{"launch_pad_id":"pad-1","date":"2049-07-07"}]}`,
+			mockSetup: func() {
+				mockAvailability.EXPECT().CheckBatch(gomock.Any(), gomock.Any()).
+					Return([]models.AvailabilityCheckResult{{Err: errors.New("connection reset")}})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"results":[
+				{"launch_pad_id":"pad-1","date":"2049-07-07","available":false,"error":"internal server error"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			response := httptest.NewRecorder()
+			handler.CheckAvailability(response, httptest.NewRequest(http.MethodPost, "/availability/check", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			assert.JSONEq(t, tt.expectedBody, response.Body.String())
+		})
+	}
+}
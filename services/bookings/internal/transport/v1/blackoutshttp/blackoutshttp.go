@@ -0,0 +1,212 @@
+package blackoutshttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/blackouts"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/httpresponse"
+	bookingsv1 "github.com/zsoltggs/tabeo-interview/services/bookings/pkg/bookings/v1"
+)
+
+const dateLayout = "2006-01-02"
+
+type BlackoutsHTTP interface {
+	ListBlackouts(response http.ResponseWriter, request *http.Request)
+	CreateBlackout(response http.ResponseWriter, request *http.Request)
+	GetBlackout(response http.ResponseWriter, request *http.Request)
+	UpdateBlackout(response http.ResponseWriter, request *http.Request)
+	DeleteBlackout(response http.ResponseWriter, request *http.Request)
+}
+
+type blackoutsHTTP struct {
+	manager blackouts.Manager
+}
+
+func New(manager blackouts.Manager) BlackoutsHTTP {
+	return &blackoutsHTTP{
+		manager: manager,
+	}
+}
+
+// ListBlackouts returns the blackouts filtered by the launch_pad_id, from and to query params, all of them optional.
+// The blackouts of every launch pad are returned with the ones of launch_pad_id, to is included.
+func (h blackoutsHTTP) ListBlackouts(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	params := request.URL.Query()
+	filters := models.BlackoutFilters{}
+	if launchPadID := params.Get("launch_pad_id"); launchPadID != "" {
+		filters.LaunchPadID = &launchPadID
+	}
+	if value := params.Get("from"); value != "" {
+		from, err := time.Parse(dateLayout, value)
+		if err != nil {
+			httpresponse.WriteError(response, http.StatusBadRequest, "from has to be a date (YYYY-MM-DD)")
+			return
+		}
+		filters.From = &from
+	}
+	if value := params.Get("to"); value != "" {
+		to, err := time.Parse(dateLayout, value)
+		if err != nil {
+			httpresponse.WriteError(response, http.StatusBadRequest, "to has to be a date (YYYY-MM-DD)")
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+		filters.To = &to
+	}
+
+	result, err := h.manager.List(request.Context(), filters)
+	if err != nil {
+		log.WithError(err).Error("unable to list blackouts")
+		httpresponse.WriteError(response, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	resp := bookingsv1.ListBlackoutsResponse{
+		Blackouts: []bookingsv1.Blackout{},
+	}
+	for _, blackout := range result {
+		resp.Blackouts = append(resp.Blackouts, fromDomainBlackout(blackout))
+	}
+	httpresponse.Write(response, http.StatusOK, resp)
+}
+
+func (h blackoutsHTTP) CreateBlackout(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req bookingsv1.BlackoutRequest
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		httpresponse.WriteError(response, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	blackout, err := h.manager.Create(request.Context(), toDomainBlackout(uuid.Nil, req))
+	switch {
+	case errors.Is(err, blackouts.ErrInvalidBlackout):
+		httpresponse.WriteError(response, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		log.WithError(err).Error("unable to create blackout")
+		httpresponse.WriteError(response, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	httpresponse.Write(response, http.StatusCreated, fromDomainBlackout(*blackout))
+}
+
+func (h blackoutsHTTP) GetBlackout(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	blackoutID, err := uuid.Parse(mux.Vars(request)["blackout-id"])
+	if err != nil {
+		httpresponse.WriteError(response, http.StatusBadRequest, "invalid blackout id")
+		return
+	}
+
+	blackout, err := h.manager.Get(request.Context(), blackoutID)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		httpresponse.WriteError(response, http.StatusNotFound, "blackout not found")
+		return
+	case err != nil:
+		log.WithError(err).Error("unable to get blackout")
+		httpresponse.WriteError(response, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	httpresponse.Write(response, http.StatusOK, fromDomainBlackout(*blackout))
+}
+
+// UpdateBlackout replaces the launch pad, the period and the reason of a blackout
+func (h blackoutsHTTP) UpdateBlackout(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPut {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	blackoutID, err := uuid.Parse(mux.Vars(request)["blackout-id"])
+	if err != nil {
+		httpresponse.WriteError(response, http.StatusBadRequest, "invalid blackout id")
+		return
+	}
+	var req bookingsv1.BlackoutRequest
+	err = json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		httpresponse.WriteError(response, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	blackout, err := h.manager.Update(request.Context(), toDomainBlackout(blackoutID, req))
+	switch {
+	case errors.Is(err, blackouts.ErrInvalidBlackout):
+		httpresponse.WriteError(response, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, database.ErrNotFound):
+		httpresponse.WriteError(response, http.StatusNotFound, "blackout not found")
+		return
+	case err != nil:
+		log.WithError(err).Error("unable to update blackout")
+		httpresponse.WriteError(response, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	httpresponse.Write(response, http.StatusOK, fromDomainBlackout(*blackout))
+}
+
+func (h blackoutsHTTP) DeleteBlackout(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodDelete {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	blackoutID, err := uuid.Parse(mux.Vars(request)["blackout-id"])
+	if err != nil {
+		httpresponse.WriteError(response, http.StatusBadRequest, "invalid blackout id")
+		return
+	}
+
+	err = h.manager.Delete(request.Context(), blackoutID)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		httpresponse.WriteError(response, http.StatusNotFound, "blackout not found")
+		return
+	case err != nil:
+		log.WithError(err).Error("unable to delete blackout")
+		httpresponse.WriteError(response, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	response.WriteHeader(http.StatusNoContent)
+}
+
+func toDomainBlackout(id uuid.UUID, req bookingsv1.BlackoutRequest) models.Blackout {
+	return models.Blackout{
+		ID:          id,
+		LaunchPadID: req.LaunchPadID,
+		StartsAt:    req.StartsAt.UTC(),
+		EndsAt:      req.EndsAt.UTC(),
+		Reason:      req.Reason,
+	}
+}
+
+func fromDomainBlackout(blackout models.Blackout) bookingsv1.Blackout {
+	return bookingsv1.Blackout{
+		ID:          blackout.ID,
+		LaunchPadID: blackout.LaunchPadID,
+		StartsAt:    blackout.StartsAt.UTC(),
+		EndsAt:      blackout.EndsAt.UTC(),
+		Reason:      blackout.Reason,
+		CreatedAt:   blackout.CreatedAt.UTC(),
+		UpdatedAt:   blackout.UpdatedAt.UTC(),
+	}
+}
@@ -0,0 +1,281 @@
+package blackoutshttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/blackouts"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+var (
+	blackoutID = uuid.MustParse("0aadd991-953d-48d3-a4a8-8e1182a2c723")
+	day        = time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+	blackout   = models.Blackout{
+		ID:          blackoutID,
+		LaunchPadID: "pad-1",
+		StartsAt:    day,
+		EndsAt:      day.AddDate(0, 0, 2),
+		Reason:      "Pad refurbishment",
+		CreatedAt:   day.AddDate(0, 0, -7),
+		UpdatedAt:   day.AddDate(0, 0, -7),
+	}
+	blackoutJSON = `{"id":"0aadd991-953d-48d3-a4a8-8e1182a2c723","launch_pad_id":"pad-1",
+		"starts_at":"2049-07-07T00:00:00Z","ends_at":"2049-07-09T00:00:00Z","reason":"Pad refurbishment",
+		"created_at":"2049-06-30T00:00:00Z","updated_at":"2049-06-30T00:00:00Z"}`
+)
+
+func TestListBlackouts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockManager := mocks.NewMockManager(ctrl)
+	handler := New(mockManager)
+
+	launchPadID := "pad-1"
+	from := day
+	to := day.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Invalid from",
+			url:            "/admin/blackouts?from=07/07/2049",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"from has to be a date (YYYY-MM-DD)"}`,
+		},
+		{
+			name: "Filtered",
+			url:  "/admin/blackouts?launch_pad_id=pad-1&from=2049-07-07&to=2049-07-07",
+			mockSetup: func() {
+				mockManager.EXPECT().
+					List(gomock.Any(), models.BlackoutFilters{LaunchPadID: &launchPadID, From: &from, To: &to}).
+					Return([]models.Blackout{blackout}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"blackouts":[` + blackoutJSON + `]}`,
+		},
+		{
+			name: "Empty",
+			url:  "/admin/blackouts",
+			mockSetup: func() {
+				mockManager.EXPECT().List(gomock.Any(), models.BlackoutFilters{}).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"blackouts":[]}`,
+		},
+		{
+			name: "Internal Server Error",
+			url:  "/admin/blackouts",
+			mockSetup: func() {
+				mockManager.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, errors.New("internal error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			response := httptest.NewRecorder()
+			handler.ListBlackouts(response, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			assert.JSONEq(t, tt.expectedBody, response.Body.String())
+		})
+	}
+}
+
+func TestCreateBlackout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockManager := mocks.NewMockManager(ctrl)
+	handler := New(mockManager)
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Invalid body",
+			body:           `{"starts_at":"tomorrow"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid request body"}`,
+		},
+		{
+			name: "Invalid blackout",
+			body: `{"starts_at":"2049-07-09T00:00:00Z","ends_at":"2049-07-07T00:00:00Z"}`,
+			mockSetup: func() {
+				mockManager.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Return(nil, fmt.Errorf("%w: ends_at has to be after starts_at", blackouts.ErrInvalidBlackout))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid blackout: ends_at has to be after starts_at"}`,
+		},
+		{
+			name: "Success",
+			body: `{"launch_pad_id":"pad-1","starts_at":"2049-07-07T02:00:00+02:00","ends_at":"2049-07-09T00:00:00Z","reason":"Pad refurbishment"}`,
+			mockSetup: func() {
+				mockManager.EXPECT().Create(gomock.Any(), models.Blackout{
+					LaunchPadID: "pad-1",
+					StartsAt:    day,
+					EndsAt:      day.AddDate(0, 0, 2),
+					Reason:      "Pad refurbishment",
+				}).Return(&blackout, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   blackoutJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			response := httptest.NewRecorder()
+			handler.CreateBlackout(response, httptest.NewRequest(http.MethodPost, "/admin/blackouts", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			assert.JSONEq(t, tt.expectedBody, response.Body.String())
+		})
+	}
+}
+
+func TestBlackoutByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockManager := mocks.NewMockManager(ctrl)
+	handler := New(mockManager)
+
+	tests := []struct {
+		name           string
+		method         string
+		blackoutID     string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Invalid UUID",
+			method:         http.MethodGet,
+			blackoutID:     "invalid-uuid",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid blackout id"}`,
+		},
+		{
+			name:       "Get",
+			method:     http.MethodGet,
+			blackoutID: blackoutID.String(),
+			mockSetup: func() {
+				mockManager.EXPECT().Get(gomock.Any(), blackoutID).Return(&blackout, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   blackoutJSON,
+		},
+		{
+			name:       "Get not found",
+			method:     http.MethodGet,
+			blackoutID: blackoutID.String(),
+			mockSetup: func() {
+				mockManager.EXPECT().Get(gomock.Any(), blackoutID).Return(nil, database.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"blackout not found"}`,
+		},
+		{
+			name:       "Update",
+			method:     http.MethodPut,
+			blackoutID: blackoutID.String(),
+			body:       `{"launch_pad_id":"pad-1","starts_at":"2049-07-07T00:00:00Z","ends_at":"2049-07-09T00:00:00Z","reason":"Pad refurbishment"}`,
+			mockSetup: func() {
+				mockManager.EXPECT().Update(gomock.Any(), models.Blackout{
+					ID:          blackoutID,
+					LaunchPadID: "pad-1",
+					StartsAt:    day,
+					EndsAt:      day.AddDate(0, 0, 2),
+					Reason:      "Pad refurbishment",
+				}).Return(&blackout, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   blackoutJSON,
+		},
+		{
+			name:       "Update not found",
+			method:     http.MethodPut,
+			blackoutID: blackoutID.String(),
+			body:       `{"starts_at":"2049-07-07T00:00:00Z","ends_at":"2049-07-09T00:00:00Z"}`,
+			mockSetup: func() {
+				mockManager.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil, database.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"blackout not found"}`,
+		},
+		{
+			name:       "Delete",
+			method:     http.MethodDelete,
+			blackoutID: blackoutID.String(),
+			mockSetup: func() {
+				mockManager.EXPECT().Delete(gomock.Any(), blackoutID).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Delete internal server error",
+			method:     http.MethodDelete,
+			blackoutID: blackoutID.String(),
+			mockSetup: func() {
+				mockManager.EXPECT().Delete(gomock.Any(), blackoutID).Return(errors.New("internal error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(tt.method, "/admin/blackouts/"+tt.blackoutID, strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/admin/blackouts/{blackout-id}", handler.GetBlackout).Methods(http.MethodGet)
+			router.HandleFunc("/admin/blackouts/{blackout-id}", handler.UpdateBlackout).Methods(http.MethodPut)
+			router.HandleFunc("/admin/blackouts/{blackout-id}", handler.DeleteBlackout).Methods(http.MethodDelete)
+			router.ServeHTTP(response, req)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, response.Body.String())
+			}
+		})
+	}
+}
@@ -5,14 +5,15 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/database"
 
-	"github.com/gorilla/mux"
-
 	log "github.com/sirupsen/logrus"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/service"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/httpresponse"
 
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
 	bookingsv1 "github.com/zsoltggs/tabeo-interview/services/bookings/pkg/bookings/v1"
@@ -21,7 +22,10 @@ import (
 type BookingsHTTP interface {
 	CreateBooking(response http.ResponseWriter, request *http.Request)
 	ListBookings(response http.ResponseWriter, request *http.Request)
+	GetBooking(response http.ResponseWriter, request *http.Request)
+	UpdateBooking(response http.ResponseWriter, request *http.Request)
 	DeleteBooking(response http.ResponseWriter, request *http.Request)
+	GetBookingHistory(response http.ResponseWriter, request *http.Request)
 }
 
 type bookingsHTTP struct {
@@ -62,15 +66,27 @@ func (h bookingsHTTP) CreateBooking(response http.ResponseWriter, request *http.
 	}
 	ctx := request.Context()
 	res, err := h.service.CreateBooking(ctx, *booking)
+	var upstreamErr *models.UpstreamUnavailableError
 	switch {
 	case errors.Is(err, models.ErrNotAvailable):
-		response.WriteHeader(http.StatusConflict)
-		writeErrorResponse(response, "date is unavailable")
+		writeNotAvailableResponse(response, err)
 		return
 	case errors.Is(err, models.ErrNotFoundLaunchpad):
 		response.WriteHeader(http.StatusNotFound)
 		writeErrorResponse(response, "launch pad with ID not found")
 		return
+	case errors.As(err, &upstreamErr):
+		log.WithError(err).Warn("launch schedule is unavailable")
+		response.Header().Set("Retry-After", strconv.Itoa(httpresponse.RetryAfterSeconds(upstreamErr.RetryAfter)))
+		response.WriteHeader(http.StatusServiceUnavailable)
+		writeErrorResponse(response, "launch schedule is temporarily unavailable")
+		return
+	case errors.Is(err, models.ErrSlotBusy):
+		log.WithError(err).Warn("launch slot is busy")
+		response.Header().Set("Retry-After", "1")
+		response.WriteHeader(http.StatusServiceUnavailable)
+		writeErrorResponse(response, "launch pad is busy, retry shortly")
+		return
 	case err != nil:
 		response.WriteHeader(http.StatusInternalServerError)
 		log.WithError(err).Error("unable to create booking")
@@ -87,6 +103,7 @@ func (h bookingsHTTP) CreateBooking(response http.ResponseWriter, request *http.
 		return
 	}
 	response.Header().Set("Content-Type", "application/json")
+	response.Header().Set("ETag", etag(res.Version))
 	response.WriteHeader(http.StatusCreated)
 	_, err = response.Write(respJSON)
 	if err != nil {
@@ -152,30 +169,140 @@ func (h bookingsHTTP) ListBookings(response http.ResponseWriter, request *http.R
 	}
 }
 
-func (h bookingsHTTP) DeleteBooking(response http.ResponseWriter, request *http.Request) {
-	if request.Method != http.MethodDelete {
+func (h bookingsHTTP) GetBooking(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
 		response.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
+	bookingID, err := bookingIDFromPath(request)
+	if err != nil {
+		response.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	booking, err := h.service.GetBooking(request.Context(), bookingID)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		response.WriteHeader(http.StatusNotFound)
+		return
+	case err != nil:
+		log.WithError(err).Error("unable to get booking")
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeBookingResponse(response, *booking)
+}
 
-	vars := mux.Vars(request)
-	bookingIDStr := vars["booking-id"]
-	if bookingIDStr == "" {
+// versionFromIfMatch returns the version of the booking the change is made at. The If-Match header is required so
+// concurrent changes are not overwritten, it writes the error response and returns false if it is missing, invalid or
+// does not match the current version. A single ETag is checked by the change itself, "*" and lists are matched against
+// the current version, and the change is still only made at that version.
+func (h bookingsHTTP) versionFromIfMatch(response http.ResponseWriter, request *http.Request, bookingID uuid.UUID) (int, bool) {
+	headers := request.Header.Values("If-Match")
+	if strings.TrimSpace(strings.Join(headers, "")) == "" {
+		response.WriteHeader(http.StatusPreconditionRequired)
+		writeErrorResponse(response, "If-Match header with the ETag of the booking is required")
+		return 0, false
+	}
+	precondition, ok := parseIfMatch(headers)
+	if !ok {
 		response.WriteHeader(http.StatusBadRequest)
+		writeErrorResponse(response, "invalid If-Match header, expected the ETag of the booking")
+		return 0, false
+	}
+	if !precondition.any && len(precondition.versions) == 1 {
+		return precondition.versions[0], true
+	}
+
+	booking, err := h.service.GetBooking(request.Context(), bookingID)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		response.WriteHeader(http.StatusNotFound)
+		return 0, false
+	case err != nil:
+		log.WithError(err).Error("unable to get booking")
+		response.WriteHeader(http.StatusInternalServerError)
+		return 0, false
+	}
+	if !precondition.matches(booking.Version) {
+		writePreconditionFailed(response)
+		return 0, false
+	}
+	return booking.Version, true
+}
+
+func (h bookingsHTTP) UpdateBooking(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPatch {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	bookingID, err := bookingIDFromPath(request)
+	if err != nil {
+		response.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	version, ok := h.versionFromIfMatch(response, request, bookingID)
+	if !ok {
+		return
+	}
+
+	var updateReq bookingsv1.UpdateBookingRequest
+	decoder := json.NewDecoder(request.Body)
+	// The flight cannot be changed, a request trying to would otherwise succeed without changing it
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&updateReq)
+	if err != nil {
+		response.WriteHeader(http.StatusBadRequest)
+		writeErrorResponse(response, "bad request, only first_name, last_name, gender and birthday can be updated")
+		return
+	}
+	update, err := toDomainUpdate(updateReq)
+	if err != nil {
+		response.WriteHeader(http.StatusBadRequest)
+		writeErrorResponse(response, err.Error())
+		return
+	}
+
+	booking, err := h.service.UpdateBooking(request.Context(), bookingID, version, update)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		response.WriteHeader(http.StatusNotFound)
+		return
+	case errors.Is(err, database.ErrVersionMismatch):
+		writePreconditionFailed(response)
+		return
+	case err != nil:
+		log.WithError(err).Error("unable to update booking")
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeBookingResponse(response, *booking)
+}
+
+func (h bookingsHTTP) DeleteBooking(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodDelete {
+		response.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
-	bookingID, err := uuid.Parse(bookingIDStr)
+	bookingID, err := bookingIDFromPath(request)
 	if err != nil {
 		response.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	version, ok := h.versionFromIfMatch(response, request, bookingID)
+	if !ok {
+		return
+	}
 
 	ctx := request.Context()
-	err = h.service.DeleteBooking(ctx, bookingID)
+	err = h.service.DeleteBooking(ctx, bookingID, version)
 	switch {
 	case errors.Is(err, database.ErrNotFound):
 		response.WriteHeader(http.StatusNotFound)
 		return
+	case errors.Is(err, database.ErrVersionMismatch):
+		writePreconditionFailed(response)
+		return
 	case err != nil:
 		log.WithError(err).Error("unable to delete booking")
 		response.WriteHeader(http.StatusInternalServerError)
@@ -184,3 +311,44 @@ func (h bookingsHTTP) DeleteBooking(response http.ResponseWriter, request *http.
 
 	response.WriteHeader(http.StatusNoContent)
 }
+
+func (h bookingsHTTP) GetBookingHistory(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	bookingID, err := bookingIDFromPath(request)
+	if err != nil {
+		response.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.service.GetBookingHistory(request.Context(), bookingID)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		response.WriteHeader(http.StatusNotFound)
+		return
+	case err != nil:
+		log.WithError(err).Error("unable to get booking history")
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	resp := bookingsv1.BookingHistoryResponse{BookingID: bookingID}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, fromDomainAuditEntry(entry))
+	}
+	respJSON, err := json.Marshal(resp)
+	if err != nil {
+		log.WithError(err).Error("unable to marshal booking history response")
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(http.StatusOK)
+	_, err = response.Write(respJSON)
+	if err != nil {
+		log.WithError(err).Error("unable to write booking history response")
+	}
+}
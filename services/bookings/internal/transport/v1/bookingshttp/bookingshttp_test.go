@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -31,12 +32,14 @@ func TestCreateBookingHandler(t *testing.T) {
 	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
 
 	tests := []struct {
-		name           string
-		method         string
-		body           interface{}
-		mockSetup      func()
-		expectedStatus int
-		expectedBody   string
+		name               string
+		method             string
+		body               interface{}
+		mockSetup          func()
+		expectedStatus     int
+		expectedRetryAfter string
+		expectedETag       string
+		expectedBody       string
 	}{
 		{
 			name:   "Invalid method",
@@ -108,6 +111,87 @@ func TestCreateBookingHandler(t *testing.T) {
 			expectedStatus: http.StatusConflict,
 			expectedBody:   `{"error":"date is unavailable"}`,
 		},
+		{
+			name:   "Date unavailable with suggestions",
+			method: http.MethodPost,
+			body: bookingsv1.CreateBookingRequest{
+				FirstName:     "Jane",
+				LastName:      "Doe",
+				Gender:        "female",
+				Birthday:      "1990-01-01",
+				LaunchPadID:   "valid-pad",
+				DestinationID: "dest-456",
+				LaunchDate:    "2024-12-31",
+			},
+			mockSetup: func() {
+				mockService.EXPECT().
+					CreateBooking(gomock.Any(), gomock.Any()).
+					Return(nil, &models.NotAvailableError{
+						Reasons: []models.UnavailabilityReason{{
+							Type:        models.ReasonLaunch,
+							Description: "Starlink",
+							Launch: &models.ConflictingLaunch{
+								ID:       "launch-1",
+								Name:     "Starlink",
+								DateUTC:  time.Date(2024, 12, 31, 13, 0, 0, 0, time.UTC),
+								Provider: "spacex",
+							},
+						}},
+						Suggestions: &models.Suggestions{
+							Dates:        []time.Time{time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+							LaunchPadIDs: []string{"other-pad"},
+						}})
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody: `{"error":"date is unavailable",
+				"reasons":[{"type":"launch","description":"Starlink",
+					"launch":{"id":"launch-1","name":"Starlink","date_utc":"2024-12-31T13:00:00Z","provider":"spacex"}}],
+				"suggestions":{"dates":["2025-01-01"],"launch_pad_ids":["other-pad"]}}`,
+		},
+		{
+			name:   "Launch schedule unavailable",
+			method: http.MethodPost,
+			body: bookingsv1.CreateBookingRequest{
+				FirstName:     "Jane",
+				LastName:      "Doe",
+				Gender:        "female",
+				Birthday:      "1990-01-01",
+				LaunchPadID:   "valid-pad",
+				DestinationID: "dest-456",
+				LaunchDate:    "2024-12-31",
+			},
+			mockSetup: func() {
+				mockService.EXPECT().
+					CreateBooking(gomock.Any(), gomock.Any()).
+					Return(nil, fmt.Errorf("cannot determine availability: %w", &models.UpstreamUnavailableError{
+						RetryAfter: 1500 * time.Millisecond,
+					}))
+			},
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedRetryAfter: "2",
+			expectedBody:       `{"error":"launch schedule is temporarily unavailable"}`,
+		},
+		{
+			name:   "Launch slot busy",
+			method: http.MethodPost,
+			body: bookingsv1.CreateBookingRequest{
+				FirstName:     "Jane",
+				LastName:      "Doe",
+				Gender:        "female",
+				Birthday:      "1990-01-01",
+				LaunchPadID:   "valid-pad",
+				DestinationID: "dest-456",
+				LaunchDate:    "2024-12-31",
+			},
+			mockSetup: func() {
+				mockService.EXPECT().
+					CreateBooking(gomock.Any(), gomock.Any()).
+					Return(nil, fmt.Errorf("%w: %w", models.ErrSlotBusy, errors.New("lock timeout")))
+			},
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedRetryAfter: "1",
+			expectedBody:       `{"error":"launch pad is busy, retry shortly"}`,
+		},
 		{
 			name:   "Successful booking",
 			method: http.MethodPost,
@@ -123,6 +207,7 @@ func TestCreateBookingHandler(t *testing.T) {
 			mockSetup: func() {
 				booking := &models.Booking{
 					ID:            fixedUUID,
+					Status:        models.BookingStatusConfirmed,
 					FirstName:     "Jane",
 					LastName:      "Doe",
 					Gender:        "female",
@@ -132,15 +217,18 @@ func TestCreateBookingHandler(t *testing.T) {
 					LaunchDate:    timeDate(2024, 12, 31),
 					CreatedAt:     ts,
 					UpdatedAt:     ts,
+					Version:       1,
 				}
 				mockService.EXPECT().
 					CreateBooking(gomock.Any(), gomock.Any()).
 					Return(booking, nil)
 			},
 			expectedStatus: http.StatusCreated,
+			expectedETag:   `"1"`,
 			expectedBody: `{"booking":
 	{
 		"id":"0aadd991-953d-48d3-a4a8-8e1182a2c723",
+		"status":"confirmed",
 		"first_name":"Jane",
 		"last_name":"Doe",
 		"gender":"female",
@@ -149,7 +237,8 @@ func TestCreateBookingHandler(t *testing.T) {
 		"destination_id":"dest-456",
 		"launch_date":"2024-12-31",
 		"created_at":"2024-01-02T03:04:05Z", 
-		"updated_at":"2024-01-02T03:04:05Z"
+		"updated_at":"2024-01-02T03:04:05Z",
+		"version":1
 	}
 }`,
 		},
@@ -177,6 +266,8 @@ func TestCreateBookingHandler(t *testing.T) {
 			handler.CreateBooking(rec, req)
 
 			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedRetryAfter, rec.Header().Get("Retry-After"))
+			assert.Equal(t, tt.expectedETag, rec.Header().Get("ETag"))
 			if tt.expectedBody != "" {
 				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
 			}
@@ -236,10 +327,11 @@ func TestListBookings(t *testing.T) {
 		{
 			name:        "Successful Response",
 			method:      http.MethodGet,
-			queryParams: "?launch_date=2024-01-02&launch_pad_id=valid-pad&destination_id=dest-456",
+			queryParams: "?launch_date=2024-01-02&launch_pad_id=valid-pad&destination_id=dest-456&status=provisional&first_name=Jane&last_name=Doe",
 			mockService: func() {
 				booking := models.Booking{
 					ID:            fixedUUID,
+					Status:        models.BookingStatusProvisional,
 					FirstName:     "Jane",
 					LastName:      "Doe",
 					Gender:        "female",
@@ -249,12 +341,16 @@ func TestListBookings(t *testing.T) {
 					LaunchDate:    timeDate(2024, 12, 31),
 					CreatedAt:     ts,
 					UpdatedAt:     ts,
+					Version:       2,
 				}
 				mockService.EXPECT().
 					ListBookings(gomock.Any(), models.Filters{
 						LaunchDate:    &launchDate,
 						LaunchPadID:   toPtr("valid-pad"),
 						DestinationID: toPtr("dest-456"),
+						Status:        toPtr(models.BookingStatusProvisional),
+						FirstName:     toPtr("Jane"),
+						LastName:      toPtr("Doe"),
 					}, models.Pagination{Offset: 0, Limit: 10}).
 					Return([]models.Booking{
 						booking,
@@ -264,6 +360,7 @@ func TestListBookings(t *testing.T) {
 			expectedBody: `{"bookings":[
 	{
 		"id":"0aadd991-953d-48d3-a4a8-8e1182a2c723",
+		"status":"provisional",
 		"first_name":"Jane",
 		"last_name":"Doe",
 		"gender":"female",
@@ -272,7 +369,8 @@ func TestListBookings(t *testing.T) {
 		"destination_id":"dest-456",
 		"launch_date":"2024-12-31",
 		"created_at":"2024-01-02T03:04:05Z", 
-		"updated_at":"2024-01-02T03:04:05Z"
+		"updated_at":"2024-01-02T03:04:05Z",
+		"version":2
 	}
 ]}`,
 		},
@@ -301,6 +399,300 @@ func TestListBookings(t *testing.T) {
 	}
 }
 
+func TestGetBooking(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockService(ctrl)
+	handler := bookingsHTTP{service: mockService}
+	fixedUUID := uuid.MustParse("0aadd991-953d-48d3-a4a8-8e1182a2c723")
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		method         string
+		bookingID      string
+		mockSetup      func()
+		expectedStatus int
+		expectedETag   string
+		expectedBody   string
+	}{
+		{
+			name:           "Method Not Allowed",
+			method:         http.MethodPost,
+			bookingID:      fixedUUID.String(),
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "Bad Request - Invalid UUID",
+			method:         http.MethodGet,
+			bookingID:      "invalid-uuid",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "Booking Not Found",
+			method:    http.MethodGet,
+			bookingID: fixedUUID.String(),
+			mockSetup: func() {
+				mockService.EXPECT().GetBooking(gomock.Any(), fixedUUID).Return(nil, database.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:      "Success",
+			method:    http.MethodGet,
+			bookingID: fixedUUID.String(),
+			mockSetup: func() {
+				mockService.EXPECT().GetBooking(gomock.Any(), fixedUUID).Return(&models.Booking{
+					ID:            fixedUUID,
+					Status:        models.BookingStatusConfirmed,
+					FirstName:     "Jane",
+					LastName:      "Doe",
+					Gender:        "female",
+					Birthday:      timeDate(1990, 1, 1),
+					LaunchPadID:   "valid-pad",
+					DestinationID: "dest-456",
+					LaunchDate:    timeDate(2024, 12, 31),
+					CreatedAt:     ts,
+					UpdatedAt:     ts,
+					Version:       3,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"3"`,
+			expectedBody: `{"booking":
+	{
+		"id":"0aadd991-953d-48d3-a4a8-8e1182a2c723",
+		"status":"confirmed",
+		"first_name":"Jane",
+		"last_name":"Doe",
+		"gender":"female",
+		"birthday":"1990-01-01",
+		"launch_pad_id":"valid-pad",
+		"destination_id":"dest-456",
+		"launch_date":"2024-12-31",
+		"created_at":"2024-01-02T03:04:05Z",
+		"updated_at":"2024-01-02T03:04:05Z",
+		"version":3
+	}
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			req := httptest.NewRequest(tt.method, "/bookings/"+tt.bookingID, nil)
+			response := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/bookings/{booking-id}", handler.GetBooking)
+			router.ServeHTTP(response, req)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			assert.Equal(t, tt.expectedETag, response.Header().Get("ETag"))
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, response.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateBooking(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockService(ctrl)
+	handler := bookingsHTTP{service: mockService}
+	fixedUUID := uuid.MustParse("0aadd991-953d-48d3-a4a8-8e1182a2c723")
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	birthday := timeDate(1991, 2, 3)
+
+	tests := []struct {
+		name           string
+		method         string
+		ifMatch        string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedETag   string
+		expectedBody   string
+	}{
+		{
+			name:           "Method Not Allowed",
+			method:         http.MethodPut,
+			ifMatch:        `"3"`,
+			body:           `{"last_name":"Smith"}`,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "Missing If-Match",
+			method:         http.MethodPatch,
+			body:           `{"last_name":"Smith"}`,
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedBody:   `{"error":"If-Match header with the ETag of the booking is required"}`,
+		},
+		{
+			name:           "Invalid If-Match",
+			method:         http.MethodPatch,
+			ifMatch:        `3`,
+			body:           `{"last_name":"Smith"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid If-Match header, expected the ETag of the booking"}`,
+		},
+		{
+			name:    "Weak ETag in If-Match never matches",
+			method:  http.MethodPatch,
+			ifMatch: `W/"3"`,
+			body:    `{"last_name":"Smith"}`,
+			mockSetup: func() {
+				mockService.EXPECT().GetBooking(gomock.Any(), fixedUUID).Return(&models.Booking{ID: fixedUUID, Version: 3}, nil)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   `{"error":"booking was changed since it was read, get it again for its current ETag"}`,
+		},
+		{
+			name:    "No ETag of the list matches",
+			method:  http.MethodPatch,
+			ifMatch: `"1", "2"`,
+			body:    `{"last_name":"Smith"}`,
+			mockSetup: func() {
+				mockService.EXPECT().GetBooking(gomock.Any(), fixedUUID).Return(&models.Booking{ID: fixedUUID, Version: 3}, nil)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   `{"error":"booking was changed since it was read, get it again for its current ETag"}`,
+		},
+		{
+			name:    "Any version of a booking that does not exist",
+			method:  http.MethodPatch,
+			ifMatch: `*`,
+			body:    `{"last_name":"Smith"}`,
+			mockSetup: func() {
+				mockService.EXPECT().GetBooking(gomock.Any(), fixedUUID).
+					Return(nil, fmt.Errorf("unable to get booking: %w", database.ErrNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Flight cannot be changed",
+			method:         http.MethodPatch,
+			ifMatch:        `"3"`,
+			body:           `{"launch_date":"2025-01-01"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"bad request, only first_name, last_name, gender and birthday can be updated"}`,
+		},
+		{
+			name:           "Nothing to update",
+			method:         http.MethodPatch,
+			ifMatch:        `"3"`,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"nothing to update"}`,
+		},
+		{
+			name:           "Invalid gender",
+			method:         http.MethodPatch,
+			ifMatch:        `"3"`,
+			body:           `{"gender":"unknown"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid gender value, accepted values for gender: male, female, other"}`,
+		},
+		{
+			name:    "Booking changed since it was read",
+			method:  http.MethodPatch,
+			ifMatch: `"3"`,
+			body:    `{"last_name":"Smith"}`,
+			mockSetup: func() {
+				mockService.EXPECT().UpdateBooking(gomock.Any(), fixedUUID, 3, gomock.Any()).
+					Return(nil, fmt.Errorf("unable to update booking: %w", database.ErrVersionMismatch))
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   `{"error":"booking was changed since it was read, get it again for its current ETag"}`,
+		},
+		{
+			name:    "Booking Not Found",
+			method:  http.MethodPatch,
+			ifMatch: `"3"`,
+			body:    `{"last_name":"Smith"}`,
+			mockSetup: func() {
+				mockService.EXPECT().UpdateBooking(gomock.Any(), fixedUUID, 3, gomock.Any()).
+					Return(nil, fmt.Errorf("unable to get booking: %w", database.ErrNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:    "Success",
+			method:  http.MethodPatch,
+			ifMatch: `"3"`,
+			body:    `{"last_name":"Smith","birthday":"1991-02-03"}`,
+			mockSetup: func() {
+				mockService.EXPECT().UpdateBooking(gomock.Any(), fixedUUID, 3, models.UpdateBooking{
+					LastName: toPtr("Smith"),
+					Birthday: &birthday,
+				}).Return(&models.Booking{
+					ID:            fixedUUID,
+					Status:        models.BookingStatusConfirmed,
+					FirstName:     "Jane",
+					LastName:      "Smith",
+					Gender:        "female",
+					Birthday:      birthday,
+					LaunchPadID:   "valid-pad",
+					DestinationID: "dest-456",
+					LaunchDate:    timeDate(2024, 12, 31),
+					CreatedAt:     ts,
+					UpdatedAt:     ts,
+					Version:       4,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+			expectedBody: `{"booking":
+	{
+		"id":"0aadd991-953d-48d3-a4a8-8e1182a2c723",
+		"status":"confirmed",
+		"first_name":"Jane",
+		"last_name":"Smith",
+		"gender":"female",
+		"birthday":"1991-02-03",
+		"launch_pad_id":"valid-pad",
+		"destination_id":"dest-456",
+		"launch_date":"2024-12-31",
+		"created_at":"2024-01-02T03:04:05Z",
+		"updated_at":"2024-01-02T03:04:05Z",
+		"version":4
+	}
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			req := httptest.NewRequest(tt.method, "/bookings/"+fixedUUID.String(), bytes.NewReader([]byte(tt.body)))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			response := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/bookings/{booking-id}", handler.UpdateBooking)
+			router.ServeHTTP(response, req)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			assert.Equal(t, tt.expectedETag, response.Header().Get("ETag"))
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, response.Body.String())
+			}
+		})
+	}
+}
+
 func TestDeleteBooking(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -313,6 +705,7 @@ func TestDeleteBooking(t *testing.T) {
 		name           string
 		method         string
 		bookingID      string
+		ifMatch        string
 		expectedStatus int
 		mockSetup      func()
 	}{
@@ -320,31 +713,95 @@ func TestDeleteBooking(t *testing.T) {
 			name:           "Method Not Allowed",
 			method:         http.MethodGet,
 			bookingID:      "123e4567-e89b-12d3-a456-426614174000",
+			ifMatch:        `"1"`,
 			expectedStatus: http.StatusMethodNotAllowed,
 		},
 		{
 			name:           "Bad Request - Invalid UUID",
 			method:         http.MethodDelete,
 			bookingID:      "invalid-uuid",
+			ifMatch:        `"1"`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Missing If-Match",
+			method:         http.MethodDelete,
+			bookingID:      fixedUUID.String(),
+			expectedStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:           "Invalid If-Match",
+			method:         http.MethodDelete,
+			bookingID:      fixedUUID.String(),
+			ifMatch:        "1",
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
 			name:           "Booking Not Found",
 			method:         http.MethodDelete,
 			bookingID:      fixedUUID.String(),
+			ifMatch:        `"1"`,
 			expectedStatus: http.StatusNotFound,
 			mockSetup: func() {
-				mockService.EXPECT().DeleteBooking(gomock.Any(), fixedUUID).
+				mockService.EXPECT().DeleteBooking(gomock.Any(), fixedUUID, 1).
 					Return(database.ErrNotFound)
 			},
 		},
+		{
+			name:           "Booking changed since it was read",
+			method:         http.MethodDelete,
+			bookingID:      fixedUUID.String(),
+			ifMatch:        `"1"`,
+			expectedStatus: http.StatusPreconditionFailed,
+			mockSetup: func() {
+				mockService.EXPECT().DeleteBooking(gomock.Any(), fixedUUID, 1).
+					Return(fmt.Errorf("unable to delete booking: %w", database.ErrVersionMismatch))
+			},
+		},
+		{
+			name:           "Any version",
+			method:         http.MethodDelete,
+			bookingID:      fixedUUID.String(),
+			ifMatch:        `*`,
+			expectedStatus: http.StatusNoContent,
+			mockSetup: func() {
+				mockService.EXPECT().GetBooking(gomock.Any(), fixedUUID).Return(&models.Booking{ID: fixedUUID, Version: 5}, nil)
+				mockService.EXPECT().DeleteBooking(gomock.Any(), fixedUUID, 5).
+					Return(nil)
+			},
+		},
+		{
+			name:           "An ETag of the list matches",
+			method:         http.MethodDelete,
+			bookingID:      fixedUUID.String(),
+			ifMatch:        `W/"5", "4", "5"`,
+			expectedStatus: http.StatusNoContent,
+			mockSetup: func() {
+				mockService.EXPECT().GetBooking(gomock.Any(), fixedUUID).Return(&models.Booking{ID: fixedUUID, Version: 5}, nil)
+				mockService.EXPECT().DeleteBooking(gomock.Any(), fixedUUID, 5).
+					Return(nil)
+			},
+		},
+		{
+			name:           "Booking changed after the list was matched",
+			method:         http.MethodDelete,
+			bookingID:      fixedUUID.String(),
+			ifMatch:        `"4", "5"`,
+			expectedStatus: http.StatusPreconditionFailed,
+			mockSetup: func() {
+				mockService.EXPECT().GetBooking(gomock.Any(), fixedUUID).Return(&models.Booking{ID: fixedUUID, Version: 5}, nil)
+				mockService.EXPECT().DeleteBooking(gomock.Any(), fixedUUID, 5).
+					Return(fmt.Errorf("unable to delete booking: %w", database.ErrVersionMismatch))
+			},
+		},
 		{
 			name:           "Internal Server Error",
 			method:         http.MethodDelete,
 			bookingID:      fixedUUID.String(),
+			ifMatch:        `"1"`,
 			expectedStatus: http.StatusInternalServerError,
 			mockSetup: func() {
-				mockService.EXPECT().DeleteBooking(gomock.Any(), fixedUUID).
+				mockService.EXPECT().DeleteBooking(gomock.Any(), fixedUUID, 1).
 					Return(errors.New("internal error"))
 			},
 		},
@@ -352,9 +809,10 @@ func TestDeleteBooking(t *testing.T) {
 			name:           "Success",
 			method:         http.MethodDelete,
 			bookingID:      fixedUUID.String(),
+			ifMatch:        `"12"`,
 			expectedStatus: http.StatusNoContent,
 			mockSetup: func() {
-				mockService.EXPECT().DeleteBooking(gomock.Any(), fixedUUID).
+				mockService.EXPECT().DeleteBooking(gomock.Any(), fixedUUID, 12).
 					Return(nil)
 			},
 		},
@@ -367,6 +825,9 @@ func TestDeleteBooking(t *testing.T) {
 			}
 
 			req := httptest.NewRequest(tt.method, "/bookings/"+tt.bookingID, nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 			response := httptest.NewRecorder()
 
 			router := mux.NewRouter()
@@ -383,6 +844,157 @@ func timeDate(year, month, day int) time.Time {
 	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
 }
 
+func TestGetBookingHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockService(ctrl)
+	handler := bookingsHTTP{service: mockService}
+	fixedUUID := uuid.MustParse("0aadd991-953d-48d3-a4a8-8e1182a2c723")
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	booking := models.Booking{
+		ID:            fixedUUID,
+		Status:        models.BookingStatusConfirmed,
+		FirstName:     "Jane",
+		LastName:      "Doe",
+		Gender:        "female",
+		Birthday:      timeDate(1990, 1, 1),
+		LaunchPadID:   "valid-pad",
+		DestinationID: "dest-456",
+		LaunchDate:    timeDate(2024, 12, 31),
+		CreatedAt:     ts,
+		UpdatedAt:     ts,
+		Version:       1,
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		bookingID      string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Method Not Allowed",
+			method:         http.MethodPost,
+			bookingID:      fixedUUID.String(),
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "Bad Request - Invalid UUID",
+			method:         http.MethodGet,
+			bookingID:      "invalid-uuid",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "Booking Without History",
+			method:    http.MethodGet,
+			bookingID: fixedUUID.String(),
+			mockSetup: func() {
+				mockService.EXPECT().GetBookingHistory(gomock.Any(), fixedUUID).Return(nil, database.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:      "Service Error",
+			method:    http.MethodGet,
+			bookingID: fixedUUID.String(),
+			mockSetup: func() {
+				mockService.EXPECT().GetBookingHistory(gomock.Any(), fixedUUID).Return(nil, errors.New("connection reset"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:      "Success",
+			method:    http.MethodGet,
+			bookingID: fixedUUID.String(),
+			mockSetup: func() {
+				mockService.EXPECT().GetBookingHistory(gomock.Any(), fixedUUID).Return([]models.AuditEntry{
+					{
+						BookingID:  fixedUUID,
+						Action:     models.AuditActionCreated,
+						Actor:      "alice",
+						OccurredAt: ts,
+						After:      &booking,
+					},
+					{
+						BookingID:  fixedUUID,
+						Action:     models.AuditActionDeleted,
+						Actor:      "bob",
+						OccurredAt: ts.Add(time.Hour),
+						Before:     &booking,
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{
+	"booking_id":"0aadd991-953d-48d3-a4a8-8e1182a2c723",
+	"entries":[
+		{
+			"action":"created",
+			"actor":"alice",
+			"occurred_at":"2024-01-02T03:04:05Z",
+			"after":{
+				"id":"0aadd991-953d-48d3-a4a8-8e1182a2c723",
+				"status":"confirmed",
+				"first_name":"Jane",
+				"last_name":"Doe",
+				"gender":"female",
+				"birthday":"1990-01-01",
+				"launch_pad_id":"valid-pad",
+				"destination_id":"dest-456",
+				"launch_date":"2024-12-31",
+				"created_at":"2024-01-02T03:04:05Z",
+				"updated_at":"2024-01-02T03:04:05Z",
+				"version":1
+			}
+		},
+		{
+			"action":"deleted",
+			"actor":"bob",
+			"occurred_at":"2024-01-02T04:04:05Z",
+			"before":{
+				"id":"0aadd991-953d-48d3-a4a8-8e1182a2c723",
+				"status":"confirmed",
+				"first_name":"Jane",
+				"last_name":"Doe",
+				"gender":"female",
+				"birthday":"1990-01-01",
+				"launch_pad_id":"valid-pad",
+				"destination_id":"dest-456",
+				"launch_date":"2024-12-31",
+				"created_at":"2024-01-02T03:04:05Z",
+				"updated_at":"2024-01-02T03:04:05Z",
+				"version":1
+			}
+		}
+	]
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			req := httptest.NewRequest(tt.method, "/bookings/"+tt.bookingID+"/history", nil)
+			response := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/bookings/{booking-id}/history", handler.GetBookingHistory)
+			router.ServeHTTP(response, req)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, response.Body.String())
+			}
+		})
+	}
+}
+
 func toPtr(s string) *string {
 	return &s
 }
@@ -5,11 +5,16 @@ import (
 	"errors"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/reasons"
 	bookingsv1 "github.com/zsoltggs/tabeo-interview/services/bookings/pkg/bookings/v1"
 )
 
@@ -58,6 +63,18 @@ func createListBookingsFromQueryParams(params url.Values) (*bookingsv1.ListBooki
 	if destinationID := params.Get("destination_id"); destinationID != "" {
 		req.Filters.DestinationID = &destinationID
 	}
+
+	if status := params.Get("status"); status != "" {
+		req.Filters.Status = &status
+	}
+
+	if firstName := params.Get("first_name"); firstName != "" {
+		req.Filters.FirstName = &firstName
+	}
+
+	if lastName := params.Get("last_name"); lastName != "" {
+		req.Filters.LastName = &lastName
+	}
 	return &req, nil
 }
 
@@ -65,6 +82,9 @@ func toDomainFilter(filters bookingsv1.ListBookingsFilters) (models.Filters, err
 	result := models.Filters{
 		LaunchPadID:   filters.LaunchPadID,
 		DestinationID: filters.DestinationID,
+		Status:        filters.Status,
+		FirstName:     filters.FirstName,
+		LastName:      filters.LastName,
 	}
 	if filters.LaunchDate != nil {
 		launchDate, err := time.Parse("2006-01-02", *filters.LaunchDate)
@@ -79,6 +99,7 @@ func toDomainFilter(filters bookingsv1.ListBookingsFilters) (models.Filters, err
 func fromDomainBooking(booking models.Booking) bookingsv1.Booking {
 	return bookingsv1.Booking{
 		ID:            booking.ID,
+		Status:        booking.Status,
 		FirstName:     booking.FirstName,
 		LastName:      booking.LastName,
 		Gender:        booking.Gender,
@@ -88,6 +109,105 @@ func fromDomainBooking(booking models.Booking) bookingsv1.Booking {
 		LaunchDate:    booking.LaunchDate.Format("2006-01-02"),
 		CreatedAt:     booking.CreatedAt,
 		UpdatedAt:     booking.UpdatedAt,
+		Version:       booking.Version,
+	}
+}
+
+func fromDomainAuditEntry(entry models.AuditEntry) bookingsv1.BookingAuditEntry {
+	result := bookingsv1.BookingAuditEntry{
+		Action:     entry.Action,
+		Actor:      entry.Actor,
+		OccurredAt: entry.OccurredAt,
+	}
+	if entry.Before != nil {
+		before := fromDomainBooking(*entry.Before)
+		result.Before = &before
+	}
+	if entry.After != nil {
+		after := fromDomainBooking(*entry.After)
+		result.After = &after
+	}
+	return result
+}
+
+func bookingIDFromPath(request *http.Request) (uuid.UUID, error) {
+	return uuid.Parse(mux.Vars(request)["booking-id"])
+}
+
+// etag is the entity tag of a version of a booking
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatch is the precondition of an If-Match header
+type ifMatch struct {
+	// any is set by "*", which matches any current version of the booking
+	any bool
+	// versions are the versions of the strong ETags of the list, weak ETags and other tags never match
+	versions []int
+}
+
+// matches reports whether the precondition holds for the current version of the booking
+func (m ifMatch) matches(version int) bool {
+	return m.any || slices.Contains(m.versions, version)
+}
+
+// parseIfMatch parses the If-Match headers, "*" or a comma separated list of ETags
+func parseIfMatch(headers []string) (ifMatch, bool) {
+	value := strings.TrimSpace(strings.Join(headers, ","))
+	if value == "*" {
+		return ifMatch{any: true}, true
+	}
+	var result ifMatch
+	for {
+		value = strings.TrimLeft(value, " \t,")
+		if value == "" {
+			return result, true
+		}
+		// Weak ETags are valid, but If-Match uses the strong comparison which they never pass
+		weak := strings.HasPrefix(value, "W/")
+		value = strings.TrimPrefix(value, "W/")
+		if !strings.HasPrefix(value, `"`) {
+			return ifMatch{}, false
+		}
+		end := strings.IndexByte(value[1:], '"')
+		if end < 0 {
+			return ifMatch{}, false
+		}
+		tag := value[:end+2]
+		value = value[end+2:]
+		if rest := strings.TrimLeft(value, " \t"); rest != "" && rest[0] != ',' {
+			return ifMatch{}, false
+		}
+		version, err := strconv.Atoi(strings.Trim(tag, `"`))
+		if !weak && err == nil && tag == etag(version) {
+			result.versions = append(result.versions, version)
+		}
+	}
+}
+
+func writePreconditionFailed(response http.ResponseWriter) {
+	response.WriteHeader(http.StatusPreconditionFailed)
+	writeErrorResponse(response, "booking was changed since it was read, get it again for its current ETag")
+}
+
+// writeBookingResponse writes the booking with its version as ETag
+func writeBookingResponse(response http.ResponseWriter, booking models.Booking) {
+	result := fromDomainBooking(booking)
+	respJSON, err := json.Marshal(bookingsv1.BookingResponse{
+		Booking: &result,
+	})
+	if err != nil {
+		log.WithError(err).Error("unable to marshal booking response")
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.Header().Set("ETag", etag(booking.Version))
+	response.WriteHeader(http.StatusOK)
+	_, err = response.Write(respJSON)
+	if err != nil {
+		log.WithError(err).Error("unable to write booking response")
 	}
 }
 
@@ -108,6 +228,72 @@ func writeErrorResponse(response http.ResponseWriter, reason string) {
 	}
 }
 
+// writeNotAvailableResponse writes the conflict with the suggested alternatives if the error carries them
+func writeNotAvailableResponse(response http.ResponseWriter, err error) {
+	resp := bookingsv1.NotAvailableResponse{
+		Error: "date is unavailable",
+	}
+	var notAvailableErr *models.NotAvailableError
+	if errors.As(err, &notAvailableErr) {
+		resp.Reasons = reasons.FromDomain(notAvailableErr.Reasons)
+		if notAvailableErr.Suggestions != nil {
+			resp.Suggestions = fromDomainSuggestions(*notAvailableErr.Suggestions)
+		}
+	}
+	respJSON, err := json.Marshal(resp)
+	if err != nil {
+		log.WithError(err).Error("unable to marshal not available response")
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(http.StatusConflict)
+	_, err = response.Write(respJSON)
+	if err != nil {
+		log.WithError(err).Error("unable to write not available response")
+	}
+}
+
+func fromDomainSuggestions(suggestions models.Suggestions) *bookingsv1.Suggestions {
+	result := bookingsv1.Suggestions{
+		Dates:        make([]string, 0, len(suggestions.Dates)),
+		LaunchPadIDs: make([]string, 0, len(suggestions.LaunchPadIDs)),
+	}
+	for _, date := range suggestions.Dates {
+		result.Dates = append(result.Dates, date.Format("2006-01-02"))
+	}
+	result.LaunchPadIDs = append(result.LaunchPadIDs, suggestions.LaunchPadIDs...)
+	return &result
+}
+
+func toDomainUpdate(req bookingsv1.UpdateBookingRequest) (models.UpdateBooking, error) {
+	if req.FirstName == nil && req.LastName == nil && req.Gender == nil && req.Birthday == nil {
+		return models.UpdateBooking{}, errors.New("nothing to update")
+	}
+	if req.FirstName != nil && *req.FirstName == "" {
+		return models.UpdateBooking{}, errors.New("first name cannot be empty")
+	}
+	if req.LastName != nil && *req.LastName == "" {
+		return models.UpdateBooking{}, errors.New("last name cannot be empty")
+	}
+	if req.Gender != nil && *req.Gender != "male" && *req.Gender != "female" && *req.Gender != "other" {
+		return models.UpdateBooking{}, errors.New("invalid gender value, accepted values for gender: male, female, other")
+	}
+	result := models.UpdateBooking{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Gender:    req.Gender,
+	}
+	if req.Birthday != nil {
+		birthday, err := time.Parse("2006-01-02", *req.Birthday)
+		if err != nil {
+			return models.UpdateBooking{}, errors.New("invalid birthday, accepted format: 2006-01-02")
+		}
+		result.Birthday = &birthday
+	}
+	return result, nil
+}
+
 func toDomainBooking(req bookingsv1.CreateBookingRequest) (*models.CreateBooking, error) {
 	if req.DestinationID == "" {
 		return nil, errors.New("destination id is required")
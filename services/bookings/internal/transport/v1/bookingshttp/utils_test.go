@@ -205,12 +205,16 @@ func TestCreateListBookingsFromQueryParams(t *testing.T) {
 				"launch_date":    []string{"2024-12-01"},
 				"launch_pad_id":  []string{"lp-123"},
 				"destination_id": []string{"dest-456"},
+				"first_name":     []string{"John"},
+				"last_name":      []string{"Doe"},
 			},
 			expected: &bookingsv1.ListBookingsRequest{
 				Filters: bookingsv1.ListBookingsFilters{
 					LaunchDate:    stringPtr("2024-12-01"),
 					LaunchPadID:   stringPtr("lp-123"),
 					DestinationID: stringPtr("dest-456"),
+					FirstName:     stringPtr("John"),
+					LastName:      stringPtr("Doe"),
 				},
 				Pagination: bookingsv1.Pagination{
 					Offset: 5,
@@ -329,3 +333,30 @@ func parseDate(dateStr string) time.Time {
 	}
 	return parsedDate
 }
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  []string
+		expected ifMatch
+		valid    bool
+	}{
+		{name: "ETag", headers: []string{`"3"`}, expected: ifMatch{versions: []int{3}}, valid: true},
+		{name: "Any", headers: []string{` * `}, expected: ifMatch{any: true}, valid: true},
+		{name: "List", headers: []string{`"3", "4" ,"5"`}, expected: ifMatch{versions: []int{3, 4, 5}}, valid: true},
+		{name: "Several headers", headers: []string{`"3"`, `"4"`}, expected: ifMatch{versions: []int{3, 4}}, valid: true},
+		{name: "Weak and other ETags never match", headers: []string{`W/"3", "abc", "0x4", "a,b"`}, valid: true},
+		{name: "Unquoted", headers: []string{`3`}},
+		{name: "Unterminated", headers: []string{`"3`}},
+		{name: "Missing comma", headers: []string{`"3" "4"`}},
+		{name: "Any in a list", headers: []string{`"3", *`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, valid := parseIfMatch(tt.headers)
+			assert.Equal(t, tt.valid, valid)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
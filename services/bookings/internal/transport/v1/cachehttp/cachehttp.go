@@ -0,0 +1,129 @@
+package cachehttp
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/httpresponse"
+	bookingsv1 "github.com/zsoltggs/tabeo-interview/services/bookings/pkg/bookings/v1"
+)
+
+const dateLayout = "2006-01-02"
+
+type CacheHTTP interface {
+	Stats(response http.ResponseWriter, request *http.Request)
+	Invalidate(response http.ResponseWriter, request *http.Request)
+}
+
+type cacheHTTP struct {
+	// caches of the launch schedule providers by name
+	caches map[string]spacex.Cache
+}
+
+func New(caches map[string]spacex.Cache) CacheHTTP {
+	return &cacheHTTP{
+		caches: caches,
+	}
+}
+
+// Stats returns the hits, misses, size and age of the cache of every launch schedule provider
+func (h cacheHTTP) Stats(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	resp := bookingsv1.CacheStatsResponse{
+		Providers: []bookingsv1.ProviderCacheStats{},
+	}
+	for _, name := range h.providerNames() {
+		stats, err := h.caches[name].Stats(request.Context())
+		if err != nil {
+			log.WithError(err).WithField("provider", name).Error("unable to get cache stats")
+			httpresponse.WriteError(response, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		resp.Providers = append(resp.Providers, bookingsv1.ProviderCacheStats{
+			Provider:              name,
+			Hits:                  stats.Hits,
+			Misses:                stats.Misses,
+			Launchpads:            stats.Launchpads,
+			LaunchDates:           stats.LaunchDates,
+			OldestEntryAgeSeconds: stats.OldestEntryAge.Seconds(),
+			NewestEntryAgeSeconds: stats.NewestEntryAge.Seconds(),
+		})
+	}
+	httpresponse.Write(response, http.StatusOK, resp)
+}
+
+// Invalidate drops cached launches by the provider, launch_pad_id, from and to query params, all of them optional.
+// Launch pads are dropped too when no date is given.
+func (h cacheHTTP) Invalidate(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodDelete {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	params := request.URL.Query()
+	names := h.providerNames()
+	if provider := params.Get("provider"); provider != "" {
+		if _, ok := h.caches[provider]; !ok {
+			httpresponse.WriteError(response, http.StatusBadRequest, "unknown provider")
+			return
+		}
+		names = []string{provider}
+	}
+	from, err := parseDate(params.Get("from"))
+	if err != nil {
+		httpresponse.WriteError(response, http.StatusBadRequest, "from has to be a date (YYYY-MM-DD)")
+		return
+	}
+	to, err := parseDate(params.Get("to"))
+	if err != nil {
+		httpresponse.WriteError(response, http.StatusBadRequest, "to has to be a date (YYYY-MM-DD)")
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		httpresponse.WriteError(response, http.StatusBadRequest, "to has to be after from")
+		return
+	}
+
+	invalidated := 0
+	for _, name := range names {
+		count, err := h.caches[name].Invalidate(request.Context(), params.Get("launch_pad_id"), from, to)
+		invalidated += count
+		if err != nil {
+			log.WithError(err).WithField("provider", name).Error("unable to invalidate cache")
+			httpresponse.WriteError(response, http.StatusInternalServerError, "internal server error")
+			return
+		}
+	}
+	log.WithFields(log.Fields{
+		"providers":     names,
+		"launch_pad_id": params.Get("launch_pad_id"),
+		"from":          params.Get("from"),
+		"to":            params.Get("to"),
+		"invalidated":   invalidated,
+	}).Info("cache invalidated")
+	httpresponse.Write(response, http.StatusOK, bookingsv1.InvalidateCacheResponse{
+		Invalidated: invalidated,
+	})
+}
+
+func (h cacheHTTP) providerNames() []string {
+	names := make([]string, 0, len(h.caches))
+	for name := range h.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(dateLayout, value)
+}
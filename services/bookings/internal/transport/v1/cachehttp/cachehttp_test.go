@@ -0,0 +1,164 @@
+package cachehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex/smodels"
+)
+
+var now = time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+
+// newCache returns a cache holding pad-1 and its launches of the three days before now
+func newCache(t *testing.T, ctrl *gomock.Controller, clock clockwork.Clock) spacex.Cache {
+	mockService := mocks.NewMockSpaceXService(ctrl)
+	mockService.EXPECT().
+		GetLaunchPadForID(gomock.Any(), "pad-1").
+		Return(&smodels.Launchpad{ID: "pad-1"}, nil)
+	mockService.EXPECT().
+		GetLaunchesForDate(gomock.Any(), "pad-1", gomock.Any()).
+		Return(nil, nil).Times(3)
+
+	cache := spacex.NewCache(mockService, clock, spacex.CacheConfig{PastLaunchTTL: 24 * time.Hour})
+	ctx := context.Background()
+	_, err := cache.GetLaunchPadForID(ctx, "pad-1")
+	assert.NoError(t, err)
+	for day := -3; day < 0; day++ {
+		_, err := cache.GetLaunchesForDate(ctx, "pad-1", now.AddDate(0, 0, day))
+		assert.NoError(t, err)
+	}
+	return cache
+}
+
+func TestStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clock := clockwork.NewFakeClockAt(now)
+	handler := New(map[string]spacex.Cache{
+		"spacex":  newCache(t, ctrl, clock),
+		"blue-uk": spacex.NewCache(mocks.NewMockSpaceXService(ctrl), clock, spacex.CacheConfig{}),
+	})
+	clock.Advance(time.Minute)
+
+	recorder := httptest.NewRecorder()
+	handler.Stats(recorder, httptest.NewRequest(http.MethodGet, "/admin/cache", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"providers":[
+		{"provider":"blue-uk","hits":0,"misses":0,"launchpads":0,"launch_dates":0,"oldest_entry_age_seconds":0,"newest_entry_age_seconds":0},
+		{"provider":"spacex","hits":0,"misses":4,"launchpads":1,"launch_dates":3,"oldest_entry_age_seconds":60,"newest_entry_age_seconds":60}
+	]}`, recorder.Body.String())
+}
+
+func TestStats_BackendError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBackend := mocks.NewMockBackend(ctrl)
+	mockBackend.EXPECT().List(gomock.Any(), "").Return(nil, errors.New("connection refused"))
+	handler := New(map[string]spacex.Cache{
+		"spacex": spacex.NewCache(mocks.NewMockSpaceXService(ctrl), clockwork.NewFakeClock(), spacex.CacheConfig{Backend: mockBackend}),
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.Stats(recorder, httptest.NewRequest(http.MethodGet, "/admin/cache", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.JSONEq(t, `{"error":"internal server error"}`, recorder.Body.String())
+}
+
+func TestInvalidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Invalid method",
+			method:         http.MethodPost,
+			url:            "/admin/cache",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "Unknown provider",
+			method:         http.MethodDelete,
+			url:            "/admin/cache?provider=unknown",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"unknown provider"}`,
+		},
+		{
+			name:           "Invalid from",
+			method:         http.MethodDelete,
+			url:            "/admin/cache?from=yesterday",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"from has to be a date (YYYY-MM-DD)"}`,
+		},
+		{
+			name:           "To before from",
+			method:         http.MethodDelete,
+			url:            "/admin/cache?from=2049-07-06&to=2049-07-05",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"to has to be after from"}`,
+		},
+		{
+			name:           "Date range",
+			method:         http.MethodDelete,
+			url:            "/admin/cache?provider=spacex&launch_pad_id=pad-1&from=2049-07-05&to=2049-07-06",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"invalidated":2}`,
+		},
+		{
+			name:           "Other launch pad",
+			method:         http.MethodDelete,
+			url:            "/admin/cache?launch_pad_id=pad-2",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"invalidated":0}`,
+		},
+		{
+			name:           "Launch pad",
+			method:         http.MethodDelete,
+			url:            "/admin/cache?launch_pad_id=pad-1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"invalidated":4}`,
+		},
+		{
+			name:           "Everything",
+			method:         http.MethodDelete,
+			url:            "/admin/cache",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"invalidated":4}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			handler := New(map[string]spacex.Cache{
+				"spacex": newCache(t, ctrl, clockwork.NewFakeClockAt(now)),
+			})
+
+			recorder := httptest.NewRecorder()
+			handler.Invalidate(recorder, httptest.NewRequest(tt.method, tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package closureshttp
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/closures"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/httpresponse"
+	bookingsv1 "github.com/zsoltggs/tabeo-interview/services/bookings/pkg/bookings/v1"
+)
+
+// maxUploadSize limits the size of an uploaded calendar or sheet
+const maxUploadSize = 10 << 20
+
+type ClosuresHTTP interface {
+	ImportClosures(response http.ResponseWriter, request *http.Request)
+}
+
+type closuresHTTP struct {
+	importer closures.Importer
+}
+
+func New(importer closures.Importer) ClosuresHTTP {
+	return &closuresHTTP{
+		importer: importer,
+	}
+}
+
+// ImportClosures replaces the closures of the source query param with the uploaded ICS or CSV file.
+// The format is taken from the format query param, the extension of the source or the Content-Type.
+func (h closuresHTTP) ImportClosures(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	source := request.URL.Query().Get("source")
+	if source == "" {
+		httpresponse.WriteError(response, http.StatusBadRequest, "source is required")
+		return
+	}
+	format, err := detectFormat(request, source)
+	if err != nil {
+		httpresponse.WriteError(response, http.StatusBadRequest, "format has to be ics or csv")
+		return
+	}
+
+	body := http.MaxBytesReader(response, request.Body, maxUploadSize)
+	defer body.Close()
+	imported, err := h.importer.Import(request.Context(), source, format, body)
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		httpresponse.WriteError(response, http.StatusRequestEntityTooLarge, "file is too large")
+		return
+	case errors.Is(err, closures.ErrInvalidFile):
+		httpresponse.WriteError(response, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		log.WithError(err).Error("unable to import closures")
+		httpresponse.WriteError(response, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	respJSON, err := json.Marshal(bookingsv1.ImportClosuresResponse{
+		Source:   source,
+		Imported: imported,
+	})
+	if err != nil {
+		log.WithError(err).Error("unable to marshal import closures response")
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(http.StatusOK)
+	_, err = response.Write(respJSON)
+	if err != nil {
+		log.WithError(err).Error("unable to write import closures response")
+	}
+}
+
+func detectFormat(request *http.Request, source string) (closures.Format, error) {
+	if format := request.URL.Query().Get("format"); format != "" {
+		return closures.ParseFormat(format)
+	}
+	if format, err := closures.FormatFromFileName(source); err == nil {
+		return format, nil
+	}
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	if err != nil {
+		return "", err
+	}
+	switch mediaType {
+	case "text/calendar":
+		return closures.FormatICS, nil
+	case "text/csv":
+		return closures.FormatCSV, nil
+	default:
+		return "", closures.ErrUnsupportedFormat
+	}
+}
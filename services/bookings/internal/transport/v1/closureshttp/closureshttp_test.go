@@ -0,0 +1,120 @@
+package closureshttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/closures"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+)
+
+func TestImportClosures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockImporter := mocks.NewMockImporter(ctrl)
+	handler := New(mockImporter)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		contentType    string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Invalid method",
+			method:         http.MethodGet,
+			url:            "/closures/import?source=partner.ics",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "Missing source",
+			method:         http.MethodPost,
+			url:            "/closures/import",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"source is required"}`,
+		},
+		{
+			name:           "Unknown format",
+			method:         http.MethodPost,
+			url:            "/closures/import?source=partner",
+			contentType:    "application/json",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"format has to be ics or csv"}`,
+		},
+		{
+			name:   "Format from the source",
+			method: http.MethodPost,
+			url:    "/closures/import?source=partner.ics",
+			mockSetup: func() {
+				mockImporter.EXPECT().Import(gomock.Any(), "partner.ics", closures.FormatICS, gomock.Any()).Return(2, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"source":"partner.ics","imported":2}`,
+		},
+		{
+			name:        "Format from the content type",
+			method:      http.MethodPost,
+			url:         "/closures/import?source=partner",
+			contentType: "text/csv; charset=utf-8",
+			mockSetup: func() {
+				mockImporter.EXPECT().Import(gomock.Any(), "partner", closures.FormatCSV, gomock.Any()).Return(1, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"source":"partner","imported":1}`,
+		},
+		{
+			name:   "Invalid file",
+			method: http.MethodPost,
+			url:    "/closures/import?source=partner&format=csv",
+			mockSetup: func() {
+				mockImporter.EXPECT().Import(gomock.Any(), "partner", closures.FormatCSV, gomock.Any()).
+					Return(0, fmt.Errorf("%w: missing ends_at column", closures.ErrInvalidFile))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid closures file: missing ends_at column"}`,
+		},
+		{
+			name:   "Internal error",
+			method: http.MethodPost,
+			url:    "/closures/import?source=partner.csv",
+			mockSetup: func() {
+				mockImporter.EXPECT().Import(gomock.Any(), "partner.csv", closures.FormatCSV, gomock.Any()).
+					Return(0, errors.New("boom"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader("content"))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ImportClosures(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
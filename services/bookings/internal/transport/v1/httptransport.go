@@ -5,7 +5,13 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/auth"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/availabilityhttp"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/blackoutshttp"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/bookingshttp"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/cachehttp"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/closureshttp"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/launchpadshttp"
 
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport"
 	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/healthhttp"
@@ -15,39 +21,94 @@ import (
 )
 
 type httpTransport struct {
-	httpServer  *http.Server
-	healthSvc   healthhttp.HealthHTTP
-	bookingsSvc bookingshttp.BookingsHTTP
+	httpServer   *http.Server
+	healthSvc    healthhttp.HealthHTTP
+	bookingsSvc  bookingshttp.BookingsHTTP
+	closuresSvc  closureshttp.ClosuresHTTP
+	blackoutsSvc blackoutshttp.BlackoutsHTTP
+	cacheSvc     cachehttp.CacheHTTP
+	padsSvc      launchpadshttp.LaunchPadsHTTP
+	checksSvc    availabilityhttp.AvailabilityHTTP
+	adminToken   string
 }
 
-func NewHTTP(healthSvc healthhttp.HealthHTTP, bookingsSvc bookingshttp.BookingsHTTP) transport.Transport {
+func NewHTTP(healthSvc healthhttp.HealthHTTP,
+	bookingsSvc bookingshttp.BookingsHTTP,
+	closuresSvc closureshttp.ClosuresHTTP,
+	blackoutsSvc blackoutshttp.BlackoutsHTTP,
+	cacheSvc cachehttp.CacheHTTP,
+	padsSvc launchpadshttp.LaunchPadsHTTP,
+	checksSvc availabilityhttp.AvailabilityHTTP,
+	adminToken string) transport.Transport {
 	return &httpTransport{
-		healthSvc:   healthSvc,
-		bookingsSvc: bookingsSvc,
-		httpServer:  &http.Server{},
+		healthSvc:    healthSvc,
+		bookingsSvc:  bookingsSvc,
+		closuresSvc:  closuresSvc,
+		blackoutsSvc: blackoutsSvc,
+		cacheSvc:     cacheSvc,
+		padsSvc:      padsSvc,
+		checksSvc:    checksSvc,
+		adminToken:   adminToken,
+		httpServer:   &http.Server{},
 	}
 }
 
 func (h *httpTransport) Serve(httpPort int) error {
 	port := fmt.Sprintf(":%d", httpPort)
 	log.Infof("about to start server on port %s", port)
+	h.httpServer.Addr = port
+	h.httpServer.Handler = h.router()
+	go func() {
+		if err := h.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("listen: %s\n", err)
+		}
+	}()
+	return nil
+}
+
+// router routes the requests to the handlers, the admin endpoints require the admin token
+func (h *httpTransport) router() *mux.Router {
 	router := mux.NewRouter()
+	router.Use(auth.Middleware)
 	router.HandleFunc("/health", h.healthSvc.HttpHandler).
 		Methods("GET")
+	router.HandleFunc("/debug/vars", serveVars).
+		Methods("GET")
 	router.HandleFunc("/bookings", h.bookingsSvc.ListBookings).
 		Methods("GET")
 	router.HandleFunc("/bookings", h.bookingsSvc.CreateBooking).
 		Methods("POST")
+	router.HandleFunc("/bookings/{booking-id}", h.bookingsSvc.GetBooking).
+		Methods("GET")
+	router.HandleFunc("/bookings/{booking-id}", h.bookingsSvc.UpdateBooking).
+		Methods("PATCH")
 	router.HandleFunc("/bookings/{booking-id}", h.bookingsSvc.DeleteBooking).
 		Methods("DELETE")
-	h.httpServer.Addr = port
-	h.httpServer.Handler = router
-	go func() {
-		if err := h.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %s\n", err)
-		}
-	}()
-	return nil
+	router.HandleFunc("/bookings/{booking-id}/history", h.bookingsSvc.GetBookingHistory).
+		Methods("GET")
+	router.HandleFunc("/launchpads/{launch-pad-id}/availability", h.padsSvc.GetAvailability).
+		Methods("GET")
+	router.HandleFunc("/availability/check", h.checksSvc.CheckAvailability).
+		Methods("POST")
+	admin := router.NewRoute().Subrouter()
+	admin.Use(auth.AdminMiddleware(h.adminToken))
+	admin.HandleFunc("/closures/import", h.closuresSvc.ImportClosures).
+		Methods("POST")
+	admin.HandleFunc("/admin/blackouts", h.blackoutsSvc.ListBlackouts).
+		Methods("GET")
+	admin.HandleFunc("/admin/blackouts", h.blackoutsSvc.CreateBlackout).
+		Methods("POST")
+	admin.HandleFunc("/admin/blackouts/{blackout-id}", h.blackoutsSvc.GetBlackout).
+		Methods("GET")
+	admin.HandleFunc("/admin/blackouts/{blackout-id}", h.blackoutsSvc.UpdateBlackout).
+		Methods("PUT")
+	admin.HandleFunc("/admin/blackouts/{blackout-id}", h.blackoutsSvc.DeleteBlackout).
+		Methods("DELETE")
+	admin.HandleFunc("/admin/cache", h.cacheSvc.Stats).
+		Methods("GET")
+	admin.HandleFunc("/admin/cache", h.cacheSvc.Invalidate).
+		Methods("DELETE")
+	return router
 }
 
 func (h *httpTransport) GracefulStop(ctx context.Context) {
@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/thirdparty/spacex"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/availabilityhttp"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/blackoutshttp"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/bookingshttp"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/cachehttp"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/closureshttp"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/healthhttp"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/launchpadshttp"
+)
+
+func TestRouter_AdminEndpoints(t *testing.T) {
+	const adminToken = "secret"
+	adminRoutes := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodPost, path: "/closures/import"},
+		{method: http.MethodGet, path: "/admin/blackouts"},
+		{method: http.MethodPost, path: "/admin/blackouts"},
+		{method: http.MethodGet, path: "/admin/blackouts/7b0e5c2e-5a0c-4bd8-9d3c-0b4a6c1a0d6e"},
+		{method: http.MethodPut, path: "/admin/blackouts/7b0e5c2e-5a0c-4bd8-9d3c-0b4a6c1a0d6e"},
+		{method: http.MethodDelete, path: "/admin/blackouts/7b0e5c2e-5a0c-4bd8-9d3c-0b4a6c1a0d6e"},
+		{method: http.MethodGet, path: "/admin/cache"},
+		{method: http.MethodDelete, path: "/admin/cache"},
+	}
+	tests := []struct {
+		name           string
+		adminToken     string
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			name:           "No credentials",
+			adminToken:     adminToken,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Wrong token",
+			adminToken:     adminToken,
+			authorization:  "Bearer guess",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "No admin token configured",
+			authorization:  "Bearer ",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		for _, route := range adminRoutes {
+			t.Run(tt.name+" "+route.method+" "+route.path, func(t *testing.T) {
+				router := newTestTransport(gomock.NewController(t), tt.adminToken).router()
+				request := httptest.NewRequest(route.method, route.path, nil)
+				if tt.authorization != "" {
+					request.Header.Set("Authorization", tt.authorization)
+				}
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, request)
+				assert.Equal(t, tt.expectedStatus, recorder.Code)
+			})
+		}
+	}
+
+	t.Run("Admin token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		manager := mocks.NewMockManager(ctrl)
+		manager.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, nil)
+		transport := newTestTransport(ctrl, adminToken)
+		transport.blackoutsSvc = blackoutshttp.New(manager)
+		request := httptest.NewRequest(http.MethodGet, "/admin/blackouts", nil)
+		request.Header.Set("Authorization", "Bearer "+adminToken)
+		recorder := httptest.NewRecorder()
+		transport.router().ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func newTestTransport(ctrl *gomock.Controller, adminToken string) *httpTransport {
+	availabilitySvc := mocks.NewMockAvailability(ctrl)
+	return NewHTTP(healthhttp.New(mocks.NewMockHealthCheckable(ctrl)),
+		bookingshttp.New(mocks.NewMockService(ctrl)),
+		closureshttp.New(mocks.NewMockImporter(ctrl)),
+		blackoutshttp.New(mocks.NewMockManager(ctrl)),
+		cachehttp.New(map[string]spacex.Cache{}),
+		launchpadshttp.New(availabilitySvc),
+		availabilityhttp.New(availabilitySvc, 1),
+		adminToken).(*httpTransport)
+}
@@ -0,0 +1,96 @@
+package launchpadshttp
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/service/availability"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/httpresponse"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/transport/v1/reasons"
+	bookingsv1 "github.com/zsoltggs/tabeo-interview/services/bookings/pkg/bookings/v1"
+)
+
+const dateLayout = "2006-01-02"
+
+type LaunchPadsHTTP interface {
+	GetAvailability(response http.ResponseWriter, request *http.Request)
+}
+
+type launchPadsHTTP struct {
+	availabilitySvc availability.Availability
+}
+
+func New(availabilitySvc availability.Availability) LaunchPadsHTTP {
+	return &launchPadsHTTP{
+		availabilitySvc: availabilitySvc,
+	}
+}
+
+// GetAvailability returns whether the launch pad can be booked on every day between the from and to query params,
+// for the optional destination_id query param
+func (h launchPadsHTTP) GetAvailability(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	launchPadID := mux.Vars(request)["launch-pad-id"]
+	if launchPadID == "" {
+		httpresponse.WriteError(response, http.StatusBadRequest, "launch pad id is required")
+		return
+	}
+	from, err := time.Parse(dateLayout, request.URL.Query().Get("from"))
+	if err != nil {
+		httpresponse.WriteError(response, http.StatusBadRequest, "from is required, accepted format: 2006-01-02")
+		return
+	}
+	to, err := time.Parse(dateLayout, request.URL.Query().Get("to"))
+	if err != nil {
+		httpresponse.WriteError(response, http.StatusBadRequest, "to is required, accepted format: 2006-01-02")
+		return
+	}
+
+	destinationID := request.URL.Query().Get("destination_id")
+
+	calendar, err := h.availabilitySvc.Calendar(request.Context(), launchPadID, destinationID, from, to)
+	var upstreamErr *models.UpstreamUnavailableError
+	switch {
+	case errors.Is(err, models.ErrInvalidRange):
+		httpresponse.WriteError(response, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, models.ErrNotFoundLaunchpad):
+		httpresponse.WriteError(response, http.StatusNotFound, "launch pad with ID not found")
+		return
+	case errors.As(err, &upstreamErr):
+		log.WithError(err).Warn("launch schedule is unavailable")
+		response.Header().Set("Retry-After", strconv.Itoa(httpresponse.RetryAfterSeconds(upstreamErr.RetryAfter)))
+		httpresponse.WriteError(response, http.StatusServiceUnavailable, "launch schedule is temporarily unavailable")
+		return
+	case err != nil:
+		log.WithError(err).Error("unable to get launch pad availability")
+		httpresponse.WriteError(response, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	resp := bookingsv1.LaunchPadAvailabilityResponse{
+		LaunchPadID: launchPadID,
+		Days:        make([]bookingsv1.DayAvailability, 0, len(calendar)),
+	}
+	for _, day := range calendar {
+		resp.Days = append(resp.Days, fromDomainDay(day))
+	}
+	httpresponse.Write(response, http.StatusOK, resp)
+}
+
+func fromDomainDay(day models.DayAvailability) bookingsv1.DayAvailability {
+	return bookingsv1.DayAvailability{
+		Date:      day.Date.Format(dateLayout),
+		Available: day.Available,
+		Reasons:   reasons.FromDomain(day.Reasons),
+	}
+}
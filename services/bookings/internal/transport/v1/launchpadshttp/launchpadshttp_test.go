@@ -0,0 +1,139 @@
+package launchpadshttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/mocks"
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+)
+
+func TestGetAvailability(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAvailability := mocks.NewMockAvailability(ctrl)
+	handler := New(mockAvailability)
+	from := time.Date(2049, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2049, 7, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+		expectedHeader http.Header
+	}{
+		{
+			name:           "Missing from",
+			url:            "/launchpads/pad-1/availability?to=2049-07-02",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"from is required, accepted format: 2006-01-02"}`,
+		},
+		{
+			name:           "Invalid to",
+			url:            "/launchpads/pad-1/availability?from=2049-07-01&to=tomorrow",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"to is required, accepted format: 2006-01-02"}`,
+		},
+		{
+			name: "Range too long",
+			url:  "/launchpads/pad-1/availability?from=2049-07-01&to=2049-07-02",
+			mockSetup: func() {
+				mockAvailability.EXPECT().Calendar(gomock.Any(), "pad-1", "", from, to).
+					Return(nil, fmt.Errorf("%w: more than 1 days", models.ErrInvalidRange))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid date range: more than 1 days"}`,
+		},
+		{
+			name: "Unknown launch pad",
+			url:  "/launchpads/pad-1/availability?from=2049-07-01&to=2049-07-02",
+			mockSetup: func() {
+				mockAvailability.EXPECT().Calendar(gomock.Any(), "pad-1", "", from, to).
+					Return(nil, models.ErrNotFoundLaunchpad)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"launch pad with ID not found"}`,
+		},
+		{
+			name: "Launch schedule unavailable",
+			url:  "/launchpads/pad-1/availability?from=2049-07-01&to=2049-07-02",
+			mockSetup: func() {
+				mockAvailability.EXPECT().Calendar(gomock.Any(), "pad-1", "", from, to).
+					Return(nil, &models.UpstreamUnavailableError{RetryAfter: 2500 * time.Millisecond})
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"error":"launch schedule is temporarily unavailable"}`,
+			expectedHeader: http.Header{"Retry-After": []string{"3"}},
+		},
+		{
+			name: "Internal error",
+			url:  "/launchpads/pad-1/availability?from=2049-07-01&to=2049-07-02",
+			mockSetup: func() {
+				mockAvailability.EXPECT().Calendar(gomock.Any(), "pad-1", "", from, to).
+					Return(nil, errors.New("boom"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"internal server error"}`,
+		},
+		{
+			name: "Calendar",
+			url:  "/launchpads/pad-1/availability?from=2049-07-01&to=2049-07-02",
+			mockSetup: func() {
+				mockAvailability.EXPECT().Calendar(gomock.Any(), "pad-1", "", from, to).
+					Return([]models.DayAvailability{
+						{Date: from, Available: true},
+						{Date: to, Reasons: []models.UnavailabilityReason{{Type: models.ReasonLaunch, Description: "Starlink"}}},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"launch_pad_id":"pad-1","days":[
+				{"date":"2049-07-01","available":true},
+				{"date":"2049-07-02","available":false,"reasons":[{"type":"launch","description":"Starlink"}]}
+			]}`,
+		},
+		{
+			name: "Calendar for a destination",
+			url:  "/launchpads/pad-1/availability?from=2049-07-01&to=2049-07-01&destination_id=mars",
+			mockSetup: func() {
+				mockAvailability.EXPECT().Calendar(gomock.Any(), "pad-1", "mars", from, from).
+					Return([]models.DayAvailability{
+						{Date: from, Reasons: []models.UnavailabilityReason{{Type: models.ReasonDestinationConflict, Description: "flight goes to moon"}}},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"launch_pad_id":"pad-1","days":[
+				{"date":"2049-07-01","available":false,"reasons":[{"type":"destination_conflict","description":"flight goes to moon"}]}
+			]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/launchpads/{launch-pad-id}/availability", handler.GetAvailability)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.JSONEq(t, tt.expectedBody, recorder.Body.String())
+			for key := range tt.expectedHeader {
+				assert.Equal(t, tt.expectedHeader.Get(key), recorder.Header().Get(key))
+			}
+		})
+	}
+}
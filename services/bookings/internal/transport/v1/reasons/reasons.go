@@ -0,0 +1,45 @@
+package reasons
+
+import (
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	bookingsv1 "github.com/zsoltggs/tabeo-interview/services/bookings/pkg/bookings/v1"
+)
+
+// FromDomain converts the reasons a launch pad is unavailable to their API representation
+func FromDomain(reasons []models.UnavailabilityReason) []bookingsv1.UnavailabilityReason {
+	if len(reasons) == 0 {
+		return nil
+	}
+	result := make([]bookingsv1.UnavailabilityReason, 0, len(reasons))
+	for _, reason := range reasons {
+		converted := bookingsv1.UnavailabilityReason{
+			Type:            reason.Type,
+			Description:     reason.Description,
+			DestinationID:   reason.DestinationID,
+			LaunchPadStatus: reason.LaunchPadStatus,
+		}
+		if reason.Launch != nil {
+			converted.Launch = &bookingsv1.ConflictingLaunch{
+				ID:       reason.Launch.ID,
+				Name:     reason.Launch.Name,
+				DateUTC:  reason.Launch.DateUTC,
+				Provider: reason.Launch.Provider,
+			}
+		}
+		if reason.Window != nil {
+			converted.Window = &bookingsv1.ClosedWindow{
+				StartsAt: reason.Window.StartsAt,
+				EndsAt:   reason.Window.EndsAt,
+				Source:   reason.Window.Source,
+			}
+		}
+		if reason.Seats != nil {
+			converted.Seats = &bookingsv1.Seats{
+				Booked:   reason.Seats.Booked,
+				Capacity: reason.Seats.Capacity,
+			}
+		}
+		result = append(result, converted)
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package reasons
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zsoltggs/tabeo-interview/services/bookings/internal/models"
+	bookingsv1 "github.com/zsoltggs/tabeo-interview/services/bookings/pkg/bookings/v1"
+)
+
+func TestFromDomain(t *testing.T) {
+	date := time.Date(2049, 7, 7, 0, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, FromDomain(nil))
+	assert.Equal(t, []bookingsv1.UnavailabilityReason{
+		{
+			Type:        models.ReasonLaunch,
+			Description: "Starlink",
+			Launch:      &bookingsv1.ConflictingLaunch{ID: "launch-1", Name: "Starlink", DateUTC: date.Add(time.Hour), Provider: "spacex"},
+		},
+		{
+			Type:        models.ReasonClosure,
+			Description: "Range maintenance",
+			Window:      &bookingsv1.ClosedWindow{StartsAt: date, EndsAt: date.AddDate(0, 0, 1), Source: "partner.ics"},
+		},
+		{
+			Type:        models.ReasonFlightFull,
+			Description: "2 of 2 seats booked",
+			Seats:       &bookingsv1.Seats{Booked: 2, Capacity: 2},
+		},
+		{Type: models.ReasonDestinationConflict, Description: "flight goes to moon", DestinationID: "moon"},
+		{Type: models.ReasonLaunchPadInactive, Description: "launch pad is retired", LaunchPadStatus: "retired"},
+	}, FromDomain([]models.UnavailabilityReason{
+		{
+			Type:        models.ReasonLaunch,
+			Description: "Starlink",
+			Launch:      &models.ConflictingLaunch{ID: "launch-1", Name: "Starlink", DateUTC: date.Add(time.Hour), Provider: "spacex"},
+		},
+		{
+			Type:        models.ReasonClosure,
+			Description: "Range maintenance",
+			Window:      &models.ClosedWindow{StartsAt: date, EndsAt: date.AddDate(0, 0, 1), Source: "partner.ics"},
+		},
+		{
+			Type:        models.ReasonFlightFull,
+			Description: "2 of 2 seats booked",
+			Seats:       &models.Seats{Booked: 2, Capacity: 2},
+		},
+		{Type: models.ReasonDestinationConflict, Description: "flight goes to moon", DestinationID: "moon"},
+		{Type: models.ReasonLaunchPadInactive, Description: "launch pad is retired", LaunchPadStatus: "retired"},
+	}))
+}
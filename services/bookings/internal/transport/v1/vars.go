@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// runtimeVars are published by the expvar package itself: cmdline holds the flags of the process,
+// the database connection string among them, and memstats the details of its memory
+var runtimeVars = map[string]bool{
+	"cmdline":  true,
+	"memstats": true,
+}
+
+// serveVars serves the variables published by the service, e.g. the limiter counters and the health of the
+// launch schedule providers, like expvar.Handler but without the runtimeVars
+func serveVars(w http.ResponseWriter, _ *http.Request) {
+	vars := map[string]json.RawMessage{}
+	expvar.Do(func(kv expvar.KeyValue) {
+		if !runtimeVars[kv.Key] {
+			vars[kv.Key] = json.RawMessage(kv.Value.String())
+		}
+	})
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err := json.NewEncoder(w).Encode(vars)
+	if err != nil {
+		log.WithError(err).Error("unable to write vars")
+	}
+}
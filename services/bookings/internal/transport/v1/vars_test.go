@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeVars(t *testing.T) {
+	limiter := expvar.NewMap("test_limiter")
+	limiter.Add("in_flight", 2)
+
+	rec := httptest.NewRecorder()
+	serveVars(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var vars map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &vars))
+	assert.JSONEq(t, `{"in_flight": 2}`, string(vars["test_limiter"]))
+	assert.NotContains(t, vars, "cmdline", "the flags of the process hold the database credentials")
+	assert.NotContains(t, vars, "memstats")
+}
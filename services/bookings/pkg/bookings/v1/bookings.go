@@ -22,7 +22,8 @@ type CreateBookingResponse struct {
 }
 
 type Booking struct {
-	ID uuid.UUID `json:"id"`
+	ID     uuid.UUID `json:"id"`
+	Status string    `json:"status"`
 
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
@@ -35,6 +36,35 @@ type Booking struct {
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Version is incremented on every change, it is also the ETag of the booking
+	Version int `json:"version"`
+}
+
+type BookingResponse struct {
+	Booking *Booking `json:"booking,omitempty"`
+}
+
+// UpdateBookingRequest changes the details of the passenger, omitted fields are left unchanged
+type UpdateBookingRequest struct {
+	FirstName *string `json:"first_name,omitempty"`
+	LastName  *string `json:"last_name,omitempty"`
+	Gender    *string `json:"gender,omitempty"`
+	Birthday  *string `json:"birthday,omitempty"`
+}
+
+// BookingAuditEntry is a change of a booking, Before is omitted for a creation and After for a deletion
+type BookingAuditEntry struct {
+	Action     string    `json:"action"`
+	Actor      string    `json:"actor"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Before     *Booking  `json:"before,omitempty"`
+	After      *Booking  `json:"after,omitempty"`
+}
+
+type BookingHistoryResponse struct {
+	BookingID uuid.UUID `json:"booking_id"`
+	// Entries are the changes of the booking, oldest first
+	Entries []BookingAuditEntry `json:"entries"`
 }
 
 type HealthResponse struct {
@@ -46,6 +76,19 @@ type ListBookingsRequest struct {
 	Pagination Pagination          `json:"pagination"`
 }
 
+type NotAvailableResponse struct {
+	Error       string                 `json:"error"`
+	Reasons     []UnavailabilityReason `json:"reasons,omitempty"`
+	Suggestions *Suggestions           `json:"suggestions,omitempty"`
+}
+
+type Suggestions struct {
+	// Dates are the nearest available dates on the same launch pad, nearest first
+	Dates []string `json:"dates"`
+	// LaunchPadIDs are the other active launch pads available on the same date
+	LaunchPadIDs []string `json:"launch_pad_ids"`
+}
+
 type ErrorResponse struct {
 	Error string `json:"error,omitempty"`
 }
@@ -59,9 +102,123 @@ type ListBookingsFilters struct {
 	LaunchDate    *string `json:"launch_date"`
 	LaunchPadID   *string `json:"launch_pad_id"`
 	DestinationID *string `json:"destination_id"`
+	Status        *string `json:"status"`
+	FirstName     *string `json:"first_name"`
+	LastName      *string `json:"last_name"`
 }
 
 type Pagination struct {
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
 }
+
+type ImportClosuresResponse struct {
+	Source   string `json:"source"`
+	Imported int    `json:"imported"`
+}
+
+type CacheStatsResponse struct {
+	Providers []ProviderCacheStats `json:"providers"`
+}
+
+type ProviderCacheStats struct {
+	Provider              string  `json:"provider"`
+	Hits                  int64   `json:"hits"`
+	Misses                int64   `json:"misses"`
+	Launchpads            int     `json:"launchpads"`
+	LaunchDates           int     `json:"launch_dates"`
+	OldestEntryAgeSeconds float64 `json:"oldest_entry_age_seconds"`
+	NewestEntryAgeSeconds float64 `json:"newest_entry_age_seconds"`
+}
+
+type InvalidateCacheResponse struct {
+	Invalidated int `json:"invalidated"`
+}
+
+type LaunchPadAvailabilityResponse struct {
+	LaunchPadID string            `json:"launch_pad_id"`
+	Days        []DayAvailability `json:"days"`
+}
+
+type DayAvailability struct {
+	Date      string                 `json:"date"`
+	Available bool                   `json:"available"`
+	Reasons   []UnavailabilityReason `json:"reasons,omitempty"`
+}
+
+type UnavailabilityReason struct {
+	Type            string             `json:"type"`
+	Description     string             `json:"description"`
+	Launch          *ConflictingLaunch `json:"launch,omitempty"`
+	Window          *ClosedWindow      `json:"window,omitempty"`
+	Seats           *Seats             `json:"seats,omitempty"`
+	DestinationID   string             `json:"destination_id,omitempty"`
+	LaunchPadStatus string             `json:"launch_pad_status,omitempty"`
+}
+
+type CheckAvailabilityRequest struct {
+	Checks []AvailabilityCheck `json:"checks"`
+}
+
+type AvailabilityCheck struct {
+	LaunchPadID   string `json:"launch_pad_id"`
+	DestinationID string `json:"destination_id,omitempty"`
+	Date          string `json:"date"`
+}
+
+type CheckAvailabilityResponse struct {
+	// Results are in the order of the checks of the request
+	Results []AvailabilityCheckResult `json:"results"`
+}
+
+type AvailabilityCheckResult struct {
+	LaunchPadID   string                 `json:"launch_pad_id"`
+	DestinationID string                 `json:"destination_id,omitempty"`
+	Date          string                 `json:"date"`
+	Available     bool                   `json:"available"`
+	Provisional   bool                   `json:"provisional,omitempty"`
+	Reasons       []UnavailabilityReason `json:"reasons,omitempty"`
+	// Error is set if the check failed, Available is false then
+	Error             string `json:"error,omitempty"`
+	RetryAfterSeconds int    `json:"retry_after_seconds,omitempty"`
+}
+
+type ConflictingLaunch struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	DateUTC  time.Time `json:"date_utc"`
+	Provider string    `json:"provider"`
+}
+
+type ClosedWindow struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Source   string    `json:"source"`
+}
+
+type Seats struct {
+	Booked   int `json:"booked"`
+	Capacity int `json:"capacity"`
+}
+
+type BlackoutRequest struct {
+	// LaunchPadID is left empty for the blackouts of every launch pad
+	LaunchPadID string    `json:"launch_pad_id"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Reason      string    `json:"reason"`
+}
+
+type Blackout struct {
+	ID          uuid.UUID `json:"id"`
+	LaunchPadID string    `json:"launch_pad_id,omitempty"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ListBlackoutsResponse struct {
+	Blackouts []Blackout `json:"blackouts"`
+}
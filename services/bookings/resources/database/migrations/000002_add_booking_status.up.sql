@@ -0,0 +1,2 @@
+ALTER TABLE bookings
+    ADD COLUMN status VARCHAR(50) NOT NULL DEFAULT 'confirmed';
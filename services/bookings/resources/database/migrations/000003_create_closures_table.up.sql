@@ -0,0 +1,13 @@
+CREATE TABLE closures
+(
+    id            UUID PRIMARY KEY,
+    launch_pad_id VARCHAR(255) NOT NULL,
+    starts_at     TIMESTAMPTZ  NOT NULL,
+    ends_at       TIMESTAMPTZ  NOT NULL,
+    summary       TEXT         NOT NULL DEFAULT '',
+    source        VARCHAR(255) NOT NULL,
+    created_at    TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX closures_launch_pad_id_starts_at_idx ON closures (launch_pad_id, starts_at);
+CREATE INDEX closures_source_idx ON closures (source);
@@ -0,0 +1,9 @@
+CREATE TABLE cache_entries
+(
+    key        TEXT PRIMARY KEY,
+    value      BYTEA       NOT NULL,
+    stored_at  TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX cache_entries_expires_at_idx ON cache_entries (expires_at);
@@ -0,0 +1,14 @@
+CREATE TABLE blackouts
+(
+    id            UUID PRIMARY KEY,
+    -- NULL blacks out every launch pad
+    launch_pad_id VARCHAR(255),
+    starts_at     TIMESTAMPTZ NOT NULL,
+    ends_at       TIMESTAMPTZ NOT NULL,
+    reason        TEXT        NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL,
+    CONSTRAINT blackouts_ends_after_starts CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX blackouts_launch_pad_id_starts_at_idx ON blackouts (launch_pad_id, starts_at);
@@ -0,0 +1,19 @@
+CREATE TABLE outbox_events
+(
+    id           UUID PRIMARY KEY,
+    -- seq orders the events, the events of a booking are published in order
+    seq          BIGSERIAL   NOT NULL,
+    booking_id   UUID        NOT NULL,
+    event_type   VARCHAR(64) NOT NULL,
+    payload      JSONB       NOT NULL,
+    -- pending, published or dead once delivery was given up
+    status       VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    last_error   TEXT        NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL,
+    available_at TIMESTAMPTZ NOT NULL,
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX outbox_events_pending_idx ON outbox_events (available_at, seq) WHERE status = 'pending';
+CREATE INDEX outbox_events_booking_id_seq_idx ON outbox_events (booking_id, seq) WHERE status = 'pending';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS booking_audit;
+DROP FUNCTION IF EXISTS booking_audit_append_only();
@@ -0,0 +1,27 @@
+CREATE TABLE booking_audit
+(
+    id          BIGSERIAL PRIMARY KEY,
+    -- Not a foreign key, the history of a booking outlives it
+    booking_id  UUID        NOT NULL,
+    action      VARCHAR(16) NOT NULL,
+    actor       TEXT        NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    -- Snapshots of the booking, before is NULL for a creation and after for a deletion
+    before      JSONB,
+    after       JSONB
+);
+
+CREATE INDEX booking_audit_booking_id_idx ON booking_audit (booking_id, id);
+
+CREATE FUNCTION booking_audit_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'booking_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER booking_audit_append_only
+    BEFORE UPDATE OR DELETE
+    ON booking_audit
+    FOR EACH ROW
+EXECUTE FUNCTION booking_audit_append_only();
@@ -0,0 +1,27 @@
+-- The personal data erased from the snapshots of the history and the payloads of the events is not restored
+DO
+$$
+    BEGIN
+        IF EXISTS (SELECT 1 FROM bookings WHERE pii_key_id IS NOT NULL) THEN
+            RAISE EXCEPTION 'bookings have encrypted personal data, which cannot be decrypted by a migration';
+        END IF;
+    END
+$$;
+
+DROP INDEX bookings_pii_key_id_idx;
+DROP INDEX bookings_last_name_index_idx;
+DROP INDEX bookings_first_name_index_idx;
+
+ALTER TABLE bookings
+    DROP COLUMN last_name_index,
+    DROP COLUMN first_name_index,
+    DROP COLUMN birthday_encrypted,
+    DROP COLUMN gender_encrypted,
+    DROP COLUMN last_name_encrypted,
+    DROP COLUMN first_name_encrypted,
+    DROP COLUMN pii_data_key,
+    DROP COLUMN pii_key_id,
+    ALTER COLUMN first_name SET NOT NULL,
+    ALTER COLUMN last_name SET NOT NULL,
+    ALTER COLUMN gender SET NOT NULL,
+    ALTER COLUMN birthday SET NOT NULL;
@@ -0,0 +1,35 @@
+-- The plaintext columns are kept for the bookings created before, until bookings pii reencrypt encrypts them
+ALTER TABLE bookings
+    ALTER COLUMN first_name DROP NOT NULL,
+    ALTER COLUMN last_name DROP NOT NULL,
+    ALTER COLUMN gender DROP NOT NULL,
+    ALTER COLUMN birthday DROP NOT NULL,
+    -- Key of the keyring the data key is encrypted with, NULL while the booking is in plaintext
+    ADD COLUMN pii_key_id           TEXT,
+    ADD COLUMN pii_data_key         BYTEA,
+    ADD COLUMN first_name_encrypted BYTEA,
+    ADD COLUMN last_name_encrypted  BYTEA,
+    ADD COLUMN gender_encrypted     BYTEA,
+    ADD COLUMN birthday_encrypted   BYTEA,
+    ADD COLUMN first_name_index     BYTEA,
+    ADD COLUMN last_name_index      BYTEA;
+
+CREATE INDEX bookings_first_name_index_idx ON bookings (first_name_index);
+CREATE INDEX bookings_last_name_index_idx ON bookings (last_name_index);
+CREATE INDEX bookings_pii_key_id_idx ON bookings (pii_key_id);
+
+-- The snapshots of the history and the payloads of the events are not encrypted, they are stored without the personal
+-- data from now on and it is erased from the ones recorded before. The history is append-only otherwise.
+ALTER TABLE booking_audit DISABLE TRIGGER booking_audit_append_only;
+
+UPDATE booking_audit
+SET before = before - '{first_name,last_name,gender,birthday}'::text[],
+    after  = after - '{first_name,last_name,gender,birthday}'::text[]
+WHERE before ?| '{first_name,last_name,gender,birthday}'::text[]
+   OR after ?| '{first_name,last_name,gender,birthday}'::text[];
+
+ALTER TABLE booking_audit ENABLE TRIGGER booking_audit_append_only;
+
+UPDATE outbox_events
+SET payload = payload - '{first_name,last_name,gender,birthday}'::text[]
+WHERE payload ?| '{first_name,last_name,gender,birthday}'::text[];
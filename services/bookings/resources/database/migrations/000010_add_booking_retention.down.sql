@@ -0,0 +1,4 @@
+DROP INDEX bookings_launch_date_idx;
+
+ALTER TABLE bookings
+    DROP COLUMN anonymized_at;
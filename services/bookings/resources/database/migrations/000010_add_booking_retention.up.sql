@@ -0,0 +1,5 @@
+-- Set once the retention job erased the personal data of the booking, a later edit clears it
+ALTER TABLE bookings
+    ADD COLUMN anonymized_at TIMESTAMPTZ;
+
+CREATE INDEX bookings_launch_date_idx ON bookings (launch_date);
@@ -0,0 +1,4 @@
+-- Set while a relay publishes the event, other relays skip it until then. Claiming an event only takes a short
+-- transaction, so publishing does not hold a row lock or a connection.
+ALTER TABLE outbox_events
+    ADD COLUMN locked_until TIMESTAMPTZ;
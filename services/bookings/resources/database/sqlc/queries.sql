@@ -1,6 +1,98 @@
+-- name: AnonymizeBooking :one
+UPDATE bookings
+SET first_name           = NULL,
+    last_name            = NULL,
+    gender               = NULL,
+    birthday             = NULL,
+    pii_key_id           = NULL,
+    pii_data_key         = NULL,
+    first_name_encrypted = NULL,
+    last_name_encrypted  = NULL,
+    gender_encrypted     = NULL,
+    birthday_encrypted   = NULL,
+    first_name_index     = NULL,
+    last_name_index      = NULL,
+    anonymized_at        = sqlc.arg('anonymized_at'),
+    updated_at           = sqlc.arg('anonymized_at'),
+    version              = version + 1
+WHERE id = sqlc.arg('id')
+  AND anonymized_at IS NULL
+RETURNING id,
+    first_name,
+    last_name,
+    gender,
+    birthday,
+    launch_pad_id,
+    destination_id,
+    launch_date,
+    created_at,
+    updated_at,
+    status,
+    version,
+    pii_key_id,
+    pii_data_key,
+    first_name_encrypted,
+    last_name_encrypted,
+    gender_encrypted,
+    birthday_encrypted,
+    first_name_index,
+    last_name_index,
+    anonymized_at;
+
+-- name: ClaimOutboxEvents :many
+UPDATE outbox_events
+SET locked_until = now() + make_interval(secs => sqlc.arg('lease_seconds')::float8)
+WHERE id IN (SELECT e.id
+             FROM outbox_events e
+             WHERE e.status = 'pending'
+               AND e.available_at <= now()
+               AND (e.locked_until IS NULL OR e.locked_until <= now())
+               AND NOT EXISTS (SELECT 1
+                               FROM outbox_events earlier
+                               WHERE earlier.booking_id = e.booking_id
+                                 AND earlier.status = 'pending'
+                                 AND earlier.seq < e.seq)
+             ORDER BY e.seq
+             LIMIT sqlc.arg('limit') FOR UPDATE SKIP LOCKED)
+RETURNING id,
+    seq,
+    booking_id,
+    event_type,
+    payload,
+    status,
+    attempts,
+    last_error,
+    created_at,
+    available_at,
+    published_at,
+    locked_until;
+
+-- name: CountBookingsByLaunchDate :many
+SELECT launch_date,
+       destination_id,
+       count(*) AS bookings
+FROM bookings
+WHERE launch_pad_id = sqlc.arg('launch_pad_id')
+  AND launch_date >= sqlc.arg('from_date')
+  AND launch_date < sqlc.arg('to_date')
+  AND status <> 'rejected'
+GROUP BY launch_date, destination_id
+ORDER BY launch_date, destination_id;
+
+-- name: CreateBlackout :exec
+INSERT INTO blackouts (id, launch_pad_id, starts_at, ends_at, reason, created_at, updated_at)
+VALUES ($1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6,
+        $7);
+
 -- name: CreateBooking :exec
-INSERT INTO bookings (id, first_name, last_name, gender, birthday, launch_pad_id, destination_id, launch_date,
-                      created_at, updated_at)
+INSERT INTO bookings (id, launch_pad_id, destination_id, launch_date, created_at, updated_at, status, version,
+                      pii_key_id, pii_data_key, first_name_encrypted, last_name_encrypted, gender_encrypted,
+                      birthday_encrypted, first_name_index, last_name_index)
 VALUES ($1,
         $2,
         $3,
@@ -10,13 +102,100 @@ VALUES ($1,
         $7,
         $8,
         $9,
-        $10);
+        $10,
+        $11,
+        $12,
+        $13,
+        $14,
+        $15,
+        $16);
+
+-- name: CreateBookingAuditEntry :exec
+INSERT INTO booking_audit (booking_id, action, actor, occurred_at, before, after)
+VALUES ($1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6);
+
+-- name: CreateClosure :exec
+INSERT INTO closures (id, launch_pad_id, starts_at, ends_at, summary, source, created_at)
+VALUES ($1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6,
+        $7);
+
+-- name: CreateOutboxEvent :exec
+INSERT INTO outbox_events (id, booking_id, event_type, payload, created_at, available_at)
+VALUES ($1,
+        $2,
+        $3,
+        $4,
+        now(),
+        now());
+
+-- name: DeleteBlackout :one
+DELETE
+FROM blackouts
+WHERE id = $1
+RETURNING id;
 
 -- name: DeleteBooking :one
 DELETE
 FROM bookings
 WHERE id = $1
-RETURNING id;
+  AND version = $2
+RETURNING id,
+    first_name,
+    last_name,
+    gender,
+    birthday,
+    launch_pad_id,
+    destination_id,
+    launch_date,
+    created_at,
+    updated_at,
+    status,
+    version,
+    pii_key_id,
+    pii_data_key,
+    first_name_encrypted,
+    last_name_encrypted,
+    gender_encrypted,
+    birthday_encrypted,
+    first_name_index,
+    last_name_index,
+    anonymized_at;
+
+-- name: DeleteCacheEntry :exec
+DELETE
+FROM cache_entries
+WHERE key = $1;
+
+-- name: DeleteClosuresBySource :exec
+DELETE
+FROM closures
+WHERE source = $1;
+
+-- name: DeleteExpiredCacheEntries :execrows
+DELETE
+FROM cache_entries
+WHERE expires_at <= $1;
+
+-- name: GetBlackoutByID :one
+SELECT id,
+       launch_pad_id,
+       starts_at,
+       ends_at,
+       reason,
+       created_at,
+       updated_at
+FROM blackouts
+WHERE id = $1;
 
 -- name: GetBookingByID :one
 SELECT id,
@@ -28,10 +207,56 @@ SELECT id,
        destination_id,
        launch_date,
        created_at,
-       updated_at
+       updated_at,
+       status,
+       version,
+       pii_key_id,
+       pii_data_key,
+       first_name_encrypted,
+       last_name_encrypted,
+       gender_encrypted,
+       birthday_encrypted,
+       first_name_index,
+       last_name_index,
+       anonymized_at
 FROM bookings
 WHERE id = $1;
 
+-- name: GetCacheEntry :one
+SELECT key,
+       value,
+       stored_at,
+       expires_at
+FROM cache_entries
+WHERE key = sqlc.arg('key')
+  AND (expires_at IS NULL OR expires_at > sqlc.arg('now'));
+
+-- name: ListBlackouts :many
+SELECT id,
+       launch_pad_id,
+       starts_at,
+       ends_at,
+       reason,
+       created_at,
+       updated_at
+FROM blackouts
+WHERE (sqlc.narg('launch_pad_id')::text IS NULL OR launch_pad_id IS NULL OR launch_pad_id = sqlc.narg('launch_pad_id'))
+  AND ends_at > coalesce(sqlc.narg('starts_after'), '-infinity'::timestamptz)
+  AND starts_at < coalesce(sqlc.narg('ends_before'), 'infinity'::timestamptz)
+ORDER BY starts_at;
+
+-- name: ListBookingAuditEntries :many
+SELECT id,
+       booking_id,
+       action,
+       actor,
+       occurred_at,
+       before,
+       after
+FROM booking_audit
+WHERE booking_id = $1
+ORDER BY id;
+
 -- name: ListBookings :many
 SELECT id,
        first_name,
@@ -42,10 +267,204 @@ SELECT id,
        destination_id,
        launch_date,
        created_at,
-       updated_at
+       updated_at,
+       status,
+       version,
+       pii_key_id,
+       pii_data_key,
+       first_name_encrypted,
+       last_name_encrypted,
+       gender_encrypted,
+       birthday_encrypted,
+       first_name_index,
+       last_name_index,
+       anonymized_at
 FROM bookings
 WHERE launch_date = coalesce(sqlc.narg('launch_date'), launch_date)
   AND launch_pad_id = coalesce(sqlc.narg('launch_pad_id'), launch_pad_id)
   AND destination_id = coalesce(sqlc.narg('destination_id'), destination_id)
-ORDER BY created_at DESC LIMIT sqlc.arg('limit')
+  AND status = coalesce(sqlc.narg('status'), status)
+  AND (sqlc.narg('first_name_index')::bytea IS NULL OR first_name_index = sqlc.narg('first_name_index'))
+  AND (sqlc.narg('last_name_index')::bytea IS NULL OR last_name_index = sqlc.narg('last_name_index'))
+ORDER BY created_at DESC, id LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListBookingsToAnonymize :many
+SELECT id, launch_date
+FROM bookings
+WHERE anonymized_at IS NULL
+  AND launch_date < sqlc.arg('launched_before')
+ORDER BY launch_date, id LIMIT sqlc.arg('limit')
 OFFSET sqlc.arg('offset');
+
+-- name: ListBookingsToPurge :many
+SELECT id, launch_date
+FROM bookings
+WHERE launch_date < sqlc.arg('launched_before')
+ORDER BY launch_date, id LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListBookingsToReencrypt :many
+SELECT id,
+       first_name,
+       last_name,
+       gender,
+       birthday,
+       launch_pad_id,
+       destination_id,
+       launch_date,
+       created_at,
+       updated_at,
+       status,
+       version,
+       pii_key_id,
+       pii_data_key,
+       first_name_encrypted,
+       last_name_encrypted,
+       gender_encrypted,
+       birthday_encrypted,
+       first_name_index,
+       last_name_index,
+       anonymized_at
+FROM bookings
+WHERE pii_key_id IS DISTINCT FROM sqlc.arg('active_key_id')::text
+  AND anonymized_at IS NULL
+ORDER BY id
+LIMIT sqlc.arg('limit') FOR UPDATE SKIP LOCKED;
+
+-- name: ListCacheEntries :many
+SELECT key,
+       stored_at,
+       expires_at
+FROM cache_entries
+WHERE starts_with(key, sqlc.arg('prefix')::text)
+  AND (expires_at IS NULL OR expires_at > sqlc.arg('now'))
+ORDER BY key;
+
+-- name: ListClosures :many
+SELECT id,
+       launch_pad_id,
+       starts_at,
+       ends_at,
+       summary,
+       source,
+       created_at
+FROM closures
+WHERE launch_pad_id = sqlc.arg('launch_pad_id')
+  AND starts_at < sqlc.arg('ends_before')
+  AND ends_at > sqlc.arg('starts_after')
+ORDER BY starts_at;
+
+-- name: LockLaunchSlot :exec
+SELECT pg_advisory_xact_lock(hashtextextended(sqlc.arg('key')::text, 0));
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events
+SET status       = sqlc.arg('status'),
+    attempts     = attempts + 1,
+    last_error   = sqlc.arg('last_error'),
+    available_at = now() + make_interval(secs => sqlc.arg('retry_in_seconds')::float8),
+    locked_until = NULL
+WHERE id = sqlc.arg('id');
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+SET status       = 'published',
+    attempts     = attempts + 1,
+    published_at = now(),
+    locked_until = NULL
+WHERE id = $1;
+
+-- name: NotifyCacheInvalidation :exec
+SELECT pg_notify('cache_invalidation', $1);
+
+-- name: PurgeBooking :one
+DELETE
+FROM bookings
+WHERE id = $1
+RETURNING id,
+    first_name,
+    last_name,
+    gender,
+    birthday,
+    launch_pad_id,
+    destination_id,
+    launch_date,
+    created_at,
+    updated_at,
+    status,
+    version,
+    pii_key_id,
+    pii_data_key,
+    first_name_encrypted,
+    last_name_encrypted,
+    gender_encrypted,
+    birthday_encrypted,
+    first_name_index,
+    last_name_index,
+    anonymized_at;
+
+-- name: SetBookingPII :exec
+UPDATE bookings
+SET first_name           = NULL,
+    last_name            = NULL,
+    gender               = NULL,
+    birthday             = NULL,
+    pii_key_id           = sqlc.arg('pii_key_id'),
+    pii_data_key         = sqlc.arg('pii_data_key'),
+    first_name_encrypted = sqlc.arg('first_name_encrypted'),
+    last_name_encrypted  = sqlc.arg('last_name_encrypted'),
+    gender_encrypted     = sqlc.arg('gender_encrypted'),
+    birthday_encrypted   = sqlc.arg('birthday_encrypted'),
+    first_name_index     = sqlc.arg('first_name_index'),
+    last_name_index      = sqlc.arg('last_name_index')
+WHERE id = sqlc.arg('id');
+
+-- name: SetCacheEntry :exec
+INSERT INTO cache_entries (key, value, stored_at, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (key) DO UPDATE SET value      = excluded.value,
+                                stored_at  = excluded.stored_at,
+                                expires_at = excluded.expires_at;
+
+-- name: SetLockTimeout :exec
+SELECT set_config('lock_timeout', sqlc.arg('timeout')::text, true);
+
+-- name: UpdateBlackout :one
+UPDATE blackouts
+SET launch_pad_id = $2,
+    starts_at     = $3,
+    ends_at       = $4,
+    reason        = $5,
+    updated_at    = $6
+WHERE id = $1
+RETURNING id;
+
+-- name: UpdateBooking :one
+UPDATE bookings
+SET first_name           = NULL,
+    last_name            = NULL,
+    gender               = NULL,
+    birthday             = NULL,
+    pii_key_id           = sqlc.arg('pii_key_id'),
+    pii_data_key         = sqlc.arg('pii_data_key'),
+    first_name_encrypted = sqlc.arg('first_name_encrypted'),
+    last_name_encrypted  = sqlc.arg('last_name_encrypted'),
+    gender_encrypted     = sqlc.arg('gender_encrypted'),
+    birthday_encrypted   = sqlc.arg('birthday_encrypted'),
+    first_name_index     = sqlc.arg('first_name_index'),
+    last_name_index      = sqlc.arg('last_name_index'),
+    anonymized_at        = NULL,
+    updated_at           = sqlc.arg('updated_at'),
+    version              = version + 1
+WHERE id = sqlc.arg('id')
+  AND version = sqlc.arg('version')
+RETURNING id;
+
+-- name: UpdateBookingStatus :one
+UPDATE bookings
+SET status     = $2,
+    updated_at = $3,
+    version    = version + 1
+WHERE id = $1
+RETURNING id;